@@ -15,5 +15,7 @@ func main() {
 		panic(err)
 	}
 
-	ws.Start()
+	if err := ws.Start(); err != nil {
+		panic(err)
+	}
 }
@@ -20,6 +20,7 @@ go 1.24
 toolchain go1.24.1
 
 require (
+	github.com/andybalholm/brotli v1.2.0
 	github.com/aws/aws-sdk-go v1.53.10
 	github.com/go-playground/validator/v10 v10.20.0
 	github.com/gomodule/redigo v1.9.2
@@ -29,7 +30,7 @@ require (
 	github.com/hashicorp/vault/api v1.16.0
 	github.com/joho/godotenv v1.5.1
 	github.com/jtolds/gls v4.20.0+incompatible
-	github.com/mozillazg/go-httpheader v0.4.0
+	github.com/klauspost/compress v1.18.0
 	github.com/nats-io/nats.go v1.43.0
 	github.com/prometheus/client_golang v1.19.0
 	github.com/sirupsen/logrus v1.9.3
@@ -73,7 +74,6 @@ require (
 	github.com/hashicorp/hcl v1.0.0 // indirect
 	github.com/jmespath/go-jmespath v0.4.0 // indirect
 	github.com/json-iterator/go v1.1.12 // indirect
-	github.com/klauspost/compress v1.18.0 // indirect
 	github.com/leodido/go-urn v1.4.0 // indirect
 	github.com/mitchellh/go-homedir v1.1.0 // indirect
 	github.com/mitchellh/mapstructure v1.5.0 // indirect
@@ -0,0 +1,137 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package authz
+
+import (
+	"strings"
+
+	"github.com/caiomarcatti12/nanogo/pkg/env"
+	"github.com/caiomarcatti12/nanogo/pkg/errors"
+	"github.com/caiomarcatti12/nanogo/pkg/i18n"
+	"github.com/caiomarcatti12/nanogo/pkg/jwt"
+)
+
+// Policy declara o que uma rota exige: ao menos um dos Roles e todos os Scopes.
+// Uma Policy vazia libera o acesso sem autenticação.
+type Policy struct {
+	Roles  []string
+	Scopes []string
+}
+
+func (p Policy) IsEmpty() bool {
+	return len(p.Roles) == 0 && len(p.Scopes) == 0
+}
+
+// Principal é quem fez a requisição, montado a partir das claims do token.
+type Principal struct {
+	Subject string
+	Roles   []string
+	Scopes  []string
+	Claims  jwt.Claims
+}
+
+type IAuthorizer interface {
+	Principal(claims jwt.Claims) *Principal
+	Authorize(principal *Principal, policy Policy) error
+}
+
+type Authorizer struct {
+	rolesClaim  string
+	scopesClaim string
+	i18n        i18n.I18N
+}
+
+// Factory cria o Authorizer. AUTHZ_ROLES_CLAIM e AUTHZ_SCOPES_CLAIM definem as
+// claims lidas do token.
+func Factory(env env.IEnv, i18n i18n.I18N) IAuthorizer {
+	return &Authorizer{
+		rolesClaim:  env.GetEnv("AUTHZ_ROLES_CLAIM", "roles"),
+		scopesClaim: env.GetEnv("AUTHZ_SCOPES_CLAIM", "scope"),
+		i18n:        i18n,
+	}
+}
+
+// Principal retorna nil quando não há claims, ou seja, quando a requisição não
+// foi autenticada.
+func (a *Authorizer) Principal(claims jwt.Claims) *Principal {
+	if claims == nil {
+		return nil
+	}
+
+	return &Principal{
+		Subject: claims.Subject(),
+		Roles:   values(claims[a.rolesClaim]),
+		Scopes:  values(claims[a.scopesClaim]),
+		Claims:  claims,
+	}
+}
+
+// Authorize retorna um errors.CustomError 401 quando não há principal e 403
+// quando a Policy não é atendida.
+func (a *Authorizer) Authorize(principal *Principal, policy Policy) error {
+	if policy.IsEmpty() {
+		return nil
+	}
+
+	if principal == nil {
+		return errors.Unauthorized(a.i18n.Get("authz.unauthenticated"))
+	}
+
+	if len(policy.Roles) > 0 && !containsAny(principal.Roles, policy.Roles) {
+		return errors.Forbidden(a.i18n.Get("authz.missing_role", map[string]interface{}{"roles": strings.Join(policy.Roles, ", ")}))
+	}
+
+	for _, scope := range policy.Scopes {
+		if !containsAny(principal.Scopes, []string{scope}) {
+			return errors.Forbidden(a.i18n.Get("authz.missing_scope", map[string]interface{}{"scope": scope}))
+		}
+	}
+
+	return nil
+}
+
+// values aceita lista ou string; strings são separadas por espaço, como a
+// claim scope do OAuth 2.0.
+func values(claim interface{}) []string {
+	switch value := claim.(type) {
+	case string:
+		return strings.Fields(value)
+	case []string:
+		return value
+	case []interface{}:
+		result := make([]string, 0, len(value))
+		for _, item := range value {
+			if text, ok := item.(string); ok {
+				result = append(result, text)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+func containsAny(values []string, accepted []string) bool {
+	for _, value := range values {
+		for _, candidate := range accepted {
+			if value == candidate {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package authz
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/caiomarcatti12/nanogo/pkg/errors"
+	"github.com/caiomarcatti12/nanogo/pkg/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeI18n struct{}
+
+func (fakeI18n) SetLanguage(string)            {}
+func (fakeI18n) GetLanguage() string           { return "en-us" }
+func (fakeI18n) GetDefaultLanguage() string    { return "en-us" }
+func (fakeI18n) LoadTranslations(string) error { return nil }
+func (fakeI18n) Get(key string, _ ...map[string]interface{}) string {
+	return key
+}
+
+func newAuthorizer() *Authorizer {
+	return &Authorizer{rolesClaim: "roles", scopesClaim: "scope", i18n: fakeI18n{}}
+}
+
+func assertCode(t *testing.T, err error, code int) {
+	t.Helper()
+	customError, ok := err.(*errors.CustomError)
+	require.True(t, ok, "expected *errors.CustomError, got %T", err)
+	assert.Equal(t, code, customError.Code)
+}
+
+func TestPrincipal(t *testing.T) {
+	authorizer := newAuthorizer()
+
+	assert.Nil(t, authorizer.Principal(nil))
+
+	principal := authorizer.Principal(jwt.Claims{
+		"sub":   "42",
+		"roles": []interface{}{"admin", "editor"},
+		"scope": "users:read users:write",
+	})
+	assert.Equal(t, "42", principal.Subject)
+	assert.Equal(t, []string{"admin", "editor"}, principal.Roles)
+	assert.Equal(t, []string{"users:read", "users:write"}, principal.Scopes)
+}
+
+func TestAuthorize(t *testing.T) {
+	authorizer := newAuthorizer()
+	principal := authorizer.Principal(jwt.Claims{
+		"roles": []string{"editor"},
+		"scope": "users:read",
+	})
+
+	t.Run("empty policy allows anonymous", func(t *testing.T) {
+		assert.NoError(t, authorizer.Authorize(nil, Policy{}))
+	})
+
+	t.Run("anonymous is unauthorized", func(t *testing.T) {
+		assertCode(t, authorizer.Authorize(nil, Policy{Roles: []string{"editor"}}), http.StatusUnauthorized)
+	})
+
+	t.Run("any role is enough", func(t *testing.T) {
+		assert.NoError(t, authorizer.Authorize(principal, Policy{Roles: []string{"admin", "editor"}}))
+		assertCode(t, authorizer.Authorize(principal, Policy{Roles: []string{"admin"}}), http.StatusForbidden)
+	})
+
+	t.Run("every scope is required", func(t *testing.T) {
+		assert.NoError(t, authorizer.Authorize(principal, Policy{Scopes: []string{"users:read"}}))
+		assertCode(t, authorizer.Authorize(principal, Policy{Scopes: []string{"users:read", "users:write"}}), http.StatusForbidden)
+	})
+}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/caiomarcatti12/nanogo/pkg/env"
 	"github.com/caiomarcatti12/nanogo/pkg/log"
+	"github.com/gomodule/redigo/redis"
 )
 
 type ICache interface {
@@ -30,6 +31,13 @@ type ICache interface {
 	Disconnect()
 }
 
+// IRedisPool is implemented by caches backed by Redis and gives access to the
+// connection pool for commands not covered by ICache, such as scripts.
+type IRedisPool interface {
+	// Conn returns a connection from the pool. The caller must close it.
+	Conn() redis.Conn
+}
+
 func Factory(env env.IEnv, logger log.ILog) ICache {
 	cacheProvider := env.GetEnv("CACHE_PROVIDER", "REDIS")
 
@@ -16,6 +16,7 @@
 package cache
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -139,10 +140,44 @@ func (r *RedisCache) Remove(key string) error {
 	return nil
 }
 
+// Conn returns a connection from the pool. The caller must close it.
+func (r *RedisCache) Conn() redis.Conn {
+	return r.pool.Get()
+}
+
 func (r *RedisCache) Disconnect() {
 	r.pool.Close()
 }
 
+// HealthCheck sends a PING through the connection pool.
+func (r *RedisCache) HealthCheck(ctx context.Context) error {
+	if r.pool.Dial == nil {
+		return errors.New("redis pool is not connected")
+	}
+
+	conn, err := r.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = redis.DoContext(conn, ctx, "PING")
+
+	return err
+}
+
+// OnStart creates the connection pool when the application starts.
+func (r *RedisCache) OnStart(ctx context.Context) error {
+	return r.Connect()
+}
+
+// OnStop closes the connection pool when the application stops.
+func (r *RedisCache) OnStop(ctx context.Context) error {
+	r.logger.Info("Disconnecting from Redis...")
+
+	return r.pool.Close()
+}
+
 func (r *RedisCache) stringifyValue(value interface{}) (string, error) {
 	bytes, err := json.Marshal(value)
 	if err != nil {
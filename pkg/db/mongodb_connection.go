@@ -24,6 +24,7 @@ import (
 	"github.com/caiomarcatti12/nanogo/pkg/log"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
 var once sync.Once
@@ -94,6 +95,27 @@ func (m *MongoClient) GetClient() interface{} {
 	return m.clientDB
 }
 
+// HealthCheck pings the primary node, so it fails while MongoDB is unreachable
+// even though the driver connects lazily.
+func (m *MongoClient) HealthCheck(ctx context.Context) error {
+	if m.client == nil {
+		return errors.New("MongoDB is not connected")
+	}
+
+	return m.client.Ping(ctx, readpref.Primary())
+}
+
+// OnStop closes the MongoDB connection when the application stops.
+func (m *MongoClient) OnStop(ctx context.Context) error {
+	if m.client == nil {
+		return nil
+	}
+
+	m.logger.Info("Disconnecting from MongoDB...")
+
+	return m.client.Disconnect(ctx)
+}
+
 func (m *MongoClient) Disconnect() {
 	m.logger.Info("Disconnecting from MongoDB...")
 	err := m.client.Disconnect(context.Background())
@@ -16,42 +16,90 @@
 package di
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"reflect"
+	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/caiomarcatti12/nanogo/pkg/i18n"
 	"github.com/caiomarcatti12/nanogo/pkg/log"
 )
 
 type IContainer interface {
-	Register(factoryFunc interface{}) error
-	RegisterAll(factoryFunc []interface{}) error
+	Register(factoryFunc interface{}, options ...RegisterOption) error
+	RegisterAll(factoryFunc []interface{}, options ...RegisterOption) error
+	IsRegistered(factoryFunc interface{}) bool
+	Lifetime(factoryFunc interface{}) (Lifetime, bool)
 	GetByFactory(factoryFunc interface{}) (interface{}, error)
 	GetByName(interfaceName string) (interface{}, error)
+	GetNamed(interfaceName string, name string) (interface{}, error)
+	GetGroup(interfaceName string) ([]interface{}, error)
+	CreateScope() IScope
+	Validate(options ...ValidateOption) error
+	Graph() Graph
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+	CreateChild() IContainer
+	Override(factoryFunc interface{}, options ...RegisterOption) (restore func(), err error)
+	Invoke(fn interface{}) error
+	Decorate(decoratorFunc interface{}) error
 }
 
-// Container manages dependency injection.
+// registration holds a factory function and how its instances are shared.
+type registration struct {
+	factory  interface{}
+	lifetime Lifetime
+	typeName string
+	name     string
+	grouped  bool
+	// as is the type the factory is registered under when it differs from the
+	// factory result, see Provide and Bind.
+	as reflect.Type
+
+	// buildMu serializes singleton construction so it happens exactly once.
+	buildMu sync.Mutex
+	// checked stores the container generation in which the registration was
+	// last verified to be free of circular dependencies.
+	checked atomic.Uint64
+}
+
+// Container manages dependency injection. It is safe for concurrent use.
 type Container struct {
-	constructors map[string]interface{}
+	mu           sync.RWMutex
+	constructors map[string]*registration
 	cached       map[string]interface{}
-	i18n         i18n.I18N
-	log          log.ILog
+	// implementations keeps every registration key of a type in registration
+	// order: the default one, named ones and group members.
+	implementations map[string][]string
+	generation      uint64
+	// built keeps singletons in construction order, which is also dependency
+	// order since dependencies are always built first.
+	built   []interface{}
+	started bool
+	// decorators keeps the decorators of each type in registration order.
+	decorators map[string][]*decorator
+	// parent is set on child containers, see CreateChild.
+	parent *Container
+	i18n   i18n.I18N
+	log    log.ILog
 }
 
 var (
 	singletonInstance IContainer
 	once              sync.Once
+	// generations is shared by every container so a generation number never
+	// describes two different sets of registrations.
+	generations atomic.Uint64
 )
 
+// Factory returns the global container used by GetInstance and the generic
+// helpers, creating it on the first call.
 func Factory(i18n i18n.I18N, log log.ILog) IContainer {
 	once.Do(func() {
-		singletonInstance = &Container{
-			constructors: make(map[string]interface{}),
-			cached:       make(map[string]interface{}),
-			i18n:         i18n,
-			log:          log,
-		}
+		singletonInstance = newContainer(i18n, log)
 	})
 	return singletonInstance
 }
@@ -60,25 +108,201 @@ func GetInstance() IContainer {
 	return singletonInstance
 }
 
+// SetInstance replaces the global container returned by GetInstance and returns
+// a function that puts the previous one back. Meant for tests, see package ditest.
+func SetInstance(container IContainer) (restore func()) {
+	once.Do(func() {})
+
+	previous := singletonInstance
+	singletonInstance = container
+
+	return func() {
+		singletonInstance = previous
+	}
+}
+
+// New creates an independent container that shares nothing with the global one.
+func New(i18n i18n.I18N, log log.ILog) IContainer {
+	return newContainer(i18n, log)
+}
+
+func newContainer(i18n i18n.I18N, log log.ILog) *Container {
+	return &Container{
+		constructors:    make(map[string]*registration),
+		cached:          make(map[string]interface{}),
+		implementations: make(map[string][]string),
+		decorators:      make(map[string][]*decorator),
+		generation:      generations.Add(1),
+		i18n:            i18n,
+		log:             log,
+	}
+}
+
+// CreateChild creates a container that inherits the registrations present in c
+// when it is called. Registrations added to the child, including overrides,
+// are only visible to the child. Inherited singletons are shared with c unless
+// one of their dependencies was overridden in the child, in which case the
+// child builds and keeps its own instance.
+func (c *Container) CreateChild() IContainer {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	child := newContainer(c.i18n, c.log)
+	child.parent = c
+
+	for key, reg := range c.constructors {
+		child.constructors[key] = reg
+	}
+
+	for typeName, keys := range c.implementations {
+		child.implementations[typeName] = append([]string{}, keys...)
+	}
+
+	for typeName, decorators := range c.decorators {
+		child.decorators[typeName] = append([]*decorator{}, decorators...)
+	}
+
+	return child
+}
+
 // Register adds a factory function for creating instances of a type.
-func (c *Container) Register(factoryFunc interface{}) error {
-	cType, err := c.getNameInterface(factoryFunc)
+// Without options the factory is registered as a singleton and replaces any
+// previous default factory of the same type. Use WithName or Grouped to keep
+// several implementations of a type side by side.
+func (c *Container) Register(factoryFunc interface{}, options ...RegisterOption) error {
+	reg, err := c.newRegistration(factoryFunc, options)
 
 	if err != nil {
 		return err
 	}
 
-	c.log.Trace(c.i18n.Get("di.register_new_factory", map[string]interface{}{"factory": cType}))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := c.registrationKey(reg)
 
-	c.constructors[cType] = factoryFunc
+	c.log.Trace(c.i18n.Get("di.register_new_factory", map[string]interface{}{"factory": key, "lifetime": reg.lifetime.String()}))
+
+	if previous, exists := c.constructors[key]; exists && !previous.sameAs(reg) {
+		c.log.Warning(c.i18n.Get("di.factory_replaced", map[string]interface{}{"factory": key}))
+	}
+
+	c.store(key, reg)
 
 	return nil
 }
 
-// Register adds a factory function for creating instances of a type.
-func (c *Container) RegisterAll(factoryFunc []interface{}) error {
+// Override replaces the registration of the type returned by factoryFunc, like
+// Register, and returns a function that restores the previous registration
+// together with the instance it had already built. It is meant for tests that
+// need to swap an implementation temporarily, see package ditest.
+func (c *Container) Override(factoryFunc interface{}, options ...RegisterOption) (func(), error) {
+	reg, err := c.newRegistration(factoryFunc, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := c.registrationKey(reg)
+
+	c.log.Trace(c.i18n.Get("di.factory_overridden", map[string]interface{}{"factory": key}))
+
+	previous, existed := c.constructors[key]
+	previousInstance, wasCached := c.cached[key]
+
+	c.store(key, reg)
+
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		c.log.Trace(c.i18n.Get("di.factory_restored", map[string]interface{}{"factory": key}))
+
+		c.evict(key)
+
+		if existed {
+			c.constructors[key] = previous
+		} else {
+			delete(c.constructors, key)
+			c.implementations[reg.typeName] = removeKey(c.implementations[reg.typeName], key)
+		}
+
+		if wasCached {
+			c.cached[key] = previousInstance
+		}
+
+		c.generation = generations.Add(1)
+	}, nil
+}
+
+// newRegistration validates factoryFunc and applies the options. A factory
+// registered with as(T) is stored under T once its result is checked to be
+// assignable to T.
+func (c *Container) newRegistration(factoryFunc interface{}, options []RegisterOption) (*registration, error) {
+	cType, err := c.getNameInterface(factoryFunc)
+
+	if err != nil {
+		return nil, err
+	}
+
+	reg := &registration{factory: factoryFunc, lifetime: Singleton, typeName: cType}
+
+	for _, option := range options {
+		option(reg)
+	}
+
+	if reg.as != nil {
+		result := reflect.TypeOf(factoryFunc).Out(0)
+
+		if !result.AssignableTo(reg.as) {
+			return nil, errors.New(c.i18n.Get("di.factory_type_mismatch", map[string]interface{}{"result": result.String(), "type": reg.as.String()}))
+		}
+
+		reg.typeName = typeName(reg.as)
+	}
+
+	return reg, nil
+}
+
+// store saves reg under key, dropping the instance built by a different factory.
+// Must be called with c.mu held.
+func (c *Container) store(key string, reg *registration) {
+	if previous, exists := c.constructors[key]; exists {
+		if !previous.sameAs(reg) {
+			c.evict(key)
+		}
+	} else {
+		c.implementations[reg.typeName] = append(c.implementations[reg.typeName], key)
+	}
+
+	c.constructors[key] = reg
+	c.generation = generations.Add(1)
+}
+
+// registrationKey returns the key under which reg is stored. Group members
+// reuse the key of an existing member built by the same factory so registering
+// it twice doesn't duplicate it. Must be called with c.mu held.
+func (c *Container) registrationKey(reg *registration) string {
+	if !reg.grouped {
+		return namedKey(reg.typeName, reg.name)
+	}
+
+	for _, key := range c.implementations[reg.typeName] {
+		if existing := c.constructors[key]; existing.grouped && existing.sameFactory(reg) {
+			return key
+		}
+	}
+
+	return fmt.Sprintf("%s@%d", reg.typeName, len(c.implementations[reg.typeName]))
+}
+
+// RegisterAll adds every factory function using the same options.
+func (c *Container) RegisterAll(factoryFunc []interface{}, options ...RegisterOption) error {
 	for _, factory := range factoryFunc {
-		err := c.Register(factory)
+		err := c.Register(factory, options...)
 
 		if err != nil {
 			return err
@@ -88,6 +312,36 @@ func (c *Container) RegisterAll(factoryFunc []interface{}) error {
 	return nil
 }
 
+// IsRegistered reports whether a factory for the type returned by factoryFunc exists.
+func (c *Container) IsRegistered(factoryFunc interface{}) bool {
+	cType, err := c.getNameInterface(factoryFunc)
+
+	if err != nil {
+		return false
+	}
+
+	_, exists := c.lookup(cType)
+
+	return exists
+}
+
+// Lifetime returns the lifetime of the registration for the type returned by
+// factoryFunc, and false when there is none.
+func (c *Container) Lifetime(factoryFunc interface{}) (Lifetime, bool) {
+	cType, err := c.getNameInterface(factoryFunc)
+
+	if err != nil {
+		return Singleton, false
+	}
+
+	reg, exists := c.lookup(cType)
+	if !exists {
+		return Singleton, false
+	}
+
+	return reg.lifetime, true
+}
+
 // GetByFactory retrieves an instance by factory func.
 func (c *Container) GetByFactory(factoryFunc interface{}) (interface{}, error) {
 	cType, err := c.getNameInterface(factoryFunc)
@@ -100,28 +354,157 @@ func (c *Container) GetByFactory(factoryFunc interface{}) (interface{}, error) {
 }
 
 func (c *Container) GetByName(interfaceName string) (interface{}, error) {
+	return c.resolve(interfaceName, nil)
+}
+
+// GetNamed retrieves the instance registered for the type with WithName(name).
+func (c *Container) GetNamed(interfaceName string, name string) (interface{}, error) {
+	return c.resolve(namedKey(interfaceName, name), nil)
+}
+
+// GetGroup retrieves every implementation registered for the type, in
+// registration order.
+func (c *Container) GetGroup(interfaceName string) ([]interface{}, error) {
+	return c.resolveAll(interfaceName, nil)
+}
+
+// CreateScope opens a new scope. Scoped registrations resolved through it are
+// shared until Close is called.
+func (c *Container) CreateScope() IScope {
+	return newScope(c)
+}
+
+// resolve builds or reuses an instance according to the registration lifetime.
+// Scope is nil when resolving outside of a scope.
+func (c *Container) resolve(interfaceName string, scope *Scope) (interface{}, error) {
 	c.log.Trace(c.i18n.Get("di.get_by_factory", map[string]interface{}{"factory": interfaceName}))
 
-	instanceFunc, exists := c.constructors[interfaceName]
+	reg, exists := c.lookup(interfaceName)
 
 	if !exists {
 		return nil, errors.New(c.i18n.Get("di.no_service_registered_for_type", map[string]interface{}{"factory": interfaceName}))
 	}
 
-	if instanceCached, exists := c.cached[interfaceName]; exists {
+	switch reg.lifetime {
+	case Transient:
+		instance, err := c.build(reg, interfaceName, scope)
+		if err != nil || scope == nil {
+			return instance, err
+		}
+
+		return scope.track(interfaceName, instance)
+	case Scoped:
+		if scope == nil {
+			return nil, errors.New(c.i18n.Get("di.scoped_outside_scope", map[string]interface{}{"factory": interfaceName}))
+		}
+
+		return scope.getOrBuild(interfaceName, func() (interface{}, error) {
+			return c.build(reg, interfaceName, scope)
+		})
+	}
+
+	if instanceCached, exists := c.getCached(interfaceName); exists {
 		c.log.Trace(c.i18n.Get("di.get_by_factory_cached", map[string]interface{}{"factory": interfaceName}))
 		return instanceCached, nil
 	}
 
-	newInstance, err := c.factoryInstance(instanceFunc, interfaceName)
+	// Singletons inherited untouched by a child container belong to the parent.
+	if c.parent != nil && c.inherited(interfaceName) {
+		return c.parent.resolve(interfaceName, nil)
+	}
+
+	// Only one goroutine builds the singleton, the others wait and reuse it.
+	reg.buildMu.Lock()
+	defer reg.buildMu.Unlock()
+
+	if instanceCached, exists := c.getCached(interfaceName); exists {
+		return instanceCached, nil
+	}
+
+	// Singletons never see the caller's scope so they can't capture scoped instances.
+	newInstance, err := c.build(reg, interfaceName, nil)
 	if err != nil {
-		c.log.Error(c.i18n.Get("di.factory_error", map[string]interface{}{"factory": interfaceName, "error": err.Error()}))
+		return nil, err
+	}
+
+	// Singletons built after Start are started right away.
+	if err := c.startIfRunning(newInstance); err != nil {
 		return nil, err
 	}
 
 	c.log.Trace(c.i18n.Get("di.set_factory_in_cache", map[string]interface{}{"factory": interfaceName}))
 
-	c.cached[interfaceName] = newInstance
+	c.setCached(interfaceName, reg, newInstance)
+
+	return newInstance, nil
+}
+
+// resolveAll resolves every registration of the type: default, named and grouped.
+func (c *Container) resolveAll(interfaceName string, scope *Scope) ([]interface{}, error) {
+	c.mu.RLock()
+	keys := append([]string{}, c.implementations[interfaceName]...)
+	c.mu.RUnlock()
+
+	instances := make([]interface{}, 0, len(keys))
+
+	for _, key := range keys {
+		instance, err := c.resolve(key, scope)
+
+		if err != nil {
+			return nil, err
+		}
+
+		instances = append(instances, instance)
+	}
+
+	return instances, nil
+}
+
+func (c *Container) lookup(interfaceName string) (*registration, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	reg, exists := c.constructors[interfaceName]
+
+	return reg, exists
+}
+
+func (c *Container) getCached(interfaceName string) (interface{}, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	instance, exists := c.cached[interfaceName]
+
+	return instance, exists
+}
+
+// setCached stores the singleton unless the registration was replaced while it was being built.
+func (c *Container) setCached(interfaceName string, reg *registration, instance interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.constructors[interfaceName] == reg {
+		c.cached[interfaceName] = instance
+	}
+
+	c.built = append(c.built, instance)
+}
+
+func (c *Container) build(reg *registration, interfaceName string, scope *Scope) (interface{}, error) {
+	if err := c.checkCycle(interfaceName, reg); err != nil {
+		c.log.Error(err.Error())
+		return nil, err
+	}
+
+	newInstance, err := c.factoryInstance(reg.factory, interfaceName, scope)
+	if err == nil {
+		newInstance, err = c.decorate(reg, interfaceName, newInstance, scope)
+	}
+
+	if err != nil {
+		c.log.Error(c.i18n.Get("di.factory_error", map[string]interface{}{"factory": interfaceName, "error": err.Error()}))
+		return nil, err
+	}
 
 	return newInstance, nil
 }
@@ -140,24 +523,26 @@ func (c *Container) getNameInterface(factoryFunc interface{}) (string, error) {
 
 	factoryType := reflect.TypeOf(factoryFunc)
 	if factoryType.Kind() != reflect.Func {
-		return "", errors.New("di.factory_func_is_not_func")
+		return "", errors.New(c.i18n.Get("di.factory_func_is_not_func"))
 	}
 
 	if factoryType.NumOut() < 1 || factoryType.NumOut() > 2 {
-		return "", errors.New("di.factory_func_has_no_one_result")
+		return "", errors.New(c.i18n.Get("di.factory_func_has_no_one_result"))
 	}
 
-	name := factoryType.Out(0).PkgPath() + "/" + factoryType.Out(0).Name()
+	if factoryType.NumOut() == 2 && factoryType.Out(1) != errorType {
+		return "", errors.New(c.i18n.Get("di.factory_second_result_not_error", map[string]interface{}{"factory": factoryType.String()}))
+	}
 
-	return name, nil
+	return typeName(factoryType.Out(0)), nil
 }
 
-func (c *Container) factoryInstance(instanceFunc interface{}, interfaceName string) (interface{}, error) {
+func (c *Container) factoryInstance(instanceFunc interface{}, interfaceName string, scope *Scope) (interface{}, error) {
 	c.log.Trace(c.i18n.Get("di.factory_new_instance", map[string]interface{}{"factory": interfaceName}))
 
 	factoryValue := reflect.ValueOf(instanceFunc)
 
-	parameters, err := c.resolveParameters(factoryValue, interfaceName)
+	parameters, err := c.resolveParameters(factoryValue, interfaceName, scope)
 
 	if err != nil {
 		return nil, err
@@ -171,13 +556,11 @@ func (c *Container) factoryInstance(instanceFunc interface{}, interfaceName stri
 		return nil, errors.New(c.i18n.Get("di.factory_function_returned_no_results", map[string]interface{}{"factory": interfaceName}))
 	}
 
-	if len(results) == 2 && results[1].Type().Implements(reflect.TypeOf((*error)(nil)).Elem()) {
-		if !results[1].IsNil() {
-			return nil, results[1].Interface().(error)
-		}
+	if len(results) == 2 && !results[1].IsNil() {
+		return nil, results[1].Interface().(error)
 	}
 
-	if !results[0].IsValid() || results[0].IsNil() {
+	if !results[0].IsValid() || isNil(results[0]) {
 		return nil, errors.New(c.i18n.Get("di.factory_function_returned_nil", map[string]interface{}{"factory": interfaceName}))
 	}
 
@@ -185,43 +568,221 @@ func (c *Container) factoryInstance(instanceFunc interface{}, interfaceName stri
 }
 
 // resolveParameters resolves the function parameters from the container.
-func (c *Container) resolveParameters(fnVal reflect.Value, interfaceName string) ([]reflect.Value, error) {
+func (c *Container) resolveParameters(fnVal reflect.Value, interfaceName string, scope *Scope) ([]reflect.Value, error) {
 	c.log.Trace(c.i18n.Get("di.resolve_parameters", map[string]interface{}{"factory": interfaceName}))
 
 	fnType := fnVal.Type()
 	var in []reflect.Value
 
 	for i := 0; i < fnType.NumIn(); i++ {
-		argType := fnType.In(i).PkgPath() + "/" + fnType.In(i).Name()
-		argInstance, err := c.GetByName(argType)
+		argValue, err := c.resolveParameter(fnType.In(i), "", scope)
 
 		if err != nil {
 			return nil, err
 		}
 
-		in = append(in, reflect.ValueOf(argInstance))
+		in = append(in, argValue)
 	}
 
 	return in, nil
 }
 
-// Get retrieves an instance of the requested type.
-func Get[T any]() (T, error) {
-	var result T
+// checkCycle walks the registered factories reachable from interfaceName and
+// fails when one of them depends on itself. The result is memoized until the
+// next registration so the walk doesn't repeat on every resolution.
+func (c *Container) checkCycle(interfaceName string, reg *registration) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if reg.checked.Load() == c.generation+1 {
+		return nil
+	}
+
+	visited := make(map[string]bool)
+	path := []string{}
+
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		for i, step := range path {
+			if step == name {
+				return append(append([]string{}, path[i:]...), name)
+			}
+		}
+
+		current, exists := c.constructors[name]
+		if !exists || visited[name] {
+			return nil
+		}
+
+		path = append(path, name)
+		defer func() { path = path[:len(path)-1] }()
+
+		for _, dependency := range c.dependencies(current) {
+			if cycle := visit(dependency); cycle != nil {
+				return cycle
+			}
+		}
+
+		visited[name] = true
+
+		return nil
+	}
+
+	if cycle := visit(interfaceName); cycle != nil {
+		return errors.New(c.i18n.Get("di.circular_dependency", map[string]interface{}{"path": strings.Join(cycle, " -> ")}))
+	}
+
+	reg.checked.Store(c.generation + 1)
+
+	return nil
+}
+
+// Invoke calls fn with its parameters resolved from the container, like a
+// factory. When the last result of fn is an error, it is returned.
+func (c *Container) Invoke(fn interface{}) error {
+	fnType := reflect.TypeOf(fn)
+
+	if fnType == nil || fnType.Kind() != reflect.Func {
+		return errors.New(c.i18n.Get("di.invoke_not_func"))
+	}
 
-	resultType := reflect.TypeOf((*T)(nil)).Elem()
-	iType := resultType.PkgPath() + "/" + resultType.Name()
+	fnValue := reflect.ValueOf(fn)
 
-	instance, err := singletonInstance.GetByName(iType)
+	parameters, err := c.resolveParameters(fnValue, fnType.String(), nil)
 
 	if err != nil {
-		return result, err
+		return err
+	}
+
+	results := fnValue.Call(parameters)
+
+	if n := len(results); n > 0 && fnType.Out(n-1) == errorType && !results[n-1].IsNil() {
+		return results[n-1].Interface().(error)
+	}
+
+	return nil
+}
+
+// inherited reports whether interfaceName and everything it depends on are
+// registered in the child exactly as in its parent.
+func (c *Container) inherited(interfaceName string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	c.parent.mu.RLock()
+	defer c.parent.mu.RUnlock()
+
+	visited := make(map[string]bool)
+
+	var same func(name string) bool
+	same = func(name string) bool {
+		if visited[name] {
+			return true
+		}
+		visited[name] = true
+
+		current := c.constructors[name]
+		if current != c.parent.constructors[name] {
+			return false
+		}
+
+		if current != nil && !sameDecorators(c.decorators[current.typeName], c.parent.decorators[current.typeName]) {
+			return false
+		}
+
+		if current == nil {
+			return true
+		}
+
+		for _, dependency := range c.dependencies(current) {
+			if !same(dependency) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return same(interfaceName)
+}
+
+// evict drops the cached instance of key and of every singleton that depends on
+// it, so they are built again with the new registration. Must be called with
+// c.mu held.
+func (c *Container) evict(key string) {
+	delete(c.cached, key)
+
+	for cachedKey := range c.cached {
+		if c.dependsOn(cachedKey, key, make(map[string]bool)) {
+			delete(c.cached, cachedKey)
+		}
+	}
+}
+
+// dependsOn reports whether name reaches target through its factory
+// parameters. Must be called with c.mu held.
+func (c *Container) dependsOn(name string, target string, visited map[string]bool) bool {
+	current, exists := c.constructors[name]
+	if !exists || visited[name] {
+		return false
 	}
+	visited[name] = true
 
-	result, ok := instance.(T)
-	if !ok {
-		return result, errors.New("could not assert type to the expected type")
+	for _, dependency := range c.dependencies(current) {
+		if dependency == target || c.dependsOn(dependency, target, visited) {
+			return true
+		}
 	}
 
-	return result, nil
+	return false
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// isNil reports whether v holds a nil value, for the kinds that can be nil.
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice:
+		return v.IsNil()
+	}
+
+	return false
+}
+
+func removeKey(keys []string, key string) []string {
+	for i, existing := range keys {
+		if existing == key {
+			return append(keys[:i:i], keys[i+1:]...)
+		}
+	}
+
+	return keys
+}
+
+// typeName returns the key used to index factories by type. Unnamed types such
+// as pointers and slices use their full description so they don't collide.
+func typeName(t reflect.Type) string {
+	if t.Name() == "" {
+		return t.String()
+	}
+
+	return t.PkgPath() + "/" + t.Name()
+}
+
+// namedKey returns the key of a named registration of the type.
+func namedKey(interfaceName string, name string) string {
+	if name == "" {
+		return interfaceName
+	}
+
+	return interfaceName + "#" + name
+}
+
+// sameAs reports whether both registrations use the same factory and lifetime.
+func (r *registration) sameAs(other *registration) bool {
+	return r.lifetime == other.lifetime && r.sameFactory(other)
+}
+
+func (r *registration) sameFactory(other *registration) bool {
+	return reflect.ValueOf(r.factory).Pointer() == reflect.ValueOf(other.factory).Pointer()
 }
@@ -0,0 +1,391 @@
+package di
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/caiomarcatti12/nanogo/pkg/di/internal/fake"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestContainer() *Container {
+	return newContainer(fake.Translator{}, fake.Logger{})
+}
+
+type IRepository interface{ Name() string }
+
+type repository struct {
+	closed bool
+}
+
+func (r *repository) Name() string { return "repository" }
+func (r *repository) Close() error {
+	r.closed = true
+	return nil
+}
+
+func NewRepository() IRepository { return &repository{} }
+
+type IService interface{ Repository() IRepository }
+
+type service struct{ repo IRepository }
+
+func (s *service) Repository() IRepository { return s.repo }
+
+func NewService(repo IRepository) IService { return &service{repo: repo} }
+
+func TestRegister_SingletonReturnsSameInstance(t *testing.T) {
+	c := newTestContainer()
+	assert.NoError(t, c.Register(NewRepository))
+
+	first, err := c.GetByFactory(NewRepository)
+	assert.NoError(t, err)
+	second, err := c.GetByFactory(NewRepository)
+	assert.NoError(t, err)
+
+	assert.Same(t, first, second)
+}
+
+func TestRegister_TransientReturnsNewInstance(t *testing.T) {
+	c := newTestContainer()
+	assert.NoError(t, c.Register(NewRepository, AsTransient()))
+
+	first, err := c.GetByFactory(NewRepository)
+	assert.NoError(t, err)
+	second, err := c.GetByFactory(NewRepository)
+	assert.NoError(t, err)
+
+	assert.NotSame(t, first, second)
+}
+
+func TestRegister_ScopedSharedInsideScope(t *testing.T) {
+	c := newTestContainer()
+	assert.NoError(t, c.Register(NewRepository, AsScoped()))
+	assert.NoError(t, c.Register(NewService, AsTransient()))
+
+	scope := c.CreateScope()
+	svc, err := scope.GetByFactory(NewService)
+	assert.NoError(t, err)
+	repo, err := scope.GetByFactory(NewRepository)
+	assert.NoError(t, err)
+	assert.Same(t, repo, svc.(IService).Repository())
+
+	other := c.CreateScope()
+	otherRepo, err := other.GetByFactory(NewRepository)
+	assert.NoError(t, err)
+	assert.NotSame(t, repo, otherRepo)
+
+	scope.Close()
+	assert.True(t, repo.(*repository).closed)
+	assert.False(t, otherRepo.(*repository).closed)
+
+	_, err = scope.GetByFactory(NewRepository)
+	assert.ErrorContains(t, err, "di.scope_closed")
+}
+
+func TestScope_ReleasesLosingInstance(t *testing.T) {
+	c := newTestContainer()
+	scope := c.CreateScope().(*Scope)
+	winner := &repository{}
+	loser := &repository{}
+
+	// The nested call stores its instance while the outer build is still running.
+	instance, err := scope.getOrBuild("repository", func() (interface{}, error) {
+		_, err := scope.getOrBuild("repository", func() (interface{}, error) { return winner, nil })
+		return loser, err
+	})
+
+	assert.NoError(t, err)
+	assert.Same(t, winner, instance)
+	assert.True(t, loser.closed)
+	assert.False(t, winner.closed)
+}
+
+func TestScope_ReleasesTransientInstances(t *testing.T) {
+	c := newTestContainer()
+	assert.NoError(t, c.Register(NewRepository, AsTransient()))
+
+	scope := c.CreateScope()
+	first, err := scope.GetByFactory(NewRepository)
+	assert.NoError(t, err)
+	second, err := scope.GetByFactory(NewRepository)
+	assert.NoError(t, err)
+
+	// Resolved outside a scope, the instance belongs to the caller.
+	outside, err := c.GetByFactory(NewRepository)
+	assert.NoError(t, err)
+
+	scope.Close()
+	assert.True(t, first.(*repository).closed)
+	assert.True(t, second.(*repository).closed)
+	assert.False(t, outside.(*repository).closed)
+}
+
+func TestRegister_ScopedOutsideScopeFails(t *testing.T) {
+	c := newTestContainer()
+	assert.NoError(t, c.Register(NewRepository, AsScoped()))
+	assert.NoError(t, c.Register(NewService))
+
+	_, err := c.GetByFactory(NewRepository)
+	assert.ErrorContains(t, err, "di.scoped_outside_scope")
+
+	// Singletons can't capture scoped dependencies even when resolved from a scope.
+	_, err = c.CreateScope().GetByFactory(NewService)
+	assert.ErrorContains(t, err, "di.scoped_outside_scope")
+}
+
+func TestRegister_ReplacingFactoryDropsCachedInstance(t *testing.T) {
+	c := newTestContainer()
+	assert.NoError(t, c.Register(NewRepository))
+
+	first, err := c.GetByFactory(NewRepository)
+	assert.NoError(t, err)
+
+	assert.NoError(t, c.Register(NewRepository, AsTransient()))
+	second, err := c.GetByFactory(NewRepository)
+	assert.NoError(t, err)
+
+	assert.NotSame(t, first, second)
+}
+
+type ICycleA interface{}
+type ICycleB interface{}
+
+func NewCycleA(b ICycleB) ICycleA { return struct{}{} }
+func NewCycleB(a ICycleA) ICycleB { return struct{}{} }
+
+func TestResolve_CircularDependencyReportsPath(t *testing.T) {
+	c := newTestContainer()
+	assert.NoError(t, c.Register(NewCycleA))
+	assert.NoError(t, c.Register(NewCycleB))
+
+	_, err := c.GetByFactory(NewCycleA)
+
+	a := "github.com/caiomarcatti12/nanogo/pkg/di/ICycleA"
+	b := "github.com/caiomarcatti12/nanogo/pkg/di/ICycleB"
+	assert.ErrorContains(t, err, "di.circular_dependency path="+a+" -> "+b+" -> "+a)
+}
+
+type ICounter interface{}
+
+func TestResolve_SingletonBuiltOnceUnderContention(t *testing.T) {
+	c := newTestContainer()
+
+	var builds atomic.Int32
+	assert.NoError(t, c.Register(func() ICounter {
+		builds.Add(1)
+		return &repository{}
+	}))
+
+	var wg sync.WaitGroup
+	instances := make([]interface{}, 50)
+
+	for i := range instances {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			instances[i], _ = c.GetByName("github.com/caiomarcatti12/nanogo/pkg/di/ICounter")
+		}(i)
+	}
+
+	// Registrations may happen while other goroutines resolve.
+	assert.NoError(t, c.Register(NewRepository))
+
+	wg.Wait()
+
+	assert.Equal(t, int32(1), builds.Load())
+	for _, instance := range instances {
+		assert.Same(t, instances[0], instance)
+	}
+}
+
+type primaryRepository struct{ repository }
+type replicaRepository struct{ repository }
+
+func (r *replicaRepository) Name() string { return "replica" }
+
+type repositoryParams struct {
+	In
+	Primary IRepository
+	Replica IRepository `name:"replica"`
+}
+
+type IRepositoryPair interface{}
+
+type repositoryPair struct {
+	primary IRepository
+	replica IRepository
+}
+
+func NewRepositoryPair(params repositoryParams) IRepositoryPair {
+	return &repositoryPair{primary: params.Primary, replica: params.Replica}
+}
+
+func TestRegister_NamedRegistrationsLiveSideBySide(t *testing.T) {
+	c := newTestContainer()
+	assert.NoError(t, c.Register(func() IRepository { return &primaryRepository{} }))
+	assert.NoError(t, c.Register(func() IRepository { return &replicaRepository{} }, WithName("replica")))
+	assert.NoError(t, c.Register(NewRepositoryPair))
+
+	pair, err := c.GetByFactory(NewRepositoryPair)
+	assert.NoError(t, err)
+	assert.IsType(t, &primaryRepository{}, pair.(*repositoryPair).primary)
+	assert.IsType(t, &replicaRepository{}, pair.(*repositoryPair).replica)
+
+	replica, err := c.GetNamed("github.com/caiomarcatti12/nanogo/pkg/di/IRepository", "replica")
+	assert.NoError(t, err)
+	assert.Same(t, pair.(*repositoryPair).replica, replica)
+}
+
+type optionalParams struct {
+	In
+	Repository IRepository `optional:"true"`
+	Replica    IRepository `name:"replica" optional:"true"`
+}
+
+type IOptionalConsumer interface{}
+
+func TestRegister_OptionalFieldsKeepZeroValueWhenUnregistered(t *testing.T) {
+	c := newTestContainer()
+
+	var received optionalParams
+	assert.NoError(t, c.Register(func(params optionalParams) IOptionalConsumer {
+		received = params
+		return &repository{}
+	}))
+	assert.NoError(t, c.Validate())
+
+	_, err := c.GetByName("github.com/caiomarcatti12/nanogo/pkg/di/IOptionalConsumer")
+	assert.NoError(t, err)
+	assert.Nil(t, received.Repository)
+	assert.Nil(t, received.Replica)
+}
+
+func TestRegister_OptionalFieldsResolveWhenRegistered(t *testing.T) {
+	c := newTestContainer()
+	assert.NoError(t, c.Register(func() IRepository { return &primaryRepository{} }))
+
+	var received optionalParams
+	assert.NoError(t, c.Register(func(params optionalParams) IOptionalConsumer {
+		received = params
+		return &repository{}
+	}))
+
+	_, err := c.GetByName("github.com/caiomarcatti12/nanogo/pkg/di/IOptionalConsumer")
+	assert.NoError(t, err)
+	assert.IsType(t, &primaryRepository{}, received.Repository)
+	assert.Nil(t, received.Replica)
+}
+
+type IHealthCheck interface{ Name() string }
+
+type healthCheck struct{ name string }
+
+func (h *healthCheck) Name() string { return h.name }
+
+type IHealthRegistry interface{}
+
+func NewDatabaseCheck() IHealthCheck { return &healthCheck{name: "database"} }
+func NewCacheCheck() IHealthCheck    { return &healthCheck{name: "cache"} }
+
+func TestRegister_GroupedRegistrationsResolveAsSlice(t *testing.T) {
+	c := newTestContainer()
+	assert.NoError(t, c.Register(NewDatabaseCheck, Grouped()))
+	assert.NoError(t, c.Register(NewCacheCheck, Grouped()))
+	assert.NoError(t, c.Register(NewCacheCheck, Grouped()))
+
+	var received []IHealthCheck
+	assert.NoError(t, c.Register(func(checks []IHealthCheck) IHealthRegistry {
+		received = checks
+		return &repository{}
+	}))
+
+	_, err := c.GetByName("github.com/caiomarcatti12/nanogo/pkg/di/IHealthRegistry")
+	assert.NoError(t, err)
+
+	if assert.Len(t, received, 2) {
+		assert.Equal(t, "database", received[0].Name())
+		assert.Equal(t, "cache", received[1].Name())
+	}
+
+	_, err = c.GetByFactory(NewDatabaseCheck)
+	assert.ErrorContains(t, err, "di.no_service_registered_for_type")
+}
+
+func TestRegister_PointerFactoriesDoNotCollide(t *testing.T) {
+	c := newTestContainer()
+	assert.NoError(t, c.Register(func() *primaryRepository { return &primaryRepository{} }))
+	assert.NoError(t, c.Register(func() *replicaRepository { return &replicaRepository{} }))
+
+	primary, err := c.GetByName("*di.primaryRepository")
+	assert.NoError(t, err)
+	assert.IsType(t, &primaryRepository{}, primary)
+}
+
+func TestOverride_RestoreRemovesNewRegistration(t *testing.T) {
+	c := newTestContainer()
+
+	restore, err := c.Override(NewRepository)
+	assert.NoError(t, err)
+	assert.True(t, c.IsRegistered(NewRepository))
+
+	restore()
+
+	assert.False(t, c.IsRegistered(NewRepository))
+	group, err := c.GetGroup("github.com/caiomarcatti12/nanogo/pkg/di/IRepository")
+	assert.NoError(t, err)
+	assert.Empty(t, group)
+}
+
+func TestCreateChild_OverrideDetectsNewCycle(t *testing.T) {
+	parent := newTestContainer()
+	assert.NoError(t, parent.Register(NewRepository))
+	assert.NoError(t, parent.Register(NewService))
+
+	_, err := parent.GetByFactory(NewService)
+	assert.NoError(t, err)
+
+	child := parent.CreateChild()
+	assert.NoError(t, child.Register(func(service IService) IRepository { return &repository{} }))
+
+	_, err = child.GetByFactory(NewService)
+	assert.ErrorContains(t, err, "di.circular_dependency")
+}
+
+func TestOverride_RebuildsCachedDependents(t *testing.T) {
+	c := newTestContainer()
+	assert.NoError(t, c.Register(NewRepository))
+	assert.NoError(t, c.Register(NewService))
+
+	before, err := c.GetByFactory(NewService)
+	assert.NoError(t, err)
+
+	replacement := &repository{}
+	restore, err := c.Override(func() IRepository { return replacement })
+	assert.NoError(t, err)
+
+	during, err := c.GetByFactory(NewService)
+	assert.NoError(t, err)
+	assert.Same(t, replacement, during.(IService).Repository())
+
+	restore()
+
+	after, err := c.GetByFactory(NewService)
+	assert.NoError(t, err)
+	assert.NotSame(t, replacement, after.(IService).Repository())
+	assert.Same(t, before.(IService).Repository(), after.(IService).Repository())
+}
+
+func TestLifetime_ReturnsRegisteredLifetime(t *testing.T) {
+	c := newTestContainer()
+
+	_, registered := c.Lifetime(NewRepository)
+	assert.False(t, registered)
+
+	assert.NoError(t, c.Register(NewRepository, WithLifetime(Scoped)))
+
+	lifetime, registered := c.Lifetime(NewRepository)
+	assert.True(t, registered)
+	assert.Equal(t, Scoped, lifetime)
+}
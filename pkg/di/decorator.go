@@ -0,0 +1,155 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package di
+
+import (
+	"errors"
+	"reflect"
+)
+
+// decorator wraps the instances of a type. Its first parameter and first result
+// are the decorated type; the other parameters are resolved like a factory's.
+type decorator struct {
+	fn interface{}
+}
+
+// Decorate registers a decorator for the type of its first parameter, such as
+// func(repo IUserRepository, cache cache.ICache) IUserRepository. Every time
+// the type is built, under any name or group, its decorators run in
+// registration order, each one receiving the result of the previous. The
+// decorator may also return an error as its second result.
+func (c *Container) Decorate(decoratorFunc interface{}) error {
+	fnType := reflect.TypeOf(decoratorFunc)
+
+	if !isDecorator(fnType) {
+		return errors.New(c.i18n.Get("di.decorator_invalid", map[string]interface{}{"decorator": describe(fnType)}))
+	}
+
+	decorated := typeName(fnType.In(0))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.log.Trace(c.i18n.Get("di.register_decorator", map[string]interface{}{"factory": decorated}))
+
+	if c.decorators == nil {
+		c.decorators = make(map[string][]*decorator)
+	}
+
+	c.decorators[decorated] = append(c.decorators[decorated], &decorator{fn: decoratorFunc})
+
+	// Instances built before the decorator existed are built again with it.
+	for _, key := range c.implementations[decorated] {
+		c.evict(key)
+	}
+
+	c.generation = generations.Add(1)
+
+	return nil
+}
+
+// Decorate registers a decorator that only needs the instance it wraps.
+func Decorate[T any](c IContainer, decorator func(T) T) error {
+	return c.Decorate(decorator)
+}
+
+// decorate applies the decorators of reg's type to instance.
+func (c *Container) decorate(reg *registration, interfaceName string, instance interface{}, scope *Scope) (interface{}, error) {
+	c.mu.RLock()
+	decorators := c.decorators[reg.typeName]
+	c.mu.RUnlock()
+
+	for _, decorator := range decorators {
+		c.log.Trace(c.i18n.Get("di.apply_decorator", map[string]interface{}{"factory": interfaceName}))
+
+		fnValue := reflect.ValueOf(decorator.fn)
+		fnType := fnValue.Type()
+
+		parameters := []reflect.Value{reflect.ValueOf(instance)}
+
+		for i := 1; i < fnType.NumIn(); i++ {
+			parameter, err := c.resolveParameter(fnType.In(i), "", scope)
+
+			if err != nil {
+				return nil, err
+			}
+
+			parameters = append(parameters, parameter)
+		}
+
+		results := fnValue.Call(parameters)
+
+		if len(results) == 2 && !results[1].IsNil() {
+			return nil, results[1].Interface().(error)
+		}
+
+		if isNil(results[0]) {
+			return nil, errors.New(c.i18n.Get("di.decorator_returned_nil", map[string]interface{}{"factory": interfaceName}))
+		}
+
+		instance = results[0].Interface()
+	}
+
+	return instance, nil
+}
+
+// dependencies returns the keys of the parameters resolved from the container.
+// Must be called with c.mu held.
+func (d *decorator) dependencies(c *Container) []string {
+	fnType := reflect.TypeOf(d.fn)
+
+	var dependencies []string
+
+	for i := 1; i < fnType.NumIn(); i++ {
+		dependencies = append(dependencies, c.parameterDependencies(fnType.In(i), "")...)
+	}
+
+	return dependencies
+}
+
+func isDecorator(fnType reflect.Type) bool {
+	if fnType == nil || fnType.Kind() != reflect.Func || fnType.NumIn() < 1 {
+		return false
+	}
+
+	if fnType.NumOut() < 1 || fnType.NumOut() > 2 || fnType.Out(0) != fnType.In(0) {
+		return false
+	}
+
+	return fnType.NumOut() == 1 || fnType.Out(1) == errorType
+}
+
+func sameDecorators(a []*decorator, b []*decorator) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func describe(t reflect.Type) string {
+	if t == nil {
+		return "nil"
+	}
+
+	return t.String()
+}
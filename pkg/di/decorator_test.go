@@ -0,0 +1,88 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package di
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type cachedRepository struct {
+	IRepository
+}
+
+func (r *cachedRepository) Name() string { return "cached " + r.IRepository.Name() }
+
+type tracedRepository struct {
+	IRepository
+}
+
+func (r *tracedRepository) Name() string { return "traced " + r.IRepository.Name() }
+
+func TestDecorate_AppliesInRegistrationOrder(t *testing.T) {
+	c := newTestContainer()
+	assert.NoError(t, c.Register(NewRepository))
+	assert.NoError(t, Decorate[IRepository](c, func(repo IRepository) IRepository { return &cachedRepository{IRepository: repo} }))
+	assert.NoError(t, Decorate[IRepository](c, func(repo IRepository) IRepository { return &tracedRepository{IRepository: repo} }))
+
+	repo := MustGet[IRepository](c)
+
+	assert.Equal(t, "traced cached repository", repo.Name())
+	assert.Same(t, repo, MustGet[IRepository](c), "the decorated singleton is cached")
+}
+
+func TestDecorate_ResolvesExtraParameters(t *testing.T) {
+	c := newTestContainer()
+	assert.NoError(t, c.Register(NewRepository))
+	assert.NoError(t, c.Register(NewService))
+	assert.NoError(t, c.Decorate(func(original IService, repo IRepository) IService {
+		return &service{repo: &tracedRepository{IRepository: repo}}
+	}))
+
+	assert.Equal(t, "traced repository", MustGet[IService](c).Repository().Name())
+}
+
+func TestDecorate_RebuildsCachedInstances(t *testing.T) {
+	c := newTestContainer()
+	assert.NoError(t, c.Register(NewRepository))
+	assert.NoError(t, c.Register(NewService))
+
+	before := MustGet[IService](c)
+	assert.NoError(t, Decorate[IRepository](c, func(repo IRepository) IRepository { return &tracedRepository{IRepository: repo} }))
+	after := MustGet[IService](c)
+
+	assert.NotSame(t, before, after)
+	assert.Equal(t, "traced repository", after.Repository().Name())
+}
+
+func TestDecorate_RejectsInvalidDecorators(t *testing.T) {
+	c := newTestContainer()
+
+	assert.ErrorContains(t, c.Decorate(func(repo IRepository) IService { return nil }), "di.decorator_invalid")
+	assert.ErrorContains(t, c.Decorate(func() IRepository { return nil }), "di.decorator_invalid")
+	assert.ErrorContains(t, c.Decorate("decorator"), "di.decorator_invalid")
+}
+
+func TestDecorate_DetectsCycleThroughDecorator(t *testing.T) {
+	c := newTestContainer()
+	assert.NoError(t, c.Register(NewRepository))
+	assert.NoError(t, c.Register(NewService))
+	assert.NoError(t, c.Decorate(func(repo IRepository, service IService) IRepository { return repo }))
+
+	_, err := Resolve[IRepository](c)
+	assert.ErrorContains(t, err, "di.circular_dependency")
+}
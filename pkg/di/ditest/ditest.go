@@ -0,0 +1,75 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package ditest ajuda a testar código que depende do container de injeção de
+// dependências: cria containers isolados, troca registros durante um teste e
+// substitui o container global usado por di.GetInstance e di.Get.
+package ditest
+
+import (
+	"testing"
+
+	"github.com/caiomarcatti12/nanogo/pkg/di"
+	"github.com/caiomarcatti12/nanogo/pkg/di/internal/fake"
+)
+
+// New cria um container independente, com logger silencioso e mensagens
+// formadas pela chave de tradução seguida das variáveis.
+func New(t testing.TB) di.IContainer {
+	t.Helper()
+
+	return di.New(Translator{}, Logger{})
+}
+
+// NewGlobal cria um container com New e o instala como container global até o
+// fim do teste.
+func NewGlobal(t testing.TB) di.IContainer {
+	t.Helper()
+
+	container := New(t)
+	UseGlobal(t, container)
+
+	return container
+}
+
+// UseGlobal instala container como container global até o fim do teste.
+// Testes que usam o container global não devem rodar com t.Parallel.
+func UseGlobal(t testing.TB, container di.IContainer) {
+	t.Helper()
+
+	t.Cleanup(di.SetInstance(container))
+}
+
+// Override troca o registro do tipo retornado por factoryFunc até o fim do
+// teste. O registro anterior e a instância já criada por ele são restaurados
+// no cleanup.
+func Override(t testing.TB, container di.IContainer, factoryFunc interface{}, options ...di.RegisterOption) {
+	t.Helper()
+
+	restore, err := container.Override(factoryFunc, options...)
+	if err != nil {
+		t.Fatalf("ditest: override failed: %v", err)
+	}
+
+	t.Cleanup(restore)
+}
+
+// Translator é o i18n.I18N usado pelos containers de New: devolve a chave de
+// tradução seguida das variáveis em ordem alfabética.
+type Translator = fake.Translator
+
+// Logger é o log.ILog silencioso usado pelos containers de New.
+type Logger = fake.Logger
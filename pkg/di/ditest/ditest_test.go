@@ -0,0 +1,130 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package ditest
+
+import (
+	"testing"
+
+	"github.com/caiomarcatti12/nanogo/pkg/db"
+	"github.com/caiomarcatti12/nanogo/pkg/di"
+	"github.com/caiomarcatti12/nanogo/pkg/log"
+	"github.com/caiomarcatti12/nanogo/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+type IUserService interface {
+	Repository() db.IMongoORM[any]
+}
+
+type userService struct {
+	orm db.IMongoORM[any]
+}
+
+func (s *userService) Repository() db.IMongoORM[any] { return s.orm }
+
+func NewUserService(orm db.IMongoORM[any]) IUserService { return &userService{orm: orm} }
+
+func newORM(database db.IDatabase, logger log.ILog) db.IMongoORM[any] {
+	return db.NewMongoORM[any](database, logger)
+}
+
+func TestOverride_ReplacesRegistrationDuringTest(t *testing.T) {
+	container := New(t)
+	assert.NoError(t, container.Register(newORM))
+	assert.NoError(t, container.Register(NewUserService))
+
+	orm := mocks.NewIMongoORM[any](t)
+
+	t.Run("override", func(t *testing.T) {
+		Override(t, container, func() db.IMongoORM[any] { return orm })
+
+		service, err := container.GetByFactory(NewUserService)
+		assert.NoError(t, err)
+		assert.Same(t, orm, service.(IUserService).Repository())
+	})
+
+	// The original factory is back and needs an IDatabase, which isn't registered.
+	_, err := container.GetByFactory(NewUserService)
+	assert.Error(t, err)
+}
+
+func TestOverride_RestoresPreviousInstance(t *testing.T) {
+	container := New(t)
+	orm := mocks.NewIMongoORM[any](t)
+	assert.NoError(t, container.Register(func() db.IMongoORM[any] { return orm }))
+
+	original, err := container.GetByFactory(newORM)
+	assert.NoError(t, err)
+
+	t.Run("override", func(t *testing.T) {
+		Override(t, container, func() db.IMongoORM[any] { return mocks.NewIMongoORM[any](t) })
+
+		overridden, err := container.GetByFactory(newORM)
+		assert.NoError(t, err)
+		assert.NotSame(t, orm, overridden)
+	})
+
+	restored, err := container.GetByFactory(newORM)
+	assert.NoError(t, err)
+	assert.Same(t, original, restored)
+}
+
+func TestChild_OverridesDoNotLeakToParent(t *testing.T) {
+	parent := New(t)
+	orm := mocks.NewIMongoORM[any](t)
+	assert.NoError(t, parent.Register(func() db.IMongoORM[any] { return orm }))
+	assert.NoError(t, parent.Register(NewUserService))
+
+	child := parent.CreateChild()
+	childORM := mocks.NewIMongoORM[any](t)
+	assert.NoError(t, child.Register(func() db.IMongoORM[any] { return childORM }))
+
+	fromChild, err := child.GetByFactory(NewUserService)
+	assert.NoError(t, err)
+	fromParent, err := parent.GetByFactory(NewUserService)
+	assert.NoError(t, err)
+
+	assert.Same(t, childORM, fromChild.(IUserService).Repository())
+	assert.Same(t, orm, fromParent.(IUserService).Repository())
+}
+
+func TestChild_SharesUntouchedSingletons(t *testing.T) {
+	parent := New(t)
+	orm := mocks.NewIMongoORM[any](t)
+	assert.NoError(t, parent.Register(func() db.IMongoORM[any] { return orm }))
+	assert.NoError(t, parent.Register(NewUserService))
+
+	child := parent.CreateChild()
+
+	fromChild, err := child.GetByFactory(NewUserService)
+	assert.NoError(t, err)
+	fromParent, err := parent.GetByFactory(NewUserService)
+	assert.NoError(t, err)
+
+	assert.Same(t, fromParent, fromChild)
+}
+
+func TestUseGlobal_RestoresPreviousGlobal(t *testing.T) {
+	previous := di.GetInstance()
+
+	t.Run("global", func(t *testing.T) {
+		container := NewGlobal(t)
+		assert.NoError(t, container.Register(NewUserService))
+		assert.Same(t, container, di.GetInstance())
+	})
+
+	assert.Equal(t, previous, di.GetInstance())
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package di
+
+import (
+	"errors"
+	"reflect"
+)
+
+// IResolver is the resolution side shared by containers and scopes, so the
+// typed helpers below work with both.
+type IResolver interface {
+	GetByName(interfaceName string) (interface{}, error)
+	GetNamed(interfaceName string, name string) (interface{}, error)
+	GetGroup(interfaceName string) ([]interface{}, error)
+}
+
+// Provide registers factory as the provider of T. The factory may return T or
+// any type assignable to T, such as a concrete pointer implementing the
+// interface T, optionally followed by an error. A factory with a different
+// signature is rejected here instead of on the first resolution.
+func Provide[T any](c IContainer, factory interface{}, options ...RegisterOption) error {
+	return c.Register(factory, append(options, as(typeOf[T]()))...)
+}
+
+// ProvideValue registers an existing value as the singleton of T.
+func ProvideValue[T any](c IContainer, value T, options ...RegisterOption) error {
+	return Provide[T](c, func() T { return value }, options...)
+}
+
+// Bind makes every resolution of I return the instance registered for Impl,
+// failing on registration when Impl doesn't implement I. Bindings are
+// transient by default so the lifetime of Impl decides how it is shared.
+func Bind[I any, Impl any](c IContainer, options ...RegisterOption) error {
+	implType := typeOf[Impl]()
+
+	identity := reflect.MakeFunc(
+		reflect.FuncOf([]reflect.Type{implType}, []reflect.Type{implType}, false),
+		func(args []reflect.Value) []reflect.Value { return args },
+	)
+
+	return c.Register(identity.Interface(), append([]RegisterOption{AsTransient()}, append(options, as(typeOf[I]()))...)...)
+}
+
+// Invoke calls fn with its parameters resolved from c and returns the error
+// fn returns, if any.
+func Invoke(c IContainer, fn interface{}) error {
+	return c.Invoke(fn)
+}
+
+// Resolve retrieves the instance of T from a container or scope.
+func Resolve[T any](r IResolver) (T, error) {
+	var result T
+
+	instance, err := r.GetByName(typeName(typeOf[T]()))
+
+	if err != nil {
+		return result, err
+	}
+
+	return assertType[T](instance)
+}
+
+// ResolveNamed retrieves the instance of T registered with WithName(name).
+func ResolveNamed[T any](r IResolver, name string) (T, error) {
+	var result T
+
+	instance, err := r.GetNamed(typeName(typeOf[T]()), name)
+
+	if err != nil {
+		return result, err
+	}
+
+	return assertType[T](instance)
+}
+
+// ResolveAll retrieves every implementation registered for T.
+func ResolveAll[T any](r IResolver) ([]T, error) {
+	instances, err := r.GetGroup(typeName(typeOf[T]()))
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]T, 0, len(instances))
+
+	for _, instance := range instances {
+		typed, err := assertType[T](instance)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, typed)
+	}
+
+	return result, nil
+}
+
+// MustGet is like Resolve but panics when T can't be resolved. Meant for
+// application startup, where a missing registration is a programming error.
+func MustGet[T any](r IResolver) T {
+	instance, err := Resolve[T](r)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return instance
+}
+
+// Get retrieves an instance of the requested type from the global container.
+func Get[T any]() (T, error) {
+	return Resolve[T](singletonInstance)
+}
+
+// GetNamed retrieves the instance of the requested type registered with
+// WithName(name) from the global container.
+func GetNamed[T any](name string) (T, error) {
+	return ResolveNamed[T](singletonInstance, name)
+}
+
+// GetAll retrieves every implementation registered for the requested type from
+// the global container.
+func GetAll[T any]() ([]T, error) {
+	return ResolveAll[T](singletonInstance)
+}
+
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func assertType[T any](instance interface{}) (T, error) {
+	result, ok := instance.(T)
+	if !ok {
+		return result, errors.New("could not assert type to the expected type")
+	}
+
+	return result, nil
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package di
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newRepositoryImpl() *repository { return &repository{} }
+
+func TestProvide_RegistersConcreteFactoryUnderInterface(t *testing.T) {
+	c := newTestContainer()
+	assert.NoError(t, Provide[IRepository](c, newRepositoryImpl))
+	assert.NoError(t, c.Register(NewService))
+
+	service, err := Resolve[IService](c)
+	assert.NoError(t, err)
+	assert.IsType(t, &repository{}, service.Repository())
+}
+
+func TestProvide_RejectsWrongFactoryOnRegistration(t *testing.T) {
+	c := newTestContainer()
+
+	assert.ErrorContains(t, Provide[IService](c, newRepositoryImpl), "di.factory_type_mismatch")
+	assert.ErrorContains(t, Provide[IRepository](c, "not a function"), "di.factory_func_is_not_func")
+	assert.ErrorContains(t, Provide[IRepository](c, func() (IRepository, string) { return nil, "" }), "di.factory_second_result_not_error")
+}
+
+func TestProvideValue_RegistersExistingValue(t *testing.T) {
+	c := newTestContainer()
+	repo := &repository{}
+	assert.NoError(t, ProvideValue[IRepository](c, repo))
+
+	assert.Same(t, repo, MustGet[IRepository](c))
+}
+
+func TestBind_ResolvesInterfaceToImplementation(t *testing.T) {
+	c := newTestContainer()
+	assert.NoError(t, c.Register(newRepositoryImpl))
+	assert.NoError(t, Bind[IRepository, *repository](c))
+
+	first := MustGet[IRepository](c)
+	second := MustGet[IRepository](c)
+
+	assert.Same(t, first, second, "the binding follows the singleton lifetime of the implementation")
+	assert.ErrorContains(t, Bind[IService, *repository](c), "di.factory_type_mismatch")
+}
+
+func TestInvoke_ResolvesParametersAndReturnsError(t *testing.T) {
+	c := newTestContainer()
+	assert.NoError(t, c.Register(NewRepository))
+
+	var received IRepository
+	assert.NoError(t, Invoke(c, func(repo IRepository) { received = repo }))
+	assert.NotNil(t, received)
+
+	failure := errors.New("migration failed")
+	assert.ErrorIs(t, Invoke(c, func(repo IRepository) error { return failure }), failure)
+	assert.ErrorContains(t, Invoke(c, func(service IService) {}), "di.no_service_registered_for_type")
+}
+
+func TestMustGet_PanicsWhenNotRegistered(t *testing.T) {
+	c := newTestContainer()
+
+	assert.Panics(t, func() { MustGet[IRepository](c) })
+}
+
+func TestResolve_WorksWithScopes(t *testing.T) {
+	c := newTestContainer()
+	assert.NoError(t, Provide[IRepository](c, newRepositoryImpl, AsScoped()))
+
+	scope := c.CreateScope()
+	defer scope.Close()
+
+	first, err := Resolve[IRepository](scope)
+	assert.NoError(t, err)
+	assert.Same(t, first, MustGet[IRepository](scope))
+}
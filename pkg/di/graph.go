@@ -0,0 +1,130 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package di
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// GraphNode describes a registration and the registrations it depends on.
+type GraphNode struct {
+	Key          string   `json:"key"`
+	Type         string   `json:"type"`
+	Name         string   `json:"name,omitempty"`
+	Grouped      bool     `json:"grouped,omitempty"`
+	Lifetime     string   `json:"lifetime"`
+	Factory      string   `json:"factory"`
+	Decorators   []string `json:"decorators,omitempty"`
+	Dependencies []string `json:"dependencies"`
+	Missing      []string `json:"missing,omitempty"`
+}
+
+// Graph is a snapshot of the container wiring, ordered by registration key.
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+}
+
+// Graph exports the dependency graph of every registered factory.
+func (c *Container) Graph() Graph {
+	graph := Graph{Nodes: []GraphNode{}}
+
+	for _, key := range c.sortedKeys() {
+		c.mu.RLock()
+		reg := c.constructors[key]
+		dependencies := c.dependencies(reg)
+		decorators := c.decorators[reg.typeName]
+		c.mu.RUnlock()
+
+		node := GraphNode{
+			Key:          key,
+			Type:         reg.typeName,
+			Name:         reg.name,
+			Grouped:      reg.grouped,
+			Lifetime:     reg.lifetime.String(),
+			Factory:      funcName(reg.factory),
+			Dependencies: []string{},
+		}
+
+		for _, decorator := range decorators {
+			node.Decorators = append(node.Decorators, funcName(decorator.fn))
+		}
+
+		for _, dependency := range dependencies {
+			node.Dependencies = append(node.Dependencies, dependency)
+
+			if _, exists := c.lookup(dependency); !exists {
+				node.Missing = append(node.Missing, dependency)
+			}
+		}
+
+		graph.Nodes = append(graph.Nodes, node)
+	}
+
+	return graph
+}
+
+func funcName(fn interface{}) string {
+	return runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+}
+
+// JSON renders the graph as indented JSON.
+func (g Graph) JSON() ([]byte, error) {
+	return json.MarshalIndent(g, "", "  ")
+}
+
+// DOT renders the graph in Graphviz DOT format. Missing dependencies are drawn
+// as dashed red nodes.
+func (g Graph) DOT() string {
+	var builder strings.Builder
+	missing := make(map[string]bool)
+
+	builder.WriteString("digraph di {\n")
+	builder.WriteString("  rankdir=LR;\n")
+	builder.WriteString("  node [shape=box];\n")
+
+	for _, node := range g.Nodes {
+		fmt.Fprintf(&builder, "  %q [label=%q];\n", node.Key, node.Key+"\n"+node.Lifetime)
+
+		for _, dependency := range node.Missing {
+			missing[dependency] = true
+		}
+	}
+
+	missingKeys := make([]string, 0, len(missing))
+	for dependency := range missing {
+		missingKeys = append(missingKeys, dependency)
+	}
+	sort.Strings(missingKeys)
+
+	for _, dependency := range missingKeys {
+		fmt.Fprintf(&builder, "  %q [style=dashed, color=red];\n", dependency)
+	}
+
+	for _, node := range g.Nodes {
+		for _, dependency := range node.Dependencies {
+			fmt.Fprintf(&builder, "  %q -> %q;\n", node.Key, dependency)
+		}
+	}
+
+	builder.WriteString("}\n")
+
+	return builder.String()
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package fake has the translator and logger used by the container tests. It
+// lives under di so the container's own tests can use it without importing
+// ditest, which depends on di; other packages use the aliases in ditest.
+package fake
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Translator returns the translation key followed by the sorted variables, so
+// assertions don't depend on the YAML files.
+type Translator struct{}
+
+func (Translator) SetLanguage(lang string)            {}
+func (Translator) GetLanguage() string                { return "en-us" }
+func (Translator) GetDefaultLanguage() string         { return "en-us" }
+func (Translator) LoadTranslations(path string) error { return nil }
+func (Translator) Get(key string, vars ...map[string]interface{}) string {
+	if len(vars) == 0 {
+		return key
+	}
+
+	parts := []string{key}
+	for name, value := range vars[0] {
+		parts = append(parts, fmt.Sprintf("%s=%v", name, value))
+	}
+	sort.Strings(parts[1:])
+
+	return strings.Join(parts, " ")
+}
+
+// Logger discards every message.
+type Logger struct{}
+
+func (Logger) Fatal(message string, args ...interface{})   {}
+func (Logger) Debug(message string, args ...interface{})   {}
+func (Logger) Info(message string, args ...interface{})    {}
+func (Logger) Error(message string, args ...interface{})   {}
+func (Logger) Warning(message string, args ...interface{}) {}
+func (Logger) Trace(message string, args ...interface{})   {}
+
+func (Logger) Fatalf(message string, args ...interface{})   {}
+func (Logger) Debugf(message string, args ...interface{})   {}
+func (Logger) Infof(message string, args ...interface{})    {}
+func (Logger) Errorf(message string, args ...interface{})   {}
+func (Logger) Warningf(message string, args ...interface{}) {}
+func (Logger) Tracef(message string, args ...interface{})   {}
@@ -0,0 +1,141 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package di
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// stopGracePeriod is how long a hook still gets once ctx has expired, so quick
+// cleanups after a stuck component are not reported as timeouts.
+var stopGracePeriod = 100 * time.Millisecond
+
+// IStarter is implemented by components that need to run code when the
+// application starts, such as opening connections.
+type IStarter interface {
+	OnStart(ctx context.Context) error
+}
+
+// IStopper is implemented by components that need to release resources when
+// the application stops, such as closing connections or flushing buffers.
+type IStopper interface {
+	OnStop(ctx context.Context) error
+}
+
+// Start calls OnStart on every singleton already built, in dependency order.
+// Singletons built afterwards are started as soon as they are created.
+func (c *Container) Start(ctx context.Context) error {
+	c.mu.Lock()
+	if c.started {
+		c.mu.Unlock()
+		return nil
+	}
+	c.started = true
+	instances := append([]interface{}{}, c.built...)
+	c.mu.Unlock()
+
+	for _, instance := range instances {
+		if err := c.startInstance(ctx, instance); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Stop calls OnStop on every singleton built by the container in the reverse
+// order they were created. Each hook gets the remaining time of ctx; hooks that
+// don't return in time are reported and the next ones still run.
+func (c *Container) Stop(ctx context.Context) error {
+	c.mu.Lock()
+	c.started = false
+	instances := append([]interface{}{}, c.built...)
+	c.mu.Unlock()
+
+	var errs []error
+
+	for i := len(instances) - 1; i >= 0; i-- {
+		stopper, ok := instances[i].(IStopper)
+
+		if !ok {
+			continue
+		}
+
+		component := fmt.Sprintf("%T", instances[i])
+		c.log.Trace(c.i18n.Get("di.lifecycle_stopping", map[string]interface{}{"component": component}))
+
+		if err := c.stopInstance(ctx, stopper); err != nil {
+			c.log.Error(c.i18n.Get("di.lifecycle_stop_failed", map[string]interface{}{"component": component, "error": err.Error()}))
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Container) startIfRunning(instance interface{}) error {
+	c.mu.RLock()
+	started := c.started
+	c.mu.RUnlock()
+
+	if !started {
+		return nil
+	}
+
+	return c.startInstance(context.Background(), instance)
+}
+
+func (c *Container) startInstance(ctx context.Context, instance interface{}) error {
+	starter, ok := instance.(IStarter)
+
+	if !ok {
+		return nil
+	}
+
+	component := fmt.Sprintf("%T", instance)
+	c.log.Trace(c.i18n.Get("di.lifecycle_starting", map[string]interface{}{"component": component}))
+
+	if err := starter.OnStart(ctx); err != nil {
+		return errors.New(c.i18n.Get("di.lifecycle_start_failed", map[string]interface{}{"component": component, "error": err.Error()}))
+	}
+
+	return nil
+}
+
+// stopInstance runs OnStop without letting a stuck hook block past ctx.
+func (c *Container) stopInstance(ctx context.Context, stopper IStopper) error {
+	done := make(chan error, 1)
+
+	go func() {
+		done <- stopper.OnStop(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(stopGracePeriod):
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,109 @@
+package di
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// eventLog records hook calls; OnStop runs on its own goroutine.
+type eventLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (e *eventLog) add(event string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = append(e.events, event)
+}
+
+func (e *eventLog) all() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string{}, e.events...)
+}
+
+type lifecycleComponent struct {
+	name   string
+	events *eventLog
+	block  bool
+}
+
+func (l *lifecycleComponent) OnStart(ctx context.Context) error {
+	l.events.add("start " + l.name)
+	return nil
+}
+
+func (l *lifecycleComponent) OnStop(ctx context.Context) error {
+	if l.block {
+		<-make(chan struct{})
+	}
+
+	l.events.add("stop " + l.name)
+	return nil
+}
+
+type IConnection interface{}
+type IConsumer interface{}
+
+func TestLifecycle_StartsInDependencyOrderAndStopsInReverse(t *testing.T) {
+	c := newTestContainer()
+	events := &eventLog{}
+
+	assert.NoError(t, c.Register(func() IConnection {
+		return &lifecycleComponent{name: "connection", events: events}
+	}))
+	assert.NoError(t, c.Register(func(conn IConnection) IConsumer {
+		return &lifecycleComponent{name: "consumer", events: events}
+	}))
+
+	_, err := c.GetByName("github.com/caiomarcatti12/nanogo/pkg/di/IConsumer")
+	assert.NoError(t, err)
+
+	assert.NoError(t, c.Start(context.Background()))
+	assert.NoError(t, c.Stop(context.Background()))
+
+	assert.Equal(t, []string{"start connection", "start consumer", "stop consumer", "stop connection"}, events.all())
+}
+
+func TestLifecycle_StartsSingletonsBuiltAfterStart(t *testing.T) {
+	c := newTestContainer()
+	events := &eventLog{}
+
+	assert.NoError(t, c.Register(func() IConnection {
+		return &lifecycleComponent{name: "connection", events: events}
+	}))
+	assert.NoError(t, c.Start(context.Background()))
+
+	_, err := c.GetByName("github.com/caiomarcatti12/nanogo/pkg/di/IConnection")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"start connection"}, events.all())
+}
+
+func TestLifecycle_StopTimeoutDoesNotSkipRemainingHooks(t *testing.T) {
+	c := newTestContainer()
+	events := &eventLog{}
+
+	assert.NoError(t, c.Register(func() IConnection {
+		return &lifecycleComponent{name: "connection", events: events}
+	}))
+	assert.NoError(t, c.Register(func(conn IConnection) IConsumer {
+		return &lifecycleComponent{name: "consumer", events: events, block: true}
+	}))
+
+	_, err := c.GetByName("github.com/caiomarcatti12/nanogo/pkg/di/IConsumer")
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err = c.Stop(ctx)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, events.all(), "stop connection")
+}
@@ -0,0 +1,41 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package di
+
+// Lifetime defines how long an instance built by the container lives.
+type Lifetime int
+
+const (
+	// Singleton instances are built once and shared by every resolution.
+	Singleton Lifetime = iota
+	// Transient instances are built again on every resolution. Those resolved
+	// through a scope are released with it; the others belong to the caller.
+	Transient
+	// Scoped instances are built once per scope (HTTP request, WebSocket
+	// message, queue delivery) and released when the scope is closed.
+	Scoped
+)
+
+func (l Lifetime) String() string {
+	switch l {
+	case Transient:
+		return "transient"
+	case Scoped:
+		return "scoped"
+	default:
+		return "singleton"
+	}
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package di
+
+import "reflect"
+
+// RegisterOption customizes a registration made through Register.
+type RegisterOption func(*registration)
+
+// WithLifetime sets the lifetime of the registered factory.
+func WithLifetime(lifetime Lifetime) RegisterOption {
+	return func(r *registration) {
+		r.lifetime = lifetime
+	}
+}
+
+// AsSingleton registers the factory as a singleton (default behaviour).
+func AsSingleton() RegisterOption {
+	return WithLifetime(Singleton)
+}
+
+// AsTransient registers the factory so a new instance is built on every resolution.
+func AsTransient() RegisterOption {
+	return WithLifetime(Transient)
+}
+
+// AsScoped registers the factory so one instance is built per scope.
+func AsScoped() RegisterOption {
+	return WithLifetime(Scoped)
+}
+
+// WithName registers the factory as a named implementation of its type. It
+// doesn't replace the default registration and is resolved with GetNamed or
+// through a `name:"..."` tagged field of a parameter struct embedding In.
+func WithName(name string) RegisterOption {
+	return func(r *registration) {
+		r.name = name
+	}
+}
+
+// Grouped registers the factory as one more implementation of its type without
+// replacing the default one. Factories receive every implementation by asking
+// for a []T parameter.
+func Grouped() RegisterOption {
+	return func(r *registration) {
+		r.grouped = true
+	}
+}
+
+// as registers the factory under t instead of the type it returns. Used by
+// Provide and Bind, where Register checks that the factory result is
+// assignable to t when the factory is registered.
+func as(t reflect.Type) RegisterOption {
+	return func(r *registration) {
+		r.as = t
+	}
+}
@@ -0,0 +1,202 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package di
+
+import (
+	"reflect"
+)
+
+// In marks a parameter struct whose exported fields are resolved one by one.
+// A field tagged with `name:"..."` receives the named registration of its type
+// and a slice field receives every implementation of the element type. A field
+// tagged with `optional:"true"` keeps its zero value when nothing is registered
+// for it instead of failing the resolution:
+//
+//	type RepositoryParams struct {
+//		di.In
+//		Primary db.IDatabase
+//		Replica db.IDatabase `name:"replica"`
+//		Metrics metric.IMetric `optional:"true"`
+//	}
+//
+//	func NewUserRepository(params RepositoryParams) IUserRepository
+type In struct{}
+
+var inType = reflect.TypeOf(In{})
+
+// isInStruct reports whether t is a struct embedding In.
+func isInStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		if field := t.Field(i); field.Anonymous && field.Type == inType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resolveParameter resolves a single factory parameter or In struct field.
+func (c *Container) resolveParameter(paramType reflect.Type, name string, scope *Scope) (reflect.Value, error) {
+	if isInStruct(paramType) {
+		return c.resolveInStruct(paramType, scope)
+	}
+
+	if name != "" {
+		return c.resolveValue(namedKey(typeName(paramType), name), scope)
+	}
+
+	if paramType.Kind() == reflect.Slice {
+		if _, exists := c.lookup(typeName(paramType)); !exists {
+			return c.resolveSlice(paramType, scope)
+		}
+	}
+
+	return c.resolveValue(typeName(paramType), scope)
+}
+
+func (c *Container) resolveValue(key string, scope *Scope) (reflect.Value, error) {
+	instance, err := c.resolve(key, scope)
+
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	return reflect.ValueOf(instance), nil
+}
+
+// resolveSlice fills a []T with every implementation registered for T.
+func (c *Container) resolveSlice(sliceType reflect.Type, scope *Scope) (reflect.Value, error) {
+	instances, err := c.resolveAll(typeName(sliceType.Elem()), scope)
+
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	slice := reflect.MakeSlice(sliceType, 0, len(instances))
+
+	for _, instance := range instances {
+		slice = reflect.Append(slice, reflect.ValueOf(instance))
+	}
+
+	return slice, nil
+}
+
+func (c *Container) resolveInStruct(structType reflect.Type, scope *Scope) (reflect.Value, error) {
+	value := reflect.New(structType).Elem()
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+
+		if field.Anonymous && field.Type == inType || !field.IsExported() {
+			continue
+		}
+
+		if isOptional(field) {
+			if _, exists := c.lookup(fieldKey(field)); !exists {
+				continue
+			}
+		}
+
+		fieldValue, err := c.resolveParameter(field.Type, field.Tag.Get("name"), scope)
+
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		value.Field(i).Set(fieldValue)
+	}
+
+	return value, nil
+}
+
+// isOptional reports whether an In struct field is tagged with `optional:"true"`.
+func isOptional(field reflect.StructField) bool {
+	return field.Tag.Get("optional") == "true"
+}
+
+// fieldKey returns the registration key an In struct field is resolved from.
+func fieldKey(field reflect.StructField) string {
+	if name := field.Tag.Get("name"); name != "" {
+		return namedKey(typeName(field.Type), name)
+	}
+
+	return typeName(field.Type)
+}
+
+// dependencies returns the registration keys reg depends on, including the
+// extra parameters of the decorators of its type. Must be called with c.mu held.
+func (c *Container) dependencies(reg *registration) []string {
+	dependencies := c.factoryDependencies(reg.factory)
+
+	for _, decorator := range c.decorators[reg.typeName] {
+		dependencies = append(dependencies, decorator.dependencies(c)...)
+	}
+
+	return dependencies
+}
+
+// factoryDependencies returns the registration keys a factory depends on.
+// Must be called with c.mu held.
+func (c *Container) factoryDependencies(factory interface{}) []string {
+	fnType := reflect.TypeOf(factory)
+	var dependencies []string
+
+	for i := 0; i < fnType.NumIn(); i++ {
+		dependencies = append(dependencies, c.parameterDependencies(fnType.In(i), "")...)
+	}
+
+	return dependencies
+}
+
+func (c *Container) parameterDependencies(paramType reflect.Type, name string) []string {
+	if isInStruct(paramType) {
+		var dependencies []string
+
+		for i := 0; i < paramType.NumField(); i++ {
+			field := paramType.Field(i)
+
+			if field.Anonymous && field.Type == inType || !field.IsExported() {
+				continue
+			}
+
+			if isOptional(field) {
+				if _, exists := c.constructors[fieldKey(field)]; !exists {
+					continue
+				}
+			}
+
+			dependencies = append(dependencies, c.parameterDependencies(field.Type, field.Tag.Get("name"))...)
+		}
+
+		return dependencies
+	}
+
+	if name != "" {
+		return []string{namedKey(typeName(paramType), name)}
+	}
+
+	if paramType.Kind() == reflect.Slice {
+		if _, exists := c.constructors[typeName(paramType)]; !exists {
+			return append([]string{}, c.implementations[typeName(paramType.Elem())]...)
+		}
+	}
+
+	return []string{typeName(paramType)}
+}
@@ -0,0 +1,186 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package di
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// IScope resolves instances bound to a unit of work such as an HTTP request,
+// a WebSocket message or a queue delivery.
+type IScope interface {
+	GetByFactory(factoryFunc interface{}) (interface{}, error)
+	GetByName(interfaceName string) (interface{}, error)
+	GetNamed(interfaceName string, name string) (interface{}, error)
+	GetGroup(interfaceName string) ([]interface{}, error)
+	Close()
+}
+
+// Scope caches scoped instances until it is closed. It is safe for concurrent use.
+type Scope struct {
+	mu        sync.Mutex
+	container *Container
+	instances map[string]interface{}
+	created   []interface{}
+	closed    bool
+}
+
+func newScope(container *Container) *Scope {
+	return &Scope{
+		container: container,
+		instances: make(map[string]interface{}),
+	}
+}
+
+// GetByFactory retrieves an instance by factory func inside the scope.
+func (s *Scope) GetByFactory(factoryFunc interface{}) (interface{}, error) {
+	cType, err := s.container.getNameInterface(factoryFunc)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByName(cType)
+}
+
+// GetByName retrieves an instance by type name inside the scope.
+func (s *Scope) GetByName(interfaceName string) (interface{}, error) {
+	if s.isClosed() {
+		return nil, errors.New(s.container.i18n.Get("di.scope_closed", map[string]interface{}{"factory": interfaceName}))
+	}
+
+	return s.container.resolve(interfaceName, s)
+}
+
+// GetNamed retrieves the named instance of the type inside the scope.
+func (s *Scope) GetNamed(interfaceName string, name string) (interface{}, error) {
+	return s.GetByName(namedKey(interfaceName, name))
+}
+
+// GetGroup retrieves every implementation of the type inside the scope.
+func (s *Scope) GetGroup(interfaceName string) ([]interface{}, error) {
+	if s.isClosed() {
+		return nil, errors.New(s.container.i18n.Get("di.scope_closed", map[string]interface{}{"factory": interfaceName}))
+	}
+
+	return s.container.resolveAll(interfaceName, s)
+}
+
+// Close releases the scoped instances and the transient ones resolved through
+// the scope, calling Close on those implementing io.Closer in the reverse order
+// they were built.
+func (s *Scope) Close() {
+	s.mu.Lock()
+
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+
+	s.closed = true
+	created := s.created
+	s.instances = nil
+	s.created = nil
+
+	s.mu.Unlock()
+
+	for i := len(created) - 1; i >= 0; i-- {
+		s.release(created[i])
+	}
+}
+
+// release calls Close on instances implementing io.Closer.
+func (s *Scope) release(instance interface{}) {
+	closer, ok := instance.(io.Closer)
+
+	if !ok {
+		return
+	}
+
+	if err := closer.Close(); err != nil {
+		s.container.log.Warning(s.container.i18n.Get("di.scope_close_error", map[string]interface{}{"error": err.Error()}))
+	}
+}
+
+func (s *Scope) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.closed
+}
+
+// getOrBuild returns the cached scoped instance or builds it. The build runs
+// without holding the lock because it may resolve other scoped dependencies;
+// if two goroutines race, the first stored instance wins and the other one is
+// released.
+func (s *Scope) getOrBuild(interfaceName string, build func() (interface{}, error)) (interface{}, error) {
+	s.mu.Lock()
+	instance, exists := s.instances[interfaceName]
+	s.mu.Unlock()
+
+	if exists {
+		return instance, nil
+	}
+
+	instance, err := build()
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+
+	if s.closed {
+		s.mu.Unlock()
+		s.release(instance)
+		return nil, errors.New(s.container.i18n.Get("di.scope_closed", map[string]interface{}{"factory": interfaceName}))
+	}
+
+	if existing, exists := s.instances[interfaceName]; exists {
+		s.mu.Unlock()
+		s.release(instance)
+		return existing, nil
+	}
+
+	s.instances[interfaceName] = instance
+	s.created = append(s.created, instance)
+	s.mu.Unlock()
+
+	return instance, nil
+}
+
+// track keeps a transient instance that implements io.Closer so Close releases
+// it with the scoped ones.
+func (s *Scope) track(interfaceName string, instance interface{}) (interface{}, error) {
+	if _, ok := instance.(io.Closer); !ok {
+		return instance, nil
+	}
+
+	s.mu.Lock()
+
+	if s.closed {
+		s.mu.Unlock()
+		s.release(instance)
+		return nil, errors.New(s.container.i18n.Get("di.scope_closed", map[string]interface{}{"factory": interfaceName}))
+	}
+
+	s.created = append(s.created, instance)
+	s.mu.Unlock()
+
+	return instance, nil
+}
@@ -0,0 +1,164 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package di
+
+import (
+	"sort"
+	"strings"
+)
+
+// ValidateOption customizes Validate.
+type ValidateOption func(*validateConfig)
+
+type validateConfig struct {
+	eager bool
+}
+
+// EagerSingletons makes Validate instantiate every singleton after the static
+// checks pass, surfacing factory errors at boot instead of on the first request.
+func EagerSingletons() ValidateOption {
+	return func(v *validateConfig) {
+		v.eager = true
+	}
+}
+
+// ValidationError lists every problem found by Validate.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return strings.Join(e.Problems, "\n")
+}
+
+// Validate walks every registered factory and reports all unresolved
+// dependencies, circular dependencies and singletons depending on scoped
+// registrations at once.
+func (c *Container) Validate(options ...ValidateOption) error {
+	config := &validateConfig{}
+
+	for _, option := range options {
+		option(config)
+	}
+
+	problems := c.staticProblems()
+
+	if len(problems) == 0 && config.eager {
+		for _, key := range c.singletonKeys() {
+			if _, err := c.resolve(key, nil); err != nil {
+				problems = append(problems, c.i18n.Get("di.validate_factory_failed", map[string]interface{}{"factory": key, "error": err.Error()}))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		c.log.Error(c.i18n.Get("di.validate_failed", map[string]interface{}{"total": len(problems)}))
+		return &ValidationError{Problems: problems}
+	}
+
+	return nil
+}
+
+func (c *Container) staticProblems() []string {
+	var problems []string
+	cycles := make(map[string]bool)
+
+	for _, key := range c.sortedKeys() {
+		c.mu.RLock()
+		reg := c.constructors[key]
+		dependencies := c.dependencies(reg)
+		c.mu.RUnlock()
+
+		for _, dependency := range dependencies {
+			if _, exists := c.lookup(dependency); !exists {
+				problems = append(problems, c.i18n.Get("di.validate_missing_dependency", map[string]interface{}{"factory": key, "dependency": dependency}))
+			}
+		}
+
+		if err := c.checkCycle(key, reg); err != nil && !cycles[err.Error()] {
+			cycles[err.Error()] = true
+			problems = append(problems, err.Error())
+		}
+
+		if reg.lifetime == Singleton {
+			if scoped := c.findScopedDependency(key, make(map[string]bool)); scoped != "" {
+				problems = append(problems, c.i18n.Get("di.validate_scoped_in_singleton", map[string]interface{}{"factory": key, "dependency": scoped}))
+			}
+		}
+	}
+
+	return problems
+}
+
+// findScopedDependency returns the first scoped registration reachable from
+// key without crossing another singleton.
+func (c *Container) findScopedDependency(key string, visited map[string]bool) string {
+	c.mu.RLock()
+	reg, exists := c.constructors[key]
+	var dependencies []string
+	if exists {
+		dependencies = c.dependencies(reg)
+	}
+	c.mu.RUnlock()
+
+	for _, dependency := range dependencies {
+		if visited[dependency] {
+			continue
+		}
+		visited[dependency] = true
+
+		depReg, exists := c.lookup(dependency)
+		if !exists {
+			continue
+		}
+
+		switch depReg.lifetime {
+		case Scoped:
+			return dependency
+		case Transient:
+			if scoped := c.findScopedDependency(dependency, visited); scoped != "" {
+				return scoped
+			}
+		}
+	}
+
+	return ""
+}
+
+func (c *Container) sortedKeys() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	keys := make([]string, 0, len(c.constructors))
+	for key := range c.constructors {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (c *Container) singletonKeys() []string {
+	var keys []string
+
+	for _, key := range c.sortedKeys() {
+		if reg, exists := c.lookup(key); exists && reg.lifetime == Singleton {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
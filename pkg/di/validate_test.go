@@ -0,0 +1,99 @@
+package di
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type IMissingA interface{}
+type IMissingB interface{}
+type IOrderService interface{}
+type IPaymentService interface{}
+
+func NewOrderService(a IMissingA, repo IRepository) IOrderService { return &repository{} }
+func NewPaymentService(b IMissingB) IPaymentService               { return &repository{} }
+
+func TestValidate_ReportsEveryMissingDependency(t *testing.T) {
+	c := newTestContainer()
+	assert.NoError(t, c.Register(NewRepository))
+	assert.NoError(t, c.Register(NewOrderService))
+	assert.NoError(t, c.Register(NewPaymentService))
+
+	err := c.Validate()
+
+	var validationError *ValidationError
+	if assert.ErrorAs(t, err, &validationError) {
+		assert.Len(t, validationError.Problems, 2)
+		assert.Contains(t, validationError.Problems[0], "dependency=github.com/caiomarcatti12/nanogo/pkg/di/IMissingA")
+		assert.Contains(t, validationError.Problems[1], "dependency=github.com/caiomarcatti12/nanogo/pkg/di/IMissingB")
+	}
+}
+
+func TestValidate_ReportsScopedDependencyOfSingleton(t *testing.T) {
+	c := newTestContainer()
+	assert.NoError(t, c.Register(NewRepository, AsScoped()))
+	assert.NoError(t, c.Register(NewService))
+
+	err := c.Validate()
+
+	assert.ErrorContains(t, err, "di.validate_scoped_in_singleton")
+}
+
+func TestValidate_EagerInstantiatesSingletons(t *testing.T) {
+	c := newTestContainer()
+	built := false
+	assert.NoError(t, c.Register(func() IRepository {
+		built = true
+		return &repository{}
+	}))
+
+	assert.NoError(t, c.Validate())
+	assert.False(t, built)
+
+	assert.NoError(t, c.Validate(EagerSingletons()))
+	assert.True(t, built)
+}
+
+func TestGraph_ExportsDotAndJson(t *testing.T) {
+	c := newTestContainer()
+	assert.NoError(t, c.Register(NewService))
+
+	graph := c.Graph()
+
+	service := "github.com/caiomarcatti12/nanogo/pkg/di/IService"
+	repo := "github.com/caiomarcatti12/nanogo/pkg/di/IRepository"
+
+	dot := graph.DOT()
+	assert.Contains(t, dot, `"`+service+`" -> "`+repo+`";`)
+	assert.Contains(t, dot, `"`+repo+`" [style=dashed, color=red];`)
+
+	content, err := graph.JSON()
+	assert.NoError(t, err)
+
+	var decoded Graph
+	assert.NoError(t, json.Unmarshal(content, &decoded))
+	if assert.Len(t, decoded.Nodes, 1) {
+		assert.Equal(t, "singleton", decoded.Nodes[0].Lifetime)
+		assert.Equal(t, []string{repo}, decoded.Nodes[0].Missing)
+	}
+}
+
+func TestGraph_DOTIsDeterministic(t *testing.T) {
+	c := newTestContainer()
+	assert.NoError(t, c.Register(NewOrderService))
+	assert.NoError(t, c.Register(NewPaymentService))
+	assert.NoError(t, c.Register(NewService))
+
+	first := c.Graph().DOT()
+
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, first, c.Graph().DOT())
+	}
+
+	missingA := strings.Index(first, `"github.com/caiomarcatti12/nanogo/pkg/di/IMissingA" [style=dashed`)
+	missingB := strings.Index(first, `"github.com/caiomarcatti12/nanogo/pkg/di/IMissingB" [style=dashed`)
+	assert.Less(t, missingA, missingB)
+}
@@ -15,10 +15,25 @@
  */
 package errors
 
+// CustomError é o erro retornado pelos handlers. Code é o status HTTP e
+// Message o detalhe exibido ao cliente. Type e Title alimentam os campos de
+// mesmo nome do RFC 7807; quando vazios, o webserver usa "about:blank" e o
+// texto padrão do status. Errors lista as falhas de validação por campo.
 type CustomError struct {
-	Code    int         `json:"code"`
-	Message string      `json:"error"`
-	Details interface{} `json:"details,omitempty"`
+	Code    int          `json:"code"`
+	Message string       `json:"error"`
+	Details interface{}  `json:"details,omitempty"`
+	Type    string       `json:"type,omitempty"`
+	Title   string       `json:"title,omitempty"`
+	Errors  []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError descreve a falha de uma regra de validação em um campo.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
 }
 
 func (e *CustomError) Error() string {
@@ -15,6 +15,8 @@
  */
 package event
 
+import "github.com/caiomarcatti12/nanogo/pkg/di"
+
 type Event struct {
 	Channel string
 	Key     string
@@ -26,4 +28,5 @@ type EventConsumer struct {
 	Key         string
 	IHandler    interface{}
 	HandlerFunc string
+	Lifetime    di.Lifetime
 }
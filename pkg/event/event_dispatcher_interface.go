@@ -16,6 +16,6 @@
 package event
 
 type IEventDispatcher interface {
-	RegisterConsumer(eventConsumer EventConsumer)
+	RegisterConsumer(eventConsumer EventConsumer) error
 	Dispatch(event Event)
 }
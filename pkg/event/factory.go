@@ -16,18 +16,30 @@
 package event
 
 import (
+	"github.com/caiomarcatti12/nanogo/pkg/di"
 	"github.com/caiomarcatti12/nanogo/pkg/env"
 	"github.com/caiomarcatti12/nanogo/pkg/i18n"
 	"github.com/caiomarcatti12/nanogo/pkg/log"
+	"github.com/caiomarcatti12/nanogo/pkg/metric"
 )
 
-func Factory(env env.IEnv, log log.ILog, i18n i18n.I18N) IEventDispatcher {
-	eventDispatcher := env.GetEnv("EVENT_DISPATCHER", "IN_MEMORY")
+// FactoryParams são as dependências de Factory. Metrics é opcional: sem o
+// MetricModule os panics dos consumidores são apenas registrados no log.
+type FactoryParams struct {
+	di.In
+	Env     env.IEnv
+	Log     log.ILog
+	I18N    i18n.I18N
+	Metrics metric.IMetric `optional:"true"`
+}
+
+func Factory(params FactoryParams) IEventDispatcher {
+	eventDispatcher := params.Env.GetEnv("EVENT_DISPATCHER", "IN_MEMORY")
 
 	switch eventDispatcher {
 	case "IN_MEMORY":
-		return NewInMemoryBroker(log, i18n)
+		return NewInMemoryBroker(params.Log, params.I18N, params.Metrics)
 	default:
-		panic(i18n.Get("event.provider_not_found", map[string]interface{}{"provider": eventDispatcher}))
+		panic(params.I18N.Get("event.provider_not_found", map[string]interface{}{"provider": eventDispatcher}))
 	}
 }
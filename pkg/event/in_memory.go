@@ -16,19 +16,25 @@
 package event
 
 import (
+	"errors"
 	"reflect"
 
+	"github.com/caiomarcatti12/nanogo/pkg/context_manager"
 	"github.com/caiomarcatti12/nanogo/pkg/di"
 	"github.com/caiomarcatti12/nanogo/pkg/i18n"
 	"github.com/caiomarcatti12/nanogo/pkg/log"
 	"github.com/caiomarcatti12/nanogo/pkg/mapper"
+	"github.com/caiomarcatti12/nanogo/pkg/metric"
+	"github.com/caiomarcatti12/nanogo/pkg/recovery"
 	"github.com/caiomarcatti12/nanogo/pkg/validator"
+	"github.com/google/uuid"
 )
 
 type InMemoryBroker struct {
-	handlers []EventConsumer // Mudança para um slice de EventHandler
-	logger   log.ILog
-	i18n     i18n.I18N
+	handlers  []EventConsumer // Mudança para um slice de EventHandler
+	logger    log.ILog
+	i18n      i18n.I18N
+	recoverer *recovery.Recoverer
 }
 
 type InMemoryConsumer struct {
@@ -37,73 +43,106 @@ type InMemoryConsumer struct {
 	i18n     i18n.I18N
 }
 
-func NewInMemoryBroker(logger log.ILog, i18n i18n.I18N) IEventDispatcher {
+// NewInMemoryBroker cria o broker em memória. metrics pode ser nil.
+func NewInMemoryBroker(logger log.ILog, i18n i18n.I18N, metrics metric.IMetric) IEventDispatcher {
 	return &InMemoryBroker{
-		handlers: []EventConsumer{},
-		logger:   logger,
-		i18n:     i18n,
+		handlers:  []EventConsumer{},
+		logger:    logger,
+		i18n:      i18n,
+		recoverer: recovery.NewRecoverer("event", logger, i18n, metrics),
 	}
 }
 
-func (i *InMemoryBroker) RegisterConsumer(eventConsumer EventConsumer) {
-	// Adiciona o handler ao slice para o eventKey específico
+// RegisterConsumer registra o handler no container com o Lifetime do
+// consumidor. Um handler já registrado é reaproveitado, desde que com o mesmo
+// ciclo de vida; caso contrário o consumidor é recusado em vez de trocar o
+// ciclo de vida de quem já o usa.
+func (i *InMemoryBroker) RegisterConsumer(eventConsumer EventConsumer) error {
+	container := di.GetInstance()
+
+	if lifetime, registered := container.Lifetime(eventConsumer.IHandler); registered {
+		if lifetime != eventConsumer.Lifetime {
+			return errors.New(i.i18n.Get("event.lifetime_conflict", map[string]interface{}{
+				"event":    eventConsumer.Channel + "." + eventConsumer.Key,
+				"lifetime": eventConsumer.Lifetime.String(),
+				"current":  lifetime.String(),
+			}))
+		}
+	} else if err := container.Register(eventConsumer.IHandler, di.WithLifetime(eventConsumer.Lifetime)); err != nil {
+		return err
+	}
 
 	i.handlers = append(i.handlers, eventConsumer)
+
+	return nil
 }
 
 func (i *InMemoryBroker) Dispatch(event Event) {
+	// Os consumidores rodam em outras goroutines; o correlation ID de quem
+	// disparou o evento é repassado para os logs deles.
+	fcm := context_manager.NewSafeContextManager()
+	correlationID, ok := fcm.GetValue("x-correlation-id")
+	if !ok || correlationID == nil {
+		correlationID = uuid.New().String()
+	}
+
 	for _, consumer := range i.handlers {
 		if consumer.Channel == event.Channel && consumer.Key == event.Key {
-			handler, err := di.GetInstance().GetByFactory(consumer.IHandler)
-
-			if err != nil {
-				return
-			}
-
-			handlerValue := reflect.ValueOf(handler)
-			// handlerType := handlerValue.Type()
-
-			// var structName string
-			// if handlerType.Kind() == reflect.Ptr {
-			// 	structName = handlerType.Elem().Name()
-			// } else {
-			// 	structName = handlerType.Name()
-			// }
+			go fcm.SetValues(fcm.CreateValue("x-correlation-id", correlationID), func() {
+				i.dispatchToConsumer(consumer, event)
+			})
+		}
+	}
+}
 
-			// span := ws.telemetry.StartChildSpan(structName + "::" + route.HandlerFunc)
-			// defer (func() { ws.telemetry.EndSpan(span, err) })()
+// dispatchToConsumer executa o handler dentro de um escopo próprio de DI. Um
+// panic no handler é registrado e não derruba o processo.
+func (i *InMemoryBroker) dispatchToConsumer(consumer EventConsumer, event Event) {
+	defer func() {
+		if value := recover(); value != nil {
+			i.recoverer.Recover(value)
+		}
+	}()
 
-			method := handlerValue.MethodByName(consumer.HandlerFunc)
+	scope := di.GetInstance().CreateScope()
+	defer scope.Close()
 
-			if !method.IsValid() {
-				// return nil, errors.InternalServerError(ws.i18n.Get("webserver.method_not_found", map[string]interface{}{"method": route.HandlerFunc, "path": route.Path}))
-			}
+	handler, err := scope.GetByFactory(consumer.IHandler)
 
-			methodType := method.Type()
-			numArgs := methodType.NumIn()
-			args := make([]reflect.Value, numArgs)
+	if err != nil {
+		i.logger.Error(err.Error())
+		return
+	}
 
-			for i := 0; i < numArgs; i++ {
-				paramType := methodType.In(i)
+	handlerValue := reflect.ValueOf(handler)
+	method := handlerValue.MethodByName(consumer.HandlerFunc)
 
-				ptrToStruct := reflect.New(paramType)
-				err := mapper.Deserialize(event.Data, ptrToStruct.Interface())
+	if !method.IsValid() {
+		i.logger.Error(i.i18n.Get("event.handler_not_found", map[string]interface{}{"event": event.Channel + "." + event.Key}))
+		return
+	}
 
-				if err != nil {
-					// return nil, errors.InternalServerError(ws.i18n.Get("webserver.error_injecting_data", map[string]interface{}{"error": err}))
-				}
+	methodType := method.Type()
+	numArgs := methodType.NumIn()
+	args := make([]reflect.Value, numArgs)
 
-				errorValidateStruct := validator.ValidateStruct(ptrToStruct.Interface())
+	for argIndex := 0; argIndex < numArgs; argIndex++ {
+		paramType := methodType.In(argIndex)
 
-				if errorValidateStruct != nil {
-					// return nil, errorValidateStruct
-				}
+		ptrToStruct := reflect.New(paramType)
 
-				args[i] = ptrToStruct.Elem()
-			}
+		if err := mapper.Deserialize(event.Data, ptrToStruct.Interface()); err != nil {
+			i.logger.Error(i.i18n.Get("event.invalid_payload", map[string]interface{}{"event": event.Channel + "." + event.Key, "error": err.Error()}))
+			return
+		}
 
-			go method.Call(args)
-			// results := method.Call(args)
+		if err := validator.ValidateStruct(ptrToStruct.Interface()); err != nil {
+			i.logger.Error(i.i18n.Get("event.invalid_payload", map[string]interface{}{"event": event.Channel + "." + event.Key, "error": err.Error()}))
+			return
 		}
+
+		args[argIndex] = ptrToStruct.Elem()
 	}
+
+	method.Call(args)
 }
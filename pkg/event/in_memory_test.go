@@ -0,0 +1,102 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package event
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/caiomarcatti12/nanogo/pkg/di"
+	"github.com/caiomarcatti12/nanogo/pkg/di/ditest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type UserCreated struct {
+	Email string `validate:"required"`
+}
+
+type IUserCreatedHandler interface {
+	Handler(event UserCreated)
+}
+
+type userCreatedHandler struct {
+	received *[]UserCreated
+}
+
+func (h *userCreatedHandler) Handler(event UserCreated) {
+	*h.received = append(*h.received, event)
+}
+
+type errorLog struct {
+	ditest.Logger
+	mu     sync.Mutex
+	errors []string
+}
+
+func (l *errorLog) Error(message string, _ ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errors = append(l.errors, message)
+}
+
+func newUserCreatedConsumer(received *[]UserCreated, lifetime di.Lifetime) EventConsumer {
+	return EventConsumer{
+		Channel:     "users",
+		Key:         "created",
+		IHandler:    func() IUserCreatedHandler { return &userCreatedHandler{received: received} },
+		HandlerFunc: "Handler",
+		Lifetime:    lifetime,
+	}
+}
+
+func TestRegisterConsumer_RejectsConflictingLifetime(t *testing.T) {
+	container := ditest.NewGlobal(t)
+	broker := NewInMemoryBroker(&errorLog{}, ditest.Translator{}, nil)
+	var received []UserCreated
+
+	require.NoError(t, broker.RegisterConsumer(newUserCreatedConsumer(&received, di.Scoped)))
+	// O mesmo handler com o mesmo ciclo de vida é reaproveitado.
+	require.NoError(t, broker.RegisterConsumer(newUserCreatedConsumer(&received, di.Scoped)))
+
+	err := broker.RegisterConsumer(newUserCreatedConsumer(&received, di.Singleton))
+
+	require.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "event.lifetime_conflict"))
+
+	lifetime, _ := container.Lifetime(func() IUserCreatedHandler { return nil })
+	assert.Equal(t, di.Scoped, lifetime)
+}
+
+func TestDispatchToConsumer_SkipsInvalidPayload(t *testing.T) {
+	ditest.NewGlobal(t)
+	logger := &errorLog{}
+	broker := NewInMemoryBroker(logger, ditest.Translator{}, nil).(*InMemoryBroker)
+	var received []UserCreated
+	consumer := newUserCreatedConsumer(&received, di.Transient)
+	require.NoError(t, broker.RegisterConsumer(consumer))
+
+	broker.dispatchToConsumer(consumer, Event{Channel: "users", Key: "created", Data: map[string]interface{}{}})
+
+	assert.Empty(t, received)
+	require.Len(t, logger.errors, 1)
+	assert.True(t, strings.HasPrefix(logger.errors[0], "event.invalid_payload"))
+
+	broker.dispatchToConsumer(consumer, Event{Channel: "users", Key: "created", Data: map[string]interface{}{"Email": "ana@example.com"}})
+
+	assert.Equal(t, []UserCreated{{Email: "ana@example.com"}}, received)
+}
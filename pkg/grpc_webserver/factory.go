@@ -1,6 +1,9 @@
 package grpc_webserver
 
 import (
+	"context"
+
+	"github.com/caiomarcatti12/nanogo/pkg/authz"
 	"github.com/caiomarcatti12/nanogo/pkg/di"
 	"github.com/caiomarcatti12/nanogo/pkg/env"
 	"github.com/caiomarcatti12/nanogo/pkg/log"
@@ -11,24 +14,33 @@ import (
 type IGrpcServer interface {
 	Add(handler GRPCHandler)
 	Start() error
+	Stop(ctx context.Context) error
 }
 
 // Factory cria uma instância do servidor gRPC com DI e logger injetados automaticamente.
-func Factory(logger log.ILog, env env.IEnv) IGrpcServer {
+func Factory(logger log.ILog, env env.IEnv, authorizer authz.IAuthorizer) IGrpcServer {
 	host := env.GetEnv("GRPC_HOST", "0.0.0.0")
 	port := env.GetEnv("GRPC_PORT", "50051")
 
-	interceptors := grpc.ChainUnaryInterceptor(
-		correlationIdInterceptor(),
-	)
+	server := &Server{
+		handlers:   []GRPCHandler{},
+		policies:   map[string]authz.Policy{},
+		authorizer: authorizer,
+		di:         di.GetInstance(),
+		logger:     logger.(log.ILog),
+		host:       host,
+		port:       port,
+	}
 
-	return &Server{
-		grpc: grpc.NewServer(interceptors),
+	server.grpc = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			correlationIdInterceptor(),
+			server.authorizationInterceptor(),
+		),
+		grpc.ChainStreamInterceptor(
+			server.streamAuthorizationInterceptor(),
+		),
+	)
 
-		handlers: []GRPCHandler{},
-		di:       di.GetInstance(),
-		logger:   logger.(log.ILog),
-		host:     host,
-		port:     port,
-	}
+	return server
 }
@@ -1,6 +1,13 @@
 package grpc_webserver
 
+import "github.com/caiomarcatti12/nanogo/pkg/authz"
+
 type GRPCHandler struct {
 	IHandler    interface{}
 	ServiceFunc string
+
+	// Policies declara os papéis e escopos exigidos por método, indexados pelo
+	// nome completo do método gRPC (ex.: "/users.UserService/DeleteUser").
+	// Métodos sem política não exigem autenticação.
+	Policies map[string]authz.Policy
 }
@@ -0,0 +1,133 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package grpc_webserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	nanogo_errors "github.com/caiomarcatti12/nanogo/pkg/errors"
+	"github.com/caiomarcatti12/nanogo/pkg/jwt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) authorizationInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		ctx, err := s.authorize(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func (s *Server) streamAuthorizationInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		ctx, err := s.authorize(stream.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &authorizedStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// authorize aplica a política do método, se houver. O token vem do metadata
+// "authorization" (Bearer) e é validado pelo jwt.IJWTManager; as claims
+// ficam disponíveis no contexto via jwt.FromContext.
+func (s *Server) authorize(ctx context.Context, method string) (context.Context, error) {
+	s.policiesMu.RLock()
+	policy, ok := s.policies[method]
+	s.policiesMu.RUnlock()
+
+	if !ok || policy.IsEmpty() {
+		return ctx, nil
+	}
+
+	claims, err := s.claims(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	if err := s.authorizer.Authorize(s.authorizer.Principal(claims), policy); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return jwt.NewContext(ctx, claims), nil
+}
+
+// claims retorna nil quando a chamada não traz token, deixando para o
+// authorizer a decisão de rejeitá-la como não autenticada.
+func (s *Server) claims(ctx context.Context) (jwt.Claims, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md["authorization"]) == 0 {
+		return nil, nil
+	}
+
+	token, found := strings.CutPrefix(md["authorization"][0], "Bearer ")
+	if !found || token == "" {
+		return nil, jwt.ErrTokenMissing
+	}
+
+	instance, err := s.di.GetByFactory(jwt.Factory)
+	if err != nil {
+		return nil, err
+	}
+
+	return instance.(jwt.IJWTManager).Validate(token)
+}
+
+func toStatus(err error) error {
+	var customError *nanogo_errors.CustomError
+	if !errors.As(err, &customError) {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	switch customError.Code {
+	case http.StatusUnauthorized:
+		return status.Error(codes.Unauthenticated, customError.Message)
+	case http.StatusForbidden:
+		return status.Error(codes.PermissionDenied, customError.Message)
+	default:
+		return status.Error(codes.Internal, customError.Message)
+	}
+}
+
+// authorizedStream substitui o contexto do stream pelo contexto com as claims.
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
@@ -16,23 +16,31 @@
 package grpc_webserver
 
 import (
+	"context"
 	"fmt"
+	"github.com/caiomarcatti12/nanogo/pkg/authz"
 	"github.com/caiomarcatti12/nanogo/pkg/di"
 	"github.com/caiomarcatti12/nanogo/pkg/log"
 	"net"
 	"reflect"
+	"sync"
+	"sync/atomic"
 
 	"google.golang.org/grpc"
 )
 
 // Server implementa IGrpcServer.
 type Server struct {
-	grpc     *grpc.Server
-	handlers []GRPCHandler
-	di       di.IContainer
-	logger   log.ILog
-	host     string
-	port     string
+	grpc       *grpc.Server
+	handlers   []GRPCHandler
+	policies   map[string]authz.Policy
+	policiesMu sync.RWMutex
+	authorizer authz.IAuthorizer
+	di         di.IContainer
+	logger     log.ILog
+	host       string
+	port       string
+	serving    atomic.Bool
 }
 
 func (s *Server) Add(handler GRPCHandler) {
@@ -41,6 +49,12 @@ func (s *Server) Add(handler GRPCHandler) {
 		s.logger.Errorf("Falha ao registrar o handler: %v", err)
 	}
 	s.handlers = append(s.handlers, handler)
+
+	s.policiesMu.Lock()
+	defer s.policiesMu.Unlock()
+	for method, policy := range handler.Policies {
+		s.policies[method] = policy
+	}
 }
 
 func (s *Server) Start() error {
@@ -67,5 +81,38 @@ func (s *Server) Start() error {
 	}
 
 	s.logger.Infof("Servidor gRPC iniciado com sucesso em %s", address)
+	s.serving.Store(true)
+	defer s.serving.Store(false)
+
 	return s.grpc.Serve(lis)
 }
+
+// HealthCheck falha enquanto o servidor não estiver aceitando conexões.
+func (s *Server) HealthCheck(ctx context.Context) error {
+	if !s.serving.Load() {
+		return fmt.Errorf("servidor gRPC não está em execução em %s:%s", s.host, s.port)
+	}
+
+	return nil
+}
+
+// Stop encerra o servidor aguardando as chamadas em andamento (GracefulStop).
+// Se o ctx expirar antes, as conexões restantes são fechadas imediatamente.
+func (s *Server) Stop(ctx context.Context) error {
+	s.serving.Store(false)
+
+	done := make(chan struct{})
+
+	go func() {
+		s.grpc.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.grpc.Stop()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package health
+
+import (
+	"time"
+
+	"github.com/caiomarcatti12/nanogo/pkg/di"
+	"github.com/caiomarcatti12/nanogo/pkg/env"
+	"github.com/caiomarcatti12/nanogo/pkg/i18n"
+	"github.com/caiomarcatti12/nanogo/pkg/log"
+	"github.com/caiomarcatti12/nanogo/pkg/metric"
+)
+
+// FactoryParams são as dependências de Factory. Metrics é opcional: sem o
+// MetricModule os resultados das verificações aparecem apenas nos endpoints
+// /healthz. Container resolve as verificações criadas por Component.
+type FactoryParams struct {
+	di.In
+	Env       env.IEnv
+	Log       log.ILog
+	I18N      i18n.I18N
+	Container di.IContainer
+	Metrics   metric.IMetric `optional:"true"`
+}
+
+// Factory cria o registro de verificações usado pelos endpoints /healthz. O
+// timeout padrão de cada verificação vem de HEALTH_CHECK_TIMEOUT.
+func Factory(params FactoryParams) IRegistry {
+	timeout, err := time.ParseDuration(params.Env.GetEnv("HEALTH_CHECK_TIMEOUT", "2s"))
+
+	if err != nil || timeout <= 0 {
+		params.Log.Warning(params.I18N.Get("health.invalid_timeout", map[string]interface{}{"variable": "HEALTH_CHECK_TIMEOUT", "default": "2s"}))
+		timeout = 2 * time.Second
+	}
+
+	return NewRegistry(timeout, params.Log, params.I18N, params.Metrics, params.Container)
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/caiomarcatti12/nanogo/pkg/di"
+	"github.com/caiomarcatti12/nanogo/pkg/di/ditest"
+	"github.com/caiomarcatti12/nanogo/pkg/env"
+	"github.com/caiomarcatti12/nanogo/pkg/i18n"
+	"github.com/caiomarcatti12/nanogo/pkg/log"
+	"github.com/caiomarcatti12/nanogo/pkg/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeEnv map[string]string
+
+func (e fakeEnv) GetEnv(variable string, defaultValue ...string) string {
+	if value, ok := e[variable]; ok {
+		return value
+	}
+
+	return defaultValue[0]
+}
+
+func (e fakeEnv) GetEnvBool(string, ...string) bool { return false }
+
+// newFactoryContainer registra as dependências obrigatórias de Factory em um
+// container que não é o global.
+func newFactoryContainer(t *testing.T, variables fakeEnv) di.IContainer {
+	container := ditest.New(t)
+
+	require.NoError(t, di.ProvideValue[env.IEnv](container, variables))
+	require.NoError(t, di.ProvideValue[log.ILog](container, ditest.Logger{}))
+	require.NoError(t, di.ProvideValue[i18n.I18N](container, ditest.Translator{}))
+	require.NoError(t, di.ProvideValue[di.IContainer](container, container))
+	require.NoError(t, container.Register(Factory))
+
+	return container
+}
+
+func TestFactory_WorksWithoutMetrics(t *testing.T) {
+	container := newFactoryContainer(t, fakeEnv{"HEALTH_CHECK_TIMEOUT": "5s"})
+
+	registry, err := di.Resolve[IRegistry](container)
+
+	require.NoError(t, err)
+	assert.Nil(t, registry.(*Registry).metrics)
+	assert.Same(t, container, registry.(*Registry).container)
+	assert.Equal(t, 5*time.Second, registry.(*Registry).timeout)
+}
+
+func TestFactory_UsesRegisteredMetrics(t *testing.T) {
+	container := newFactoryContainer(t, fakeEnv{})
+	metrics := &fakeMetric{gauges: make(map[string]float64)}
+	require.NoError(t, di.ProvideValue[metric.IMetric](container, metrics))
+
+	registry, err := di.Resolve[IRegistry](container)
+
+	require.NoError(t, err)
+	assert.Same(t, metrics, registry.(*Registry).metrics)
+	assert.Equal(t, 2*time.Second, registry.(*Registry).timeout)
+}
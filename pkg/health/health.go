@@ -0,0 +1,113 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package health
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/caiomarcatti12/nanogo/pkg/di"
+)
+
+// Status é o resultado de uma verificação ou de um relatório.
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+	// StatusDegraded indica que apenas verificações não críticas falharam. A
+	// aplicação continua recebendo tráfego.
+	StatusDegraded Status = "degraded"
+)
+
+// Check é uma verificação registrada no IRegistry. Toda verificação participa
+// do /healthz/readyz; as críticas também liberam o /healthz/startupz e, quando
+// falham, tiram a aplicação do ar.
+type Check struct {
+	Name  string
+	Check func(ctx context.Context) error
+	// Timeout limita cada execução. Zero usa HEALTH_CHECK_TIMEOUT.
+	Timeout  time.Duration
+	Critical bool
+	// Liveness inclui a verificação no /healthz/livez. Use apenas para falhas
+	// que só se resolvem reiniciando o processo, nunca para dependências externas.
+	Liveness bool
+	// Factory substitui Check nas verificações criadas por Component: o
+	// IRegistry obtém a instância do seu container e chama o HealthCheck.
+	Factory interface{}
+}
+
+// IChecker é implementado pelos componentes que sabem verificar a própria
+// conexão, como o MongoDB, o Redis, os providers de fila e o servidor gRPC.
+type IChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// Component cria uma verificação crítica que obtém, do container do IRegistry,
+// a instância da fábrica e chama seu HealthCheck. Como a instância só é criada
+// na primeira execução, a verificação também cobre a conexão inicial do
+// componente.
+func Component(name string, factory interface{}) Check {
+	return Check{
+		Name:     name,
+		Critical: true,
+		Factory:  factory,
+	}
+}
+
+func componentCheck(container di.IContainer, factory interface{}) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		instance, err := container.GetByFactory(factory)
+		if err != nil {
+			return err
+		}
+
+		checker, ok := instance.(IChecker)
+		if !ok {
+			return fmt.Errorf("%T does not implement health.IChecker", instance)
+		}
+
+		return checker.HealthCheck(ctx)
+	}
+}
+
+// Result é o resultado de uma verificação no relatório.
+type Result struct {
+	Name      string  `json:"name"`
+	Status    Status  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report é o corpo JSON devolvido pelos endpoints /healthz.
+type Report struct {
+	Status Status   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+// IRegistry guarda as verificações da aplicação e as executa para cada probe.
+type IRegistry interface {
+	Register(check Check) error
+	// Liveness executa as verificações marcadas com Liveness.
+	Liveness(ctx context.Context) Report
+	// Readiness executa todas as verificações.
+	Readiness(ctx context.Context) Report
+	// Startup executa as verificações críticas que ainda não passaram. Cada uma
+	// passa a contar como up depois do primeiro sucesso.
+	Startup(ctx context.Context) Report
+}
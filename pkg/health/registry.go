@@ -0,0 +1,285 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/caiomarcatti12/nanogo/pkg/di"
+	"github.com/caiomarcatti12/nanogo/pkg/i18n"
+	"github.com/caiomarcatti12/nanogo/pkg/log"
+	"github.com/caiomarcatti12/nanogo/pkg/metric"
+	"github.com/caiomarcatti12/nanogo/pkg/recovery"
+)
+
+const (
+	// CheckStatusMetric vale 1 quando a última execução da verificação passou e 0 quando falhou.
+	CheckStatusMetric = "health_check_status"
+	// CheckDurationMetric é a latência de cada execução, em segundos.
+	CheckDurationMetric = "health_check_duration_seconds"
+)
+
+type entry struct {
+	check Check
+	last  Result
+	// startup guarda o primeiro sucesso, que o /healthz/startupz continua reportando.
+	startup *Result
+}
+
+// Registry executa as verificações em paralelo, cada uma com seu timeout, e
+// guarda o último resultado de cada uma para registrar no log só as mudanças
+// de estado.
+type Registry struct {
+	mu        sync.Mutex
+	entries   []*entry
+	timeout   time.Duration
+	logger    log.ILog
+	i18n      i18n.I18N
+	metrics   metric.IMetric
+	container di.IContainer
+	recoverer *recovery.Recoverer
+}
+
+// NewRegistry cria o registro de verificações. timeout é usado nas verificações
+// sem Timeout próprio e metrics pode ser nil quando a aplicação não registra métricas.
+// container resolve as verificações de Component e pode ser nil quando não há nenhuma.
+func NewRegistry(timeout time.Duration, logger log.ILog, i18n i18n.I18N, metrics metric.IMetric, container di.IContainer) IRegistry {
+	if metrics != nil {
+		metrics.CreateMetric(metric.Gauge, CheckStatusMetric, "Indicates if the health check passed on its last run", metric.LabelsKeys{"check"})
+		metrics.CreateMetric(metric.Histogram, CheckDurationMetric, "Indicates health check duration in seconds", metric.LabelsKeys{"check"})
+	}
+
+	return &Registry{
+		timeout:   timeout,
+		logger:    logger,
+		i18n:      i18n,
+		metrics:   metrics,
+		container: container,
+		recoverer: recovery.NewRecoverer("health", logger, i18n, metrics),
+	}
+}
+
+func (r *Registry) Register(check Check) error {
+	if check.Check == nil && check.Factory != nil && r.container != nil {
+		check.Check = componentCheck(r.container, check.Factory)
+	}
+
+	if check.Name == "" || check.Check == nil {
+		return errors.New(r.i18n.Get("health.invalid_check", map[string]interface{}{"name": check.Name}))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, registered := range r.entries {
+		if registered.check.Name == check.Name {
+			return errors.New(r.i18n.Get("health.duplicate_check", map[string]interface{}{"name": check.Name}))
+		}
+	}
+
+	r.entries = append(r.entries, &entry{check: check})
+
+	return nil
+}
+
+func (r *Registry) Liveness(ctx context.Context) Report {
+	entries := r.filter(func(e *entry) bool { return e.check.Liveness })
+
+	return newReport(r.execute(ctx, entries))
+}
+
+func (r *Registry) Readiness(ctx context.Context) Report {
+	entries := r.filter(func(e *entry) bool { return true })
+
+	return newReport(r.execute(ctx, entries))
+}
+
+func (r *Registry) Startup(ctx context.Context) Report {
+	r.mu.Lock()
+	results := make([]Result, 0, len(r.entries))
+	var pending []*entry
+	var positions []int
+
+	for _, e := range r.entries {
+		if !e.check.Critical {
+			continue
+		}
+
+		if e.startup != nil {
+			results = append(results, *e.startup)
+			continue
+		}
+
+		pending = append(pending, e)
+		positions = append(positions, len(results))
+		results = append(results, Result{})
+	}
+	r.mu.Unlock()
+
+	for i, result := range r.execute(ctx, pending) {
+		results[positions[i]] = result
+	}
+
+	return newReport(results)
+}
+
+func (r *Registry) filter(match func(e *entry) bool) []*entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var entries []*entry
+
+	for _, e := range r.entries {
+		if match(e) {
+			entries = append(entries, e)
+		}
+	}
+
+	return entries
+}
+
+// execute roda as verificações em paralelo e devolve os resultados na ordem recebida.
+func (r *Registry) execute(ctx context.Context, entries []*entry) []Result {
+	results := make([]Result, len(entries))
+
+	var wg sync.WaitGroup
+
+	for i, e := range entries {
+		wg.Add(1)
+
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = r.run(ctx, check)
+		}(i, e.check)
+	}
+
+	wg.Wait()
+
+	for i, e := range entries {
+		r.record(e, results[i])
+	}
+
+	return results
+}
+
+// run executa uma verificação sem deixar que ela passe do timeout, mesmo que
+// ignore o ctx, e converte panics em falha.
+func (r *Registry) run(ctx context.Context, check Check) Result {
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = r.timeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+
+	go func() {
+		var err error
+
+		defer func() {
+			if value := recover(); value != nil {
+				err = r.recoverer.Recover(value)
+			}
+			done <- err
+		}()
+
+		err = check.Check(ctx)
+	}()
+
+	var err error
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = errors.New(r.i18n.Get("health.check_timeout", map[string]interface{}{"name": check.Name, "timeout": timeout.String()}))
+	}
+
+	result := Result{
+		Name:      check.Name,
+		Status:    StatusUp,
+		Critical:  check.Critical,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// record guarda o resultado, exporta as métricas e registra no log quando a
+// verificação muda de estado.
+func (r *Registry) record(e *entry, result Result) {
+	r.mu.Lock()
+	previous := e.last.Status
+	e.last = result
+	if result.Status == StatusUp && e.startup == nil {
+		e.startup = &result
+	}
+	r.mu.Unlock()
+
+	if r.metrics != nil {
+		value := 0.0
+		if result.Status == StatusUp {
+			value = 1
+		}
+
+		r.metrics.SetGauge(CheckStatusMetric, value, metric.Labels{"check": result.Name})
+		r.metrics.ObserveHistogram(CheckDurationMetric, result.LatencyMs/1000, metric.Labels{"check": result.Name})
+	}
+
+	if previous == result.Status {
+		return
+	}
+
+	if result.Status == StatusDown {
+		r.logger.Warning(r.i18n.Get("health.check_failed", map[string]interface{}{"name": result.Name, "error": result.Error}))
+	} else if previous == StatusDown {
+		r.logger.Info(r.i18n.Get("health.check_recovered", map[string]interface{}{"name": result.Name}))
+	}
+}
+
+// newReport resume os resultados: down se alguma verificação crítica falhou,
+// degraded se apenas as não críticas falharam.
+func newReport(results []Result) Report {
+	report := Report{Status: StatusUp, Checks: results}
+
+	if report.Checks == nil {
+		report.Checks = []Result{}
+	}
+
+	for _, result := range report.Checks {
+		if result.Status != StatusDown {
+			continue
+		}
+
+		if result.Critical {
+			report.Status = StatusDown
+		} else if report.Status == StatusUp {
+			report.Status = StatusDegraded
+		}
+	}
+
+	return report
+}
@@ -0,0 +1,278 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/caiomarcatti12/nanogo/pkg/di/ditest"
+	"github.com/caiomarcatti12/nanogo/pkg/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingLog struct {
+	ditest.Logger
+	mu       sync.Mutex
+	warnings []string
+}
+
+func (l *recordingLog) Warning(message string, _ ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.warnings = append(l.warnings, message)
+}
+
+type fakeMetric struct {
+	mu     sync.Mutex
+	gauges map[string]float64
+	times  int
+}
+
+func (m *fakeMetric) CreateMetric(metric.MetricType, string, string, metric.LabelsKeys) {}
+func (m *fakeMetric) IncrementCounter(string, metric.Labels) error                      { return nil }
+func (m *fakeMetric) SetGauge(name string, value float64, labels metric.Labels) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gauges[name+"/"+labels["check"]] = value
+	return nil
+}
+func (m *fakeMetric) ObserveHistogram(string, float64, metric.Labels) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.times++
+	return nil
+}
+func (m *fakeMetric) ObserveSummary(string, float64, metric.Labels) error { return nil }
+
+func newTestRegistry(metrics metric.IMetric) (*Registry, *recordingLog) {
+	logger := &recordingLog{}
+	return NewRegistry(time.Second, logger, ditest.Translator{}, metrics, nil).(*Registry), logger
+}
+
+func passing(context.Context) error { return nil }
+
+func failing(context.Context) error { return errors.New("connection refused") }
+
+func TestRegister_RejectsInvalidAndDuplicateChecks(t *testing.T) {
+	registry, _ := newTestRegistry(nil)
+
+	assert.Error(t, registry.Register(Check{Name: "", Check: passing}))
+	assert.Error(t, registry.Register(Check{Name: "db"}))
+	require.NoError(t, registry.Register(Check{Name: "db", Check: passing}))
+	assert.Error(t, registry.Register(Check{Name: "db", Check: passing}))
+}
+
+func TestReadiness_AggregatesCriticality(t *testing.T) {
+	registry, _ := newTestRegistry(nil)
+	require.NoError(t, registry.Register(Check{Name: "db", Check: passing, Critical: true}))
+	require.NoError(t, registry.Register(Check{Name: "cache", Check: failing}))
+
+	report := registry.Readiness(context.Background())
+
+	assert.Equal(t, StatusDegraded, report.Status)
+	require.Len(t, report.Checks, 2)
+	assert.Equal(t, "db", report.Checks[0].Name)
+	assert.Equal(t, StatusUp, report.Checks[0].Status)
+	assert.Equal(t, StatusDown, report.Checks[1].Status)
+	assert.Equal(t, "connection refused", report.Checks[1].Error)
+
+	require.NoError(t, registry.Register(Check{Name: "queue", Check: failing, Critical: true}))
+
+	assert.Equal(t, StatusDown, registry.Readiness(context.Background()).Status)
+}
+
+func TestReadiness_WithoutChecksIsUp(t *testing.T) {
+	registry, _ := newTestRegistry(nil)
+
+	report := registry.Readiness(context.Background())
+
+	assert.Equal(t, StatusUp, report.Status)
+	assert.NotNil(t, report.Checks)
+}
+
+func TestRun_TimesOutChecksThatIgnoreContext(t *testing.T) {
+	registry, _ := newTestRegistry(nil)
+	release := make(chan struct{})
+	defer close(release)
+
+	require.NoError(t, registry.Register(Check{
+		Name:     "slow",
+		Critical: true,
+		Timeout:  20 * time.Millisecond,
+		Check: func(context.Context) error {
+			<-release
+			return nil
+		},
+	}))
+
+	start := time.Now()
+	report := registry.Readiness(context.Background())
+
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.Equal(t, StatusDown, report.Status)
+	assert.True(t, strings.HasPrefix(report.Checks[0].Error, "health.check_timeout"))
+	assert.GreaterOrEqual(t, report.Checks[0].LatencyMs, float64(20))
+}
+
+func TestRun_RecoversPanics(t *testing.T) {
+	registry, _ := newTestRegistry(nil)
+	require.NoError(t, registry.Register(Check{
+		Name:     "broken",
+		Critical: true,
+		Check:    func(context.Context) error { panic("nil pointer") },
+	}))
+
+	report := registry.Readiness(context.Background())
+
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Contains(t, report.Checks[0].Error, "nil pointer")
+}
+
+func TestStartup_GatesUntilCriticalChecksPassOnce(t *testing.T) {
+	registry, _ := newTestRegistry(nil)
+	var calls int
+	connected := false
+
+	require.NoError(t, registry.Register(Check{
+		Name:     "db",
+		Critical: true,
+		Check: func(context.Context) error {
+			calls++
+			if !connected {
+				return errors.New("connecting")
+			}
+			return nil
+		},
+	}))
+	require.NoError(t, registry.Register(Check{Name: "cache", Check: failing}))
+
+	report := registry.Startup(context.Background())
+	assert.Equal(t, StatusDown, report.Status)
+	require.Len(t, report.Checks, 1)
+
+	connected = true
+	assert.Equal(t, StatusUp, registry.Startup(context.Background()).Status)
+
+	// Depois do primeiro sucesso, a verificação não é mais executada no startup.
+	connected = false
+	report = registry.Startup(context.Background())
+	assert.Equal(t, StatusUp, report.Status)
+	assert.Equal(t, 2, calls)
+
+	assert.Equal(t, StatusDown, registry.Readiness(context.Background()).Status)
+}
+
+func TestLiveness_RunsOnlyLivenessChecks(t *testing.T) {
+	registry, _ := newTestRegistry(nil)
+	require.NoError(t, registry.Register(Check{Name: "db", Check: failing, Critical: true}))
+	require.NoError(t, registry.Register(Check{Name: "deadlock", Check: passing, Critical: true, Liveness: true}))
+
+	report := registry.Liveness(context.Background())
+
+	assert.Equal(t, StatusUp, report.Status)
+	require.Len(t, report.Checks, 1)
+	assert.Equal(t, "deadlock", report.Checks[0].Name)
+}
+
+func TestRecord_ExportsMetricsAndLogsTransitions(t *testing.T) {
+	metrics := &fakeMetric{gauges: map[string]float64{}}
+	registry, logger := newTestRegistry(metrics)
+	healthy := true
+
+	require.NoError(t, registry.Register(Check{
+		Name:     "db",
+		Critical: true,
+		Check: func(context.Context) error {
+			if healthy {
+				return nil
+			}
+			return fmt.Errorf("down")
+		},
+	}))
+
+	registry.Readiness(context.Background())
+	assert.Equal(t, float64(1), metrics.gauges[CheckStatusMetric+"/db"])
+
+	healthy = false
+	registry.Readiness(context.Background())
+	registry.Readiness(context.Background())
+	assert.Equal(t, float64(0), metrics.gauges[CheckStatusMetric+"/db"])
+	assert.Equal(t, 3, metrics.times)
+
+	failures := 0
+	for _, warning := range logger.warnings {
+		if strings.HasPrefix(warning, "health.check_failed") {
+			failures++
+		}
+	}
+	assert.Equal(t, 1, failures)
+}
+
+type IConnection interface {
+	Connected() bool
+}
+
+type connection struct {
+	err error
+}
+
+func (c *connection) Connected() bool { return c.err == nil }
+
+func (c *connection) HealthCheck(context.Context) error { return c.err }
+
+func TestComponent_ResolvesFactoryOnFirstRun(t *testing.T) {
+	container := ditest.New(t)
+	built := 0
+	factory := func() IConnection {
+		built++
+		return &connection{err: errors.New("refused")}
+	}
+	require.NoError(t, container.Register(factory))
+
+	registry := NewRegistry(time.Second, &recordingLog{}, ditest.Translator{}, nil, container)
+	check := Component("db", factory)
+	require.NoError(t, registry.Register(check))
+
+	assert.True(t, check.Critical)
+	assert.Equal(t, 0, built)
+
+	report := registry.Readiness(context.Background())
+	require.Len(t, report.Checks, 1)
+	assert.Equal(t, "refused", report.Checks[0].Error)
+	assert.Equal(t, 1, built)
+}
+
+func TestComponent_FailsWhenFactoryIsNotRegistered(t *testing.T) {
+	registry := NewRegistry(time.Second, &recordingLog{}, ditest.Translator{}, nil, ditest.New(t))
+	require.NoError(t, registry.Register(Component("db", func() IConnection { return &connection{} })))
+
+	report := registry.Readiness(context.Background())
+	assert.Equal(t, StatusDown, report.Status)
+	assert.NotEmpty(t, report.Checks[0].Error)
+}
+
+func TestComponent_RequiresContainer(t *testing.T) {
+	registry, _ := newTestRegistry(nil)
+
+	assert.Error(t, registry.Register(Component("db", func() IConnection { return &connection{} })))
+}
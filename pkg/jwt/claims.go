@@ -0,0 +1,90 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package jwt
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Claims são as claims de um token. Números chegam como float64, como no
+// encoding/json.
+type Claims map[string]interface{}
+
+func (c Claims) Subject() string {
+	subject, _ := c["sub"].(string)
+	return subject
+}
+
+func (c Claims) Issuer() string {
+	issuer, _ := c["iss"].(string)
+	return issuer
+}
+
+// Audience aceita as duas formas da RFC 7519: string única ou lista.
+func (c Claims) Audience() []string {
+	switch audience := c["aud"].(type) {
+	case string:
+		return []string{audience}
+	case []string:
+		return audience
+	case []interface{}:
+		result := make([]string, 0, len(audience))
+		for _, value := range audience {
+			if text, ok := value.(string); ok {
+				result = append(result, text)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+func (c Claims) ExpiresAt() (time.Time, bool) {
+	return c.time("exp")
+}
+
+func (c Claims) NotBefore() (time.Time, bool) {
+	return c.time("nbf")
+}
+
+func (c Claims) IssuedAt() (time.Time, bool) {
+	return c.time("iat")
+}
+
+func (c Claims) time(name string) (time.Time, bool) {
+	var seconds float64
+
+	switch value := c[name].(type) {
+	case float64:
+		seconds = value
+	case int64:
+		seconds = float64(value)
+	case int:
+		seconds = float64(value)
+	case json.Number:
+		parsed, err := value.Float64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		seconds = parsed
+	default:
+		return time.Time{}, false
+	}
+
+	return time.Unix(0, int64(seconds*float64(time.Second))), true
+}
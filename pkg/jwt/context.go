@@ -0,0 +1,155 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package jwt
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+type contextKey struct{}
+
+var (
+	claimsType = reflect.TypeOf(Claims{})
+	timeType   = reflect.TypeOf(time.Time{})
+)
+
+// NewContext retorna um contexto com as claims do token autenticado.
+func NewContext(ctx context.Context, claims Claims) context.Context {
+	return context.WithValue(ctx, contextKey{}, claims)
+}
+
+// FromContext retorna as claims gravadas pelo middleware de autenticação.
+func FromContext(ctx context.Context) (Claims, bool) {
+	claims, ok := ctx.Value(contextKey{}).(Claims)
+	return claims, ok
+}
+
+// Bind preenche os campos da struct com a tag claim (`claim:"sub"`) e os
+// campos do tipo Claims com todas as claims. Os campos de claims ausentes são
+// zerados, para que nunca mantenham valores vindos de outra fonte, como o
+// corpo da requisição.
+func Bind(claims Claims, target interface{}) error {
+	value := reflect.ValueOf(target)
+
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return ErrBindTargetNotAPointer
+	}
+
+	return bindStruct(claims, value.Elem())
+}
+
+func bindStruct(claims Claims, value reflect.Value) error {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		fieldValue := value.Field(i)
+		name, tagged := field.Tag.Lookup("claim")
+
+		switch {
+		case tagged:
+			fieldValue.Set(reflect.Zero(field.Type))
+
+			if claim, ok := claims[name]; ok && claim != nil {
+				if err := assign(fieldValue, claim); err != nil {
+					return err
+				}
+			}
+		case field.Type == claimsType:
+			fieldValue.Set(reflect.ValueOf(claims).Convert(claimsType))
+		case field.Anonymous && field.Type.Kind() == reflect.Struct:
+			if err := bindStruct(claims, fieldValue); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// assign converte o valor vindo do JSON (string, float64, bool, []interface{},
+// map) para o tipo do campo.
+func assign(field reflect.Value, claim interface{}) error {
+	if field.Kind() == reflect.Ptr {
+		target := reflect.New(field.Type().Elem())
+		if err := assign(target.Elem(), claim); err != nil {
+			return err
+		}
+		field.Set(target)
+		return nil
+	}
+
+	value := reflect.ValueOf(claim)
+
+	if field.Type() == timeType {
+		seconds, ok := claim.(float64)
+		if !ok {
+			return ErrClaimTypeMismatch
+		}
+		field.Set(reflect.ValueOf(time.Unix(0, int64(seconds*float64(time.Second)))))
+		return nil
+	}
+
+	if field.Kind() == reflect.Slice && value.Kind() == reflect.Slice && !value.Type().AssignableTo(field.Type()) {
+		slice := reflect.MakeSlice(field.Type(), value.Len(), value.Len())
+		for i := 0; i < value.Len(); i++ {
+			if err := assign(slice.Index(i), value.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+
+	// Audiência e papéis podem vir como string única no lugar de uma lista.
+	if field.Kind() == reflect.Slice && value.Kind() == reflect.String {
+		slice := reflect.MakeSlice(field.Type(), 1, 1)
+		if err := assign(slice.Index(0), claim); err != nil {
+			return err
+		}
+		field.Set(slice)
+		return nil
+	}
+
+	switch {
+	case value.Type().AssignableTo(field.Type()):
+		field.Set(value)
+	case value.Kind() == reflect.Float64 && isNumber(field.Kind()):
+		field.Set(value.Convert(field.Type()))
+	case value.Kind() == field.Kind() && value.Type().ConvertibleTo(field.Type()):
+		field.Set(value.Convert(field.Type()))
+	default:
+		return ErrClaimTypeMismatch
+	}
+
+	return nil
+}
+
+func isNumber(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
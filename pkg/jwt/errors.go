@@ -0,0 +1,35 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package jwt
+
+import "errors"
+
+var (
+	ErrTokenMissing          = errors.New("jwt: token is missing")
+	ErrTokenMalformed        = errors.New("jwt: token is malformed")
+	ErrUnsupportedAlgorithm  = errors.New("jwt: unsupported signing algorithm")
+	ErrUnknownKey            = errors.New("jwt: no key found for the token")
+	ErrSignatureInvalid      = errors.New("jwt: signature is invalid")
+	ErrTokenExpired          = errors.New("jwt: token is expired")
+	ErrTokenNotValidYet      = errors.New("jwt: token is not valid yet")
+	ErrInvalidIssuer         = errors.New("jwt: token issuer is not accepted")
+	ErrInvalidAudience       = errors.New("jwt: token audience is not accepted")
+	ErrNoSigningKey          = errors.New("jwt: no signing key configured")
+	ErrNoKeys                = errors.New("jwt: no signing or verification key configured")
+	ErrInvalidKey            = errors.New("jwt: key is invalid for the algorithm")
+	ErrClaimTypeMismatch     = errors.New("jwt: claim cannot be assigned to the field")
+	ErrBindTargetNotAPointer = errors.New("jwt: bind target must be a pointer to a struct")
+)
@@ -13,28 +13,18 @@
  * See the License for the specific language governing permissions and
  * limitations under the License.
  */
-package webserver_route
+package jwt
 
-import (
-	"net/http"
-
-	"github.com/caiomarcatti12/nanogo/pkg/types"
-)
-
-type IHealthCheckController interface {
-	Handler() (interface{}, error)
-}
-
-type HealthCheckController struct {
+// JWKSController publica as chaves públicas de verificação. Veja
+// nanogo.JWKSModule para registrar a rota /.well-known/jwks.json.
+type JWKSController struct {
+	manager IJWTManager
 }
 
-func NewHealthCheckController() IHealthCheckController {
-	return &HealthCheckController{}
+func NewJWKSController(manager IJWTManager) *JWKSController {
+	return &JWKSController{manager: manager}
 }
 
-func (hc *HealthCheckController) Handler() (interface{}, error) {
-	return types.Response{
-		StatusCode: http.StatusOK,
-		Headers:    map[string]string{"Content-Type": "text/plain; charset=utf-8"},
-	}, nil
+func (c *JWKSController) Handler() (KeySet, error) {
+	return c.manager.JWKS(), nil
 }
@@ -15,6 +15,64 @@
  */
 package jwt
 
-type JWTManager struct {
-	signingKey []byte
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/caiomarcatti12/nanogo/pkg/env"
+	"github.com/caiomarcatti12/nanogo/pkg/i18n"
+	"github.com/caiomarcatti12/nanogo/pkg/log"
+)
+
+type IJWTManager interface {
+	Sign(claims Claims) (string, error)
+	Validate(token string) (Claims, error)
+	JWKS() KeySet
+	Reload() error
+}
+
+// Factory cria o JWTManager a partir das variáveis JWT_*.
+func Factory(env env.IEnv, logger log.ILog, i18n i18n.I18N) (IJWTManager, error) {
+	config := Config{
+		Algorithm: env.GetEnv("JWT_ALGORITHM", HS256),
+		KeyID:     env.GetEnv("JWT_KEY_ID", ""),
+		Secret:    []byte(env.GetEnv("JWT_SECRET", "")),
+		JWKSFile:  env.GetEnv("JWT_JWKS_FILE", ""),
+		Issuer:    env.GetEnv("JWT_ISSUER", ""),
+	}
+
+	if audience := env.GetEnv("JWT_AUDIENCE", ""); audience != "" {
+		config.Audience = strings.Split(audience, ",")
+	}
+
+	var err error
+
+	if config.Expiration, err = time.ParseDuration(env.GetEnv("JWT_EXPIRATION", "1h")); err != nil {
+		return nil, configurationError(i18n, "JWT_EXPIRATION", err)
+	}
+
+	if config.Leeway, err = time.ParseDuration(env.GetEnv("JWT_LEEWAY", "0s")); err != nil {
+		return nil, configurationError(i18n, "JWT_LEEWAY", err)
+	}
+
+	if path := env.GetEnv("JWT_PRIVATE_KEY_FILE", ""); path != "" {
+		if config.PrivateKey, err = os.ReadFile(path); err != nil {
+			return nil, configurationError(i18n, "JWT_PRIVATE_KEY_FILE", err)
+		}
+	}
+
+	manager, err := NewJWTManager(config)
+	if err != nil {
+		return nil, configurationError(i18n, "JWT_*", err)
+	}
+
+	logger.Debug(i18n.Get("jwt.manager_created", map[string]interface{}{"algorithm": config.Algorithm}))
+
+	return manager, nil
+}
+
+func configurationError(i18n i18n.I18N, variable string, err error) error {
+	return errors.New(i18n.Get("jwt.invalid_configuration", map[string]interface{}{"variable": variable, "error": err.Error()}))
 }
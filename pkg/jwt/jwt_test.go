@@ -0,0 +1,215 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rsaPEM(t *testing.T) []byte {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(private)})
+}
+
+func ecPEM(t *testing.T) []byte {
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func TestSignAndValidate_AllAlgorithms(t *testing.T) {
+	configs := map[string]Config{
+		HS256: {Algorithm: HS256, Secret: []byte("secret")},
+		RS256: {Algorithm: RS256, PrivateKey: rsaPEM(t), KeyID: "rsa-1"},
+		ES256: {Algorithm: ES256, PrivateKey: ecPEM(t), KeyID: "ec-1"},
+	}
+
+	for algorithm, config := range configs {
+		t.Run(algorithm, func(t *testing.T) {
+			config.Expiration = time.Hour
+
+			manager, err := NewJWTManager(config)
+			require.NoError(t, err)
+
+			token, err := manager.Sign(Claims{"sub": "user-1", "roles": []string{"admin"}})
+			require.NoError(t, err)
+
+			claims, err := manager.Validate(token)
+			require.NoError(t, err)
+			assert.Equal(t, "user-1", claims.Subject())
+
+			expiresAt, ok := claims.ExpiresAt()
+			assert.True(t, ok)
+			assert.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, time.Minute)
+
+			tampered := token[:len(token)-4] + "AAAA"
+			_, err = manager.Validate(tampered)
+			assert.ErrorIs(t, err, ErrSignatureInvalid)
+		})
+	}
+}
+
+func TestValidate_ChecksTimeIssuerAndAudience(t *testing.T) {
+	manager, err := NewJWTManager(Config{
+		Algorithm: HS256,
+		Secret:    []byte("secret"),
+		Issuer:    "nanogo",
+		Audience:  []string{"api"},
+		Leeway:    time.Minute,
+	})
+	require.NoError(t, err)
+
+	now := time.Now()
+	sign := func(claims Claims) string {
+		token, err := manager.Sign(claims)
+		require.NoError(t, err)
+		return token
+	}
+
+	_, err = manager.Validate(sign(Claims{"exp": now.Add(-2 * time.Minute).Unix()}))
+	assert.ErrorIs(t, err, ErrTokenExpired)
+
+	_, err = manager.Validate(sign(Claims{"exp": now.Add(-30 * time.Second).Unix()}))
+	assert.NoError(t, err, "expired within the leeway")
+
+	_, err = manager.Validate(sign(Claims{"nbf": now.Add(2 * time.Minute).Unix()}))
+	assert.ErrorIs(t, err, ErrTokenNotValidYet)
+
+	_, err = manager.Validate(sign(Claims{"iss": "other"}))
+	assert.ErrorIs(t, err, ErrInvalidIssuer)
+
+	_, err = manager.Validate(sign(Claims{"aud": []string{"web", "api"}}))
+	assert.NoError(t, err)
+
+	_, err = manager.Validate(sign(Claims{"aud": "web"}))
+	assert.ErrorIs(t, err, ErrInvalidAudience)
+}
+
+func TestValidate_RejectsAlgorithmMismatchAndNone(t *testing.T) {
+	manager, err := NewJWTManager(Config{Algorithm: RS256, PrivateKey: rsaPEM(t)})
+	require.NoError(t, err)
+
+	hmac, err := NewJWTManager(Config{Algorithm: HS256, Secret: []byte("secret")})
+	require.NoError(t, err)
+
+	token, err := hmac.Sign(Claims{"sub": "user-1"})
+	require.NoError(t, err)
+
+	_, err = manager.Validate(token)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	parts := strings.Split(token, ".")
+	none := encode([]byte(`{"alg":"none"}`)) + "." + parts[1] + "."
+
+	_, err = manager.Validate(none)
+	assert.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+
+	_, err = manager.Validate("not-a-token")
+	assert.ErrorIs(t, err, ErrTokenMalformed)
+}
+
+func TestJWKS_PublishesPublicKeysAndRotatesFromFile(t *testing.T) {
+	issuer, err := NewJWTManager(Config{Algorithm: ES256, PrivateKey: ecPEM(t), KeyID: "key-2"})
+	require.NoError(t, err)
+
+	set := issuer.JWKS()
+	require.Len(t, set.Keys, 1)
+	assert.Equal(t, "key-2", set.Keys[0].Kid)
+	assert.Equal(t, "EC", set.Keys[0].Kty)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"keys":[]}`), 0o600))
+
+	verifier, err := NewJWTManager(Config{Algorithm: RS256, JWKSFile: path})
+	assert.ErrorIs(t, err, ErrNoKeys)
+	assert.Nil(t, verifier)
+
+	other, err := NewJWTManager(Config{Algorithm: RS256, PrivateKey: rsaPEM(t), KeyID: "key-1"})
+	require.NoError(t, err)
+
+	data, _ := json.Marshal(other.JWKS())
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	verifier, err = NewJWTManager(Config{Algorithm: RS256, JWKSFile: path})
+	require.NoError(t, err)
+
+	_, err = verifier.Sign(Claims{})
+	assert.ErrorIs(t, err, ErrNoSigningKey)
+
+	token, err := issuer.Sign(Claims{"sub": "user-1"})
+	require.NoError(t, err)
+
+	_, err = verifier.Validate(token)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	// Publica a nova chave; o kid desconhecido força a releitura do arquivo.
+	rotated := KeySet{Keys: append(other.JWKS().Keys, issuer.JWKS().Keys...)}
+	data, _ = json.Marshal(rotated)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	verifier.now = func() time.Time { return time.Now().Add(reloadInterval) }
+
+	claims, err := verifier.Validate(token)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.Subject())
+}
+
+type profile struct {
+	UserID   string    `claim:"sub"`
+	Roles    []string  `claim:"roles"`
+	Level    int       `claim:"level"`
+	Expires  time.Time `claim:"exp"`
+	TenantID *string   `claim:"tenant"`
+	All      Claims
+	Name     string
+}
+
+func TestBind_FillsTaggedFieldsAndClearsMissingClaims(t *testing.T) {
+	target := profile{UserID: "from-body", Name: "kept"}
+	claims := Claims{"roles": []interface{}{"admin"}, "level": 3.0, "exp": 1700000000.0, "tenant": "acme"}
+
+	require.NoError(t, Bind(claims, &target))
+
+	assert.Equal(t, "", target.UserID)
+	assert.Equal(t, []string{"admin"}, target.Roles)
+	assert.Equal(t, 3, target.Level)
+	assert.Equal(t, time.Unix(1700000000, 0), target.Expires)
+	assert.Equal(t, "acme", *target.TenantID)
+	assert.Equal(t, claims, target.All)
+	assert.Equal(t, "kept", target.Name)
+
+	assert.ErrorIs(t, Bind(Claims{"level": "high"}, &target), ErrClaimTypeMismatch)
+	assert.ErrorIs(t, Bind(claims, target), ErrBindTargetNotAPointer)
+}
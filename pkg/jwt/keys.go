@@ -0,0 +1,249 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+)
+
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	ES256 = "ES256"
+)
+
+// key é uma chave de assinatura ou verificação. Chaves HMAC usam secret; as
+// assimétricas usam public e, quando podem assinar, private.
+type key struct {
+	id        string
+	algorithm string
+	secret    []byte
+	private   crypto.Signer
+	public    crypto.PublicKey
+}
+
+func newSecretKey(id string, secret []byte) *key {
+	return &key{id: id, algorithm: HS256, secret: secret}
+}
+
+// newPrivateKey cria a chave de assinatura a partir de um PEM (PKCS#1, PKCS#8
+// ou SEC 1) compatível com o algoritmo.
+func newPrivateKey(id string, algorithm string, data []byte) (*key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrInvalidKey
+	}
+
+	var parsed interface{}
+	var err error
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+
+	k := &key{id: id, algorithm: algorithm, private: signer, public: signer.Public()}
+
+	if !k.compatible() {
+		return nil, ErrInvalidKey
+	}
+
+	return k, nil
+}
+
+func (k *key) compatible() bool {
+	switch k.algorithm {
+	case HS256:
+		return len(k.secret) > 0
+	case RS256:
+		_, ok := k.public.(*rsa.PublicKey)
+		return ok
+	case ES256:
+		public, ok := k.public.(*ecdsa.PublicKey)
+		return ok && public.Curve == elliptic.P256()
+	default:
+		return false
+	}
+}
+
+func (k *key) sign(input []byte) ([]byte, error) {
+	digest := sha256.Sum256(input)
+
+	switch k.algorithm {
+	case HS256:
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write(input)
+		return mac.Sum(nil), nil
+	case RS256:
+		return k.private.Sign(rand.Reader, digest[:], crypto.SHA256)
+	case ES256:
+		r, s, err := ecdsa.Sign(rand.Reader, k.private.(*ecdsa.PrivateKey), digest[:])
+		if err != nil {
+			return nil, err
+		}
+
+		// JWS usa r || s com 32 bytes cada, e não o DER do crypto/ecdsa.
+		signature := make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+		return signature, nil
+	default:
+		return nil, ErrUnsupportedAlgorithm
+	}
+}
+
+func (k *key) verify(input []byte, signature []byte) bool {
+	digest := sha256.Sum256(input)
+
+	switch k.algorithm {
+	case HS256:
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write(input)
+		return hmac.Equal(signature, mac.Sum(nil))
+	case RS256:
+		return rsa.VerifyPKCS1v15(k.public.(*rsa.PublicKey), crypto.SHA256, digest[:], signature) == nil
+	case ES256:
+		if len(signature) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(k.public.(*ecdsa.PublicKey), digest[:], r, s)
+	default:
+		return false
+	}
+}
+
+// JWK é uma chave no formato JSON Web Key (RFC 7517). Chaves "oct" (HMAC) são
+// aceitas na leitura do arquivo, mas nunca publicadas.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	K   string `json:"k,omitempty"`
+}
+
+// KeySet é um JWKS: o conjunto de chaves usado para verificar tokens.
+type KeySet struct {
+	Keys []JWK `json:"keys"`
+}
+
+func (j JWK) key() (*key, error) {
+	k, err := j.parse()
+
+	// Cada tipo de chave atende a um único algoritmo suportado.
+	if err == nil && j.Alg != "" && j.Alg != k.algorithm {
+		return nil, ErrInvalidKey
+	}
+
+	return k, err
+}
+
+func (j JWK) parse() (*key, error) {
+	switch j.Kty {
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(j.K)
+		if err != nil || len(secret) == 0 {
+			return nil, ErrInvalidKey
+		}
+		return newSecretKey(j.Kid, secret), nil
+	case "RSA":
+		n, errN := base64.RawURLEncoding.DecodeString(j.N)
+		e, errE := base64.RawURLEncoding.DecodeString(j.E)
+		if errN != nil || errE != nil || len(n) == 0 || len(e) == 0 {
+			return nil, ErrInvalidKey
+		}
+		public := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		return &key{id: j.Kid, algorithm: RS256, public: public}, nil
+	case "EC":
+		if j.Crv != "P-256" {
+			return nil, ErrInvalidKey
+		}
+		x, errX := base64.RawURLEncoding.DecodeString(j.X)
+		y, errY := base64.RawURLEncoding.DecodeString(j.Y)
+		if errX != nil || errY != nil {
+			return nil, ErrInvalidKey
+		}
+		public := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !public.Curve.IsOnCurve(public.X, public.Y) {
+			return nil, ErrInvalidKey
+		}
+		return &key{id: j.Kid, algorithm: ES256, public: public}, nil
+	default:
+		return nil, ErrInvalidKey
+	}
+}
+
+// jwk retorna a parte pública da chave; chaves HMAC não têm parte pública.
+func (k *key) jwk() (JWK, bool) {
+	switch public := k.public.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Kid: k.id,
+			Use: "sig",
+			Alg: RS256,
+			N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+		}, true
+	case *ecdsa.PublicKey:
+		x := make([]byte, 32)
+		y := make([]byte, 32)
+		public.X.FillBytes(x)
+		public.Y.FillBytes(y)
+
+		return JWK{
+			Kty: "EC",
+			Kid: k.id,
+			Use: "sig",
+			Alg: ES256,
+			Crv: "P-256",
+			X:   base64.RawURLEncoding.EncodeToString(x),
+			Y:   base64.RawURLEncoding.EncodeToString(y),
+		}, true
+	default:
+		return JWK{}, false
+	}
+}
@@ -15,59 +15,316 @@
  */
 package jwt
 
-// func NewJWTManager(signingKey ...string) *JWTManager {
-// 	var key []byte
-// 	if len(signingKey) > 0 && signingKey[0] != "" {
-// 		key = []byte(signingKey[0])
-// 	} else {
-// 		key = []byte(env.GetEnv("JTW_SECRET"))
-// 	}
-// 	return &JWTManager{signingKey: []byte(key)}
-// }
-
-// func (manager *JWTManager) GenerateToken(expirationTime time.Duration, data map[string]interface{}) (string, error) {
-// 	claims := jwt.MapClaims{
-// 		"exp": time.Now().Add(expirationTime).Unix(),
-// 	}
-
-// 	for k, v := range data {
-// 		claims[k] = v
-// 	}
-
-// 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-// 	return token.SignedString(manager.signingKey)
-// }
-
-// func (manager *JWTManager) ValidateToken(tokenString string) (jwt.Claims, error) {
-// 	claims := jwt.MapClaims{}
-
-// 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-// 		return manager.signingKey, nil
-// 	})
-
-// 	if err != nil || !token.Valid {
-// 		return nil, fmt.Errorf("token inválido")
-// 	}
-
-// 	return claims, nil
-// }
-
-// func (manager *JWTManager) DecodeToken(tokenString string) (map[string]interface{}, error) {
-// 	claims := jwt.MapClaims{}
-
-// 	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-// 		return manager.signingKey, nil
-// 	})
-
-// 	if err != nil {
-// 		return nil, fmt.Errorf("erro ao decodificar o token: %w", err)
-// 	}
-
-// 	decodedData := make(map[string]interface{})
-// 	for k, v := range claims {
-// 		decodedData[k] = v
-// 	}
-
-// 	return decodedData, nil
-// }
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// reloadInterval limita a releitura do JWKS quando chega um kid desconhecido.
+const reloadInterval = time.Minute
+
+// Config define as chaves e as regras de validação do JWTManager.
+type Config struct {
+	// Algorithm é o algoritmo de assinatura: HS256, RS256 ou ES256.
+	Algorithm string
+	// KeyID vai no cabeçalho kid dos tokens emitidos.
+	KeyID string
+	// Secret é a chave HMAC usada com HS256.
+	Secret []byte
+	// PrivateKey é a chave PEM usada com RS256 e ES256.
+	PrivateKey []byte
+	// JWKSFile é um arquivo JWKS com chaves adicionais de verificação,
+	// relido quando chega um token com kid desconhecido.
+	JWKSFile   string
+	Issuer     string
+	Audience   []string
+	Expiration time.Duration
+	// Leeway é a tolerância de relógio aplicada a exp e nbf.
+	Leeway time.Duration
+}
+
+type JWTManager struct {
+	config     Config
+	signingKey *key
+	keys       []*key
+	lastReload time.Time
+	mu         sync.RWMutex
+	now        func() time.Time
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+	Kid string `json:"kid,omitempty"`
+}
+
+// NewJWTManager cria o gerenciador. Sem chave de assinatura ele apenas valida
+// tokens, usando as chaves do JWKSFile.
+func NewJWTManager(config Config) (*JWTManager, error) {
+	manager := &JWTManager{config: config, now: time.Now}
+
+	switch {
+	case config.Algorithm == HS256 && len(config.Secret) > 0:
+		manager.signingKey = newSecretKey(config.KeyID, config.Secret)
+	case (config.Algorithm == RS256 || config.Algorithm == ES256) && len(config.PrivateKey) > 0:
+		signingKey, err := newPrivateKey(config.KeyID, config.Algorithm, config.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		manager.signingKey = signingKey
+	case config.Algorithm != HS256 && config.Algorithm != RS256 && config.Algorithm != ES256:
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	if err := manager.Reload(); err != nil {
+		return nil, err
+	}
+
+	if len(manager.keys) == 0 {
+		return nil, ErrNoKeys
+	}
+
+	return manager, nil
+}
+
+// Sign emite um token com as claims informadas. iat é sempre preenchido; exp,
+// iss e aud usam a configuração quando não foram informados.
+func (m *JWTManager) Sign(claims Claims) (string, error) {
+	if m.signingKey == nil {
+		return "", ErrNoSigningKey
+	}
+
+	now := m.now()
+	payload := Claims{"iat": now.Unix()}
+
+	if m.config.Expiration > 0 {
+		payload["exp"] = now.Add(m.config.Expiration).Unix()
+	}
+
+	if m.config.Issuer != "" {
+		payload["iss"] = m.config.Issuer
+	}
+
+	if len(m.config.Audience) == 1 {
+		payload["aud"] = m.config.Audience[0]
+	} else if len(m.config.Audience) > 1 {
+		payload["aud"] = m.config.Audience
+	}
+
+	for name, value := range claims {
+		payload[name] = value
+	}
+
+	headerJSON, err := json.Marshal(header{Alg: m.signingKey.algorithm, Typ: "JWT", Kid: m.signingKey.id})
+	if err != nil {
+		return "", err
+	}
+
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	input := encode(headerJSON) + "." + encode(payloadJSON)
+
+	signature, err := m.signingKey.sign([]byte(input))
+	if err != nil {
+		return "", err
+	}
+
+	return input + "." + encode(signature), nil
+}
+
+// Validate confere a assinatura e as claims exp, nbf, iss e aud, retornando as
+// claims do token. Os erros podem ser comparados com errors.Is.
+func (m *JWTManager) Validate(token string) (Claims, error) {
+	if token == "" {
+		return nil, ErrTokenMissing
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrTokenMalformed
+	}
+
+	var h header
+	if err := decodeJSON(parts[0], &h); err != nil {
+		return nil, ErrTokenMalformed
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrTokenMalformed
+	}
+
+	if h.Alg != HS256 && h.Alg != RS256 && h.Alg != ES256 {
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	if err := m.verify(h, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, err
+	}
+
+	var claims Claims
+	if err := decodeJSON(parts[1], &claims); err != nil {
+		return nil, ErrTokenMalformed
+	}
+
+	if err := m.validateClaims(claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// JWKS retorna as chaves públicas de verificação, para publicar em
+// /.well-known/jwks.json. Segredos HMAC nunca são incluídos.
+func (m *JWTManager) JWKS() KeySet {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	set := KeySet{Keys: []JWK{}}
+
+	for _, k := range m.keys {
+		if jwk, ok := k.jwk(); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+
+	return set
+}
+
+// Reload relê o JWKSFile, permitindo a rotação de chaves sem reiniciar a aplicação.
+func (m *JWTManager) Reload() error {
+	var keys []*key
+
+	if m.signingKey != nil {
+		keys = append(keys, m.signingKey)
+	}
+
+	if m.config.JWKSFile != "" {
+		data, err := os.ReadFile(m.config.JWKSFile)
+		if err != nil {
+			return err
+		}
+
+		var set KeySet
+		if err := json.Unmarshal(data, &set); err != nil {
+			return err
+		}
+
+		for _, jwk := range set.Keys {
+			k, err := jwk.key()
+			if err != nil {
+				return err
+			}
+			keys = append(keys, k)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.keys = keys
+	m.lastReload = m.now()
+
+	return nil
+}
+
+func (m *JWTManager) verify(h header, input []byte, signature []byte) error {
+	candidates := m.candidates(h)
+
+	if len(candidates) == 0 && h.Kid != "" && m.reloadDue() {
+		if err := m.Reload(); err == nil {
+			candidates = m.candidates(h)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return ErrUnknownKey
+	}
+
+	for _, k := range candidates {
+		if k.verify(input, signature) {
+			return nil
+		}
+	}
+
+	return ErrSignatureInvalid
+}
+
+// candidates retorna as chaves do algoritmo do token. Com kid, apenas a chave
+// com esse id; o algoritmo precisa coincidir para evitar troca de algoritmo.
+func (m *JWTManager) candidates(h header) []*key {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []*key
+
+	for _, k := range m.keys {
+		if k.algorithm != h.Alg || (h.Kid != "" && k.id != h.Kid) {
+			continue
+		}
+		result = append(result, k)
+	}
+
+	return result
+}
+
+func (m *JWTManager) reloadDue() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.config.JWKSFile != "" && m.now().Sub(m.lastReload) >= reloadInterval
+}
+
+func (m *JWTManager) validateClaims(claims Claims) error {
+	now := m.now()
+
+	if expiresAt, ok := claims.ExpiresAt(); ok && !now.Before(expiresAt.Add(m.config.Leeway)) {
+		return ErrTokenExpired
+	}
+
+	if notBefore, ok := claims.NotBefore(); ok && now.Add(m.config.Leeway).Before(notBefore) {
+		return ErrTokenNotValidYet
+	}
+
+	if m.config.Issuer != "" && claims.Issuer() != m.config.Issuer {
+		return ErrInvalidIssuer
+	}
+
+	if len(m.config.Audience) > 0 && !containsAny(claims.Audience(), m.config.Audience) {
+		return ErrInvalidAudience
+	}
+
+	return nil
+}
+
+func containsAny(values []string, accepted []string) bool {
+	for _, value := range values {
+		for _, candidate := range accepted {
+			if value == candidate {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func encode(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeJSON(segment string, target interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, target)
+}
@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	bson "go.mongodb.org/mongo-driver/bson"
+
 	rsql "github.com/caiomarcatti12/nanogo/pkg/rsql"
 	mock "github.com/stretchr/testify/mock"
 
@@ -307,6 +309,74 @@ func (_c *IMongoORM_Insert_Call[T]) RunAndReturn(run func(T) (uuid.UUID, error))
 	return _c
 }
 
+// RawQuery provides a mock function with given fields: query, sort, limit, skip
+func (_m *IMongoORM[T]) RawQuery(query bson.M, sort bson.M, limit int64, skip int64) ([]T, int64, error) {
+	ret := _m.Called(query, sort, limit, skip)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RawQuery")
+	}
+
+	var r0 []T
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(bson.M, bson.M, int64, int64) ([]T, int64, error)); ok {
+		return rf(query, sort, limit, skip)
+	}
+	if rf, ok := ret.Get(0).(func(bson.M, bson.M, int64, int64) []T); ok {
+		r0 = rf(query, sort, limit, skip)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]T)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(bson.M, bson.M, int64, int64) int64); ok {
+		r1 = rf(query, sort, limit, skip)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(bson.M, bson.M, int64, int64) error); ok {
+		r2 = rf(query, sort, limit, skip)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// IMongoORM_RawQuery_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RawQuery'
+type IMongoORM_RawQuery_Call[T interface{}] struct {
+	*mock.Call
+}
+
+// RawQuery is a helper method to define mock.On call
+//   - query bson.M
+//   - sort bson.M
+//   - limit int64
+//   - skip int64
+func (_e *IMongoORM_Expecter[T]) RawQuery(query interface{}, sort interface{}, limit interface{}, skip interface{}) *IMongoORM_RawQuery_Call[T] {
+	return &IMongoORM_RawQuery_Call[T]{Call: _e.mock.On("RawQuery", query, sort, limit, skip)}
+}
+
+func (_c *IMongoORM_RawQuery_Call[T]) Run(run func(query bson.M, sort bson.M, limit int64, skip int64)) *IMongoORM_RawQuery_Call[T] {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(bson.M), args[1].(bson.M), args[2].(int64), args[3].(int64))
+	})
+	return _c
+}
+
+func (_c *IMongoORM_RawQuery_Call[T]) Return(_a0 []T, _a1 int64, _a2 error) *IMongoORM_RawQuery_Call[T] {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *IMongoORM_RawQuery_Call[T]) RunAndReturn(run func(bson.M, bson.M, int64, int64) ([]T, int64, error)) *IMongoORM_RawQuery_Call[T] {
+	_c.Call.Return(run)
+	return _c
+}
+
 // RawQueryParseRsql provides a mock function with given fields: filter
 func (_m *IMongoORM[T]) RawQueryParseRsql(filter rsql.QueryFilter) ([]T, int64, error) {
 	ret := _m.Called(filter)
@@ -0,0 +1,425 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package nanogo
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/caiomarcatti12/nanogo/pkg/context_manager"
+	"github.com/caiomarcatti12/nanogo/pkg/di"
+	"github.com/caiomarcatti12/nanogo/pkg/env"
+	"github.com/caiomarcatti12/nanogo/pkg/event"
+	"github.com/caiomarcatti12/nanogo/pkg/grpc_webserver"
+	"github.com/caiomarcatti12/nanogo/pkg/health"
+	"github.com/caiomarcatti12/nanogo/pkg/i18n"
+	"github.com/caiomarcatti12/nanogo/pkg/log"
+	"github.com/caiomarcatti12/nanogo/pkg/queue"
+	"github.com/caiomarcatti12/nanogo/pkg/webserver"
+	webserver_route "github.com/caiomarcatti12/nanogo/pkg/webserver/routes"
+	"github.com/caiomarcatti12/nanogo/pkg/websocketserver"
+)
+
+// App é dono do container e controla o ciclo de vida da aplicação: inicia os
+// servidores e consumidores habilitados, aguarda SIGINT/SIGTERM e encerra tudo
+// respeitando o tempo de APP_SHUTDOWN_TIMEOUT.
+type App struct {
+	container di.IContainer
+	env       env.IEnv
+	i18n      i18n.I18N
+	logger    log.ILog
+
+	webServer       bool
+	webSocketServer bool
+	grpcServer      bool
+	consumers       []queue.QueueConsumer
+	modules         []Module
+
+	skipModuleHealthChecks bool
+
+	stopping atomic.Bool
+}
+
+type AppOption func(*App)
+
+// WithWebServer instala o WebServerModule e inicia o servidor HTTP junto com a aplicação.
+func WithWebServer() AppOption {
+	return func(a *App) {
+		a.webServer = true
+		a.modules = append(a.modules, WebServerModule())
+	}
+}
+
+// WithWebSocketServer instala o WebSocketModule e inicia o servidor WebSocket,
+// que também sobe o servidor HTTP.
+func WithWebSocketServer() AppOption {
+	return func(a *App) {
+		a.webSocketServer = true
+		a.modules = append(a.modules, WebSocketModule())
+	}
+}
+
+// WithGrpcServer instala o GrpcModule e inicia o servidor gRPC junto com a aplicação.
+func WithGrpcServer() AppOption {
+	return func(a *App) {
+		a.grpcServer = true
+		a.modules = append(a.modules, GrpcModule())
+	}
+}
+
+// WithQueueConsumers instala o QueueModule e inicia os consumidores informados
+// no provider de fila configurado.
+func WithQueueConsumers(consumers ...queue.QueueConsumer) AppOption {
+	return func(a *App) {
+		a.consumers = append(a.consumers, consumers...)
+		a.modules = append(a.modules, QueueModule())
+	}
+}
+
+// WithModules instala os módulos informados, além do CoreModule. Módulos com
+// rotas, handlers gRPC ou consumidores habilitam os servidores correspondentes.
+func WithModules(modules ...Module) AppOption {
+	return func(a *App) {
+		a.modules = append(a.modules, modules...)
+	}
+}
+
+// withoutModuleHealthChecks instala os módulos sem registrar as HealthChecks
+// deles. Usado por Bootstrap, que instala módulos que a aplicação pode não usar.
+func withoutModuleHealthChecks() AppOption {
+	return func(a *App) {
+		a.skipModuleHealthChecks = true
+	}
+}
+
+// NewApp carrega as variáveis de ambiente e instala o CoreModule e os módulos de
+// WithModules no container. Diferente de Bootstrap, retorna os erros em vez de
+// entrar em pânico.
+func NewApp(options ...AppOption) (*App, error) {
+	i18nAdapter, err := i18n.Factory()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := env.Loader(i18nAdapter); err != nil {
+		return nil, err
+	}
+
+	contextManager := context_manager.NewSafeContextManager()
+
+	envAdapter := env.Factory(i18nAdapter)
+
+	logAdapter := log.Factory(envAdapter, contextManager)
+
+	container := di.Factory(i18nAdapter, logAdapter)
+
+	app := &App{
+		container: container,
+		env:       envAdapter,
+		i18n:      i18nAdapter,
+		logger:    logAdapter,
+	}
+
+	for _, option := range options {
+		option(app)
+	}
+
+	if err := app.install(append([]Module{CoreModule()}, app.modules...)); err != nil {
+		return nil, err
+	}
+
+	return app, nil
+}
+
+// install registra primeiro as fábricas de todos os módulos e depois as rotas e
+// consumidores, que já podem depender de qualquer fábrica registrada.
+func (a *App) install(modules []Module) error {
+	modules = flattenModules(modules)
+
+	for _, module := range modules {
+		for _, provider := range module.Providers {
+			var err error
+
+			if p, ok := provider.(Provider); ok {
+				err = a.container.Register(p.Factory, p.Options...)
+			} else {
+				err = a.container.Register(provider)
+			}
+
+			if err != nil {
+				return a.installError(module, err)
+			}
+		}
+	}
+
+	for _, module := range modules {
+		if err := a.installComponents(module); err != nil {
+			return a.installError(module, err)
+		}
+	}
+
+	return nil
+}
+
+func (a *App) installComponents(module Module) error {
+	if len(module.Routes) > 0 {
+		server, err := a.container.GetByFactory(webserver.Factory)
+		if err != nil {
+			return err
+		}
+
+		for _, route := range module.Routes {
+			server.(webserver.IWebServer).AddRoute(route)
+		}
+
+		a.webServer = true
+	}
+
+	if len(module.WebSocketRoutes) > 0 {
+		server, err := a.container.GetByFactory(websocketserver.Factory)
+		if err != nil {
+			return err
+		}
+
+		for _, route := range module.WebSocketRoutes {
+			server.(websocketserver.IWebSocketServer).AddRoute(route)
+		}
+
+		a.webSocketServer = true
+	}
+
+	if len(module.GrpcHandlers) > 0 {
+		server, err := a.container.GetByFactory(grpc_webserver.Factory)
+		if err != nil {
+			return err
+		}
+
+		for _, handler := range module.GrpcHandlers {
+			server.(grpc_webserver.IGrpcServer).Add(handler)
+		}
+
+		a.grpcServer = true
+	}
+
+	if len(module.EventConsumers) > 0 {
+		dispatcher, err := a.container.GetByFactory(event.Factory)
+		if err != nil {
+			return err
+		}
+
+		for _, consumer := range module.EventConsumers {
+			if err := dispatcher.(event.IEventDispatcher).RegisterConsumer(consumer); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(module.HealthChecks) > 0 && !a.skipModuleHealthChecks {
+		if err := a.registerHealthChecks(module.HealthChecks...); err != nil {
+			return err
+		}
+	}
+
+	a.consumers = append(a.consumers, module.QueueConsumers...)
+
+	return nil
+}
+
+func (a *App) registerHealthChecks(checks ...health.Check) error {
+	registry, err := a.container.GetByFactory(health.Factory)
+	if err != nil {
+		return err
+	}
+
+	for _, check := range checks {
+		if err := registry.(health.IRegistry).Register(check); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *App) installError(module Module, err error) error {
+	return errors.New(a.i18n.Get("app.module_install_failed", map[string]interface{}{"module": module.Name, "error": err.Error()}))
+}
+
+// Container retorna o container de injeção de dependências da aplicação.
+func (a *App) Container() di.IContainer {
+	return a.container
+}
+
+// Run inicia os componentes e bloqueia até receber SIGINT/SIGTERM ou até algum
+// servidor falhar. Em seguida marca /healthz/readyz como indisponível, aguarda
+// APP_SHUTDOWN_DELAY e encerra servidores, consumidores e componentes dentro de
+// APP_SHUTDOWN_TIMEOUT.
+func (a *App) Run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := a.container.Start(ctx); err != nil {
+		return err
+	}
+
+	failures := make(chan error, 1)
+
+	if err := a.startServers(failures); err != nil {
+		return errors.Join(err, a.shutdown())
+	}
+
+	a.logger.Info(a.i18n.Get("app.started", map[string]interface{}{}))
+
+	var runErr error
+
+	select {
+	case <-ctx.Done():
+		a.logger.Info(a.i18n.Get("app.signal_received", map[string]interface{}{}))
+	case runErr = <-failures:
+		a.logger.Error(a.i18n.Get("app.component_failed", map[string]interface{}{"error": runErr.Error()}))
+	}
+
+	return errors.Join(runErr, a.shutdown())
+}
+
+func (a *App) startServers(failures chan<- error) error {
+	if a.webSocketServer {
+		server, err := a.container.GetByFactory(websocketserver.Factory)
+		if err != nil {
+			return err
+		}
+
+		a.serve("websocketserver", server.(websocketserver.IWebSocketServer).Start, failures)
+	} else if a.webServer {
+		server, err := a.container.GetByFactory(webserver.Factory)
+		if err != nil {
+			return err
+		}
+
+		a.serve("webserver", server.(webserver.IWebServer).Start, failures)
+	}
+
+	if a.grpcServer {
+		server, err := a.container.GetByFactory(grpc_webserver.Factory)
+		if err != nil {
+			return err
+		}
+
+		// A verificação só existe quando o servidor é iniciado pela aplicação.
+		if err := a.registerHealthChecks(health.Component("grpc", grpc_webserver.Factory)); err != nil {
+			return err
+		}
+
+		a.serve("grpc", server.(grpc_webserver.IGrpcServer).Start, failures)
+	}
+
+	if len(a.consumers) > 0 {
+		provider, err := a.container.GetByFactory(queue.Factory)
+		if err != nil {
+			return err
+		}
+
+		for _, consumer := range a.consumers {
+			a.serve(consumer.Queue.GetName(), func() error {
+				return provider.(queue.IQueue).AddConsumer(consumer)
+			}, failures)
+		}
+	}
+
+	return nil
+}
+
+// serve executa um componente bloqueante em background. Se ele retornar com erro
+// antes do encerramento, a aplicação inteira é encerrada.
+func (a *App) serve(component string, run func() error, failures chan<- error) {
+	go func() {
+		err := run()
+
+		if err == nil || a.stopping.Load() {
+			return
+		}
+
+		select {
+		case failures <- errors.New(a.i18n.Get("app.component_stopped", map[string]interface{}{"component": component, "error": err.Error()})):
+		default:
+		}
+	}()
+}
+
+// shutdown encerra primeiro o que recebe tráfego e depois os demais componentes,
+// para que as mensagens e requisições em andamento ainda encontrem as conexões abertas.
+func (a *App) shutdown() error {
+	a.stopping.Store(true)
+
+	if readiness, err := a.container.GetByFactory(webserver_route.NewReadiness); err == nil {
+		readiness.(webserver_route.IReadiness).SetReady(false)
+	}
+
+	time.Sleep(a.duration("APP_SHUTDOWN_DELAY", "0s"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.duration("APP_SHUTDOWN_TIMEOUT", "30s"))
+	defer cancel()
+
+	var errs []error
+
+	if a.webSocketServer {
+		if server, err := a.container.GetByFactory(websocketserver.Factory); err == nil {
+			errs = append(errs, server.(websocketserver.IWebSocketServer).Shutdown(ctx))
+		}
+	}
+
+	if a.webServer || a.webSocketServer {
+		if server, err := a.container.GetByFactory(webserver.Factory); err == nil {
+			errs = append(errs, server.(webserver.IWebServer).Shutdown(ctx))
+		}
+	}
+
+	if a.grpcServer {
+		if server, err := a.container.GetByFactory(grpc_webserver.Factory); err == nil {
+			errs = append(errs, server.(grpc_webserver.IGrpcServer).Stop(ctx))
+		}
+	}
+
+	// Os providers de fila drenam as mensagens em andamento no OnStop
+	errs = append(errs, a.container.Stop(ctx))
+
+	err := errors.Join(errs...)
+
+	if err != nil {
+		a.logger.Error(a.i18n.Get("app.shutdown_failed", map[string]interface{}{"error": err.Error()}))
+	} else {
+		a.logger.Info(a.i18n.Get("app.shutdown_completed", map[string]interface{}{}))
+	}
+
+	return err
+}
+
+func (a *App) duration(variable string, defaultValue string) time.Duration {
+	fallback, _ := time.ParseDuration(defaultValue)
+
+	parsed, err := time.ParseDuration(a.env.GetEnv(variable, defaultValue))
+	if err != nil {
+		a.logger.Warning(a.i18n.Get("app.invalid_duration", map[string]interface{}{"variable": variable, "default": defaultValue}))
+		return fallback
+	}
+
+	return parsed
+}
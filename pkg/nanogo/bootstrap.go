@@ -15,102 +15,15 @@
  */
 package nanogo
 
-import (
-	"github.com/caiomarcatti12/nanogo/pkg/context_manager"
-	"github.com/caiomarcatti12/nanogo/pkg/db"
-	"github.com/caiomarcatti12/nanogo/pkg/di"
-	"github.com/caiomarcatti12/nanogo/pkg/env"
-	"github.com/caiomarcatti12/nanogo/pkg/event"
-	"github.com/caiomarcatti12/nanogo/pkg/grpc_webserver"
-	"github.com/caiomarcatti12/nanogo/pkg/i18n"
-	"github.com/caiomarcatti12/nanogo/pkg/log"
-	"github.com/caiomarcatti12/nanogo/pkg/metric"
-	"github.com/caiomarcatti12/nanogo/pkg/queue"
-	"github.com/caiomarcatti12/nanogo/pkg/telemetry"
-	"github.com/caiomarcatti12/nanogo/pkg/webserver"
-	"github.com/caiomarcatti12/nanogo/pkg/websocketserver"
-)
-
+// Bootstrap registra os componentes de DefaultModules no container global.
+// Como a aplicação pode não usar todos eles, as verificações de saúde dos
+// módulos não são registradas: uma fila não configurada deixaria o
+// /healthz/readyz sempre em 503.
+//
+// Deprecated: use NewApp, que retorna os erros em vez de entrar em pânico, e
+// App.Run para iniciar e encerrar a aplicação de forma graciosa.
 func Bootstrap() {
-	i18nAdapter, err := i18n.Factory()
-
-	if err != nil {
-		panic(err)
-	}
-
-	err = env.Loader(i18nAdapter)
-
-	if err != nil {
-		panic(err)
-	}
-
-	contextManager := context_manager.NewSafeContextManager()
-
-	envAdapter := env.Factory(i18nAdapter)
-
-	logAdapter := log.Factory(envAdapter, contextManager)
-
-	container := di.Factory(i18nAdapter, logAdapter)
-
-	if err := container.Register(i18n.Factory); err != nil {
-		panic(err)
-	}
-
-	if err := container.Register(env.Factory); err != nil {
-		panic(err)
-	}
-
-	if err := container.Register(log.Factory); err != nil {
-		panic(err)
-	}
-
-	if err := container.Register(di.Factory); err != nil {
-		panic(err)
-	}
-
-	if err := container.Register(webserver.Factory); err != nil {
-		panic(err)
-	}
-
-	if err := container.Register(websocketserver.Factory); err != nil {
-		panic(err)
-	}
-
-	if err := container.Register(db.Factory); err != nil {
-		panic(err)
-	}
-
-	if err := container.Register(db.NewMongoORM[any]); err != nil {
+	if _, err := NewApp(WithModules(DefaultModules()...), withoutModuleHealthChecks()); err != nil {
 		panic(err)
 	}
-
-	if err := container.Register(context_manager.NewSafeContextManager); err != nil {
-		panic(err)
-	}
-
-	if err := container.Register(telemetry.Factory); err != nil {
-		panic(err)
-	}
-
-	if err := container.Register(event.Factory); err != nil {
-		panic(err)
-	}
-
-	if err := container.Register(metric.Factory); err != nil {
-		panic(err)
-	}
-
-	if err := container.Register(queue.Factory); err != nil {
-		panic(err)
-	}
-
-	if err := container.Register(grpc_webserver.Factory); err != nil {
-		panic(err)
-	}
-
-	// container.Register(queue.Factory)
-	// container.Register(metric.Factory)
-	// container.Register(cache.Factory)
-	// container.Register(cli.Factory)
-
 }
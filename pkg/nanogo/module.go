@@ -0,0 +1,114 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package nanogo
+
+import (
+	"github.com/caiomarcatti12/nanogo/pkg/di"
+	"github.com/caiomarcatti12/nanogo/pkg/event"
+	"github.com/caiomarcatti12/nanogo/pkg/grpc_webserver"
+	"github.com/caiomarcatti12/nanogo/pkg/health"
+	"github.com/caiomarcatti12/nanogo/pkg/queue"
+	webserver_types "github.com/caiomarcatti12/nanogo/pkg/webserver/types"
+	"github.com/caiomarcatti12/nanogo/pkg/websocketserver"
+)
+
+// Module agrupa os registros de uma parte da aplicação: fábricas do container,
+// rotas HTTP e WebSocket, handlers gRPC, consumidores de fila e de eventos e
+// verificações de saúde.
+// Os módulos de Imports são instalados antes, e um módulo com o mesmo Name é
+// instalado apenas uma vez mesmo quando importado por vários outros; as
+// HealthChecks de todas as ocorrências são somadas na instalação.
+type Module struct {
+	Name            string
+	Imports         []Module
+	Providers       []interface{}
+	Routes          []webserver_types.Route
+	WebSocketRoutes []websocketserver.Route
+	GrpcHandlers    []grpc_webserver.GRPCHandler
+	QueueConsumers  []queue.QueueConsumer
+	EventConsumers  []event.EventConsumer
+	HealthChecks    []health.Check
+}
+
+// Provider registra uma fábrica com opções de registro (ciclo de vida, nome,
+// grupo). Fábricas sem opções podem ser colocadas diretamente em Providers.
+type Provider struct {
+	Factory interface{}
+	Options []di.RegisterOption
+}
+
+// Provide cria um Provider para usar em Module.Providers.
+func Provide(factory interface{}, options ...di.RegisterOption) Provider {
+	return Provider{Factory: factory, Options: options}
+}
+
+// flattenModules retorna os módulos na ordem de instalação: cada import antes
+// de quem o importa, sem repetir módulos com o mesmo Name. As HealthChecks das
+// ocorrências repetidas são mescladas na primeira, sem repetir nomes.
+func flattenModules(modules []Module) []Module {
+	var ordered []Module
+	installed := make(map[string]bool)
+	repeatedChecks := make(map[string][]health.Check)
+
+	var visit func(module Module)
+	visit = func(module Module) {
+		if module.Name != "" {
+			if installed[module.Name] {
+				repeatedChecks[module.Name] = append(repeatedChecks[module.Name], module.HealthChecks...)
+				return
+			}
+			installed[module.Name] = true
+		}
+
+		for _, imported := range module.Imports {
+			visit(imported)
+		}
+
+		ordered = append(ordered, module)
+	}
+
+	for _, module := range modules {
+		visit(module)
+	}
+
+	for i, module := range ordered {
+		if checks := repeatedChecks[module.Name]; len(checks) > 0 {
+			ordered[i].HealthChecks = mergeHealthChecks(module.HealthChecks, checks)
+		}
+	}
+
+	return ordered
+}
+
+// mergeHealthChecks acrescenta a current as verificações de extra cujo nome
+// ainda não aparece, preservando a ordem.
+func mergeHealthChecks(current []health.Check, extra []health.Check) []health.Check {
+	merged := append([]health.Check(nil), current...)
+	names := make(map[string]bool, len(current))
+
+	for _, check := range current {
+		names[check.Name] = true
+	}
+
+	for _, check := range extra {
+		if !names[check.Name] {
+			names[check.Name] = true
+			merged = append(merged, check)
+		}
+	}
+
+	return merged
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package nanogo
+
+import (
+	"testing"
+
+	"github.com/caiomarcatti12/nanogo/pkg/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func moduleNames(modules []Module) []string {
+	names := make([]string, 0, len(modules))
+	for _, module := range modules {
+		names = append(names, module.Name)
+	}
+
+	return names
+}
+
+func TestFlattenModules_InstallsImportsFirstAndOnce(t *testing.T) {
+	core := Module{Name: "core"}
+	users := Module{Name: "users", Imports: []Module{core}}
+	orders := Module{Name: "orders", Imports: []Module{core, users}}
+
+	ordered := flattenModules([]Module{orders, users})
+
+	assert.Equal(t, []string{"core", "users", "orders"}, moduleNames(ordered))
+}
+
+func TestFlattenModules_KeepsUnnamedModules(t *testing.T) {
+	ordered := flattenModules([]Module{{}, {}})
+
+	assert.Len(t, ordered, 2)
+}
+
+func TestDefaultModules_QueueImportsMetric(t *testing.T) {
+	ordered := moduleNames(flattenModules(DefaultModules()))
+
+	assert.Less(t, indexOf(ordered, "nanogo.metric"), indexOf(ordered, "nanogo.queue"))
+	assert.Equal(t, "nanogo.core", ordered[0])
+}
+
+func indexOf(names []string, name string) int {
+	for i, current := range names {
+		if current == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func TestFlattenModules_MergesHealthChecksOfRepeatedModules(t *testing.T) {
+	base := Module{Name: "db", HealthChecks: []health.Check{{Name: "mongodb"}}}
+	extended := Module{Name: "db", HealthChecks: []health.Check{{Name: "mongodb"}, {Name: "replica"}}}
+	users := Module{Name: "users", Imports: []Module{base}}
+
+	ordered := flattenModules([]Module{users, extended})
+
+	assert.Equal(t, []string{"db", "users"}, moduleNames(ordered))
+	assert.Equal(t, []health.Check{{Name: "mongodb"}, {Name: "replica"}}, ordered[0].HealthChecks)
+}
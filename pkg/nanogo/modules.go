@@ -0,0 +1,169 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package nanogo
+
+import (
+	"net/http"
+
+	"github.com/caiomarcatti12/nanogo/pkg/authz"
+	"github.com/caiomarcatti12/nanogo/pkg/cache"
+	"github.com/caiomarcatti12/nanogo/pkg/context_manager"
+	"github.com/caiomarcatti12/nanogo/pkg/db"
+	"github.com/caiomarcatti12/nanogo/pkg/di"
+	"github.com/caiomarcatti12/nanogo/pkg/env"
+	"github.com/caiomarcatti12/nanogo/pkg/event"
+	"github.com/caiomarcatti12/nanogo/pkg/grpc_webserver"
+	"github.com/caiomarcatti12/nanogo/pkg/health"
+	"github.com/caiomarcatti12/nanogo/pkg/i18n"
+	"github.com/caiomarcatti12/nanogo/pkg/jwt"
+	"github.com/caiomarcatti12/nanogo/pkg/log"
+	"github.com/caiomarcatti12/nanogo/pkg/metric"
+	"github.com/caiomarcatti12/nanogo/pkg/queue"
+	"github.com/caiomarcatti12/nanogo/pkg/ratelimit"
+	"github.com/caiomarcatti12/nanogo/pkg/telemetry"
+	"github.com/caiomarcatti12/nanogo/pkg/webserver"
+	webserver_sse "github.com/caiomarcatti12/nanogo/pkg/webserver/sse"
+	webserver_types "github.com/caiomarcatti12/nanogo/pkg/webserver/types"
+	"github.com/caiomarcatti12/nanogo/pkg/websocketserver"
+)
+
+// CoreModule registra o que toda aplicação usa: i18n, variáveis de ambiente,
+// log, o próprio container, contexto, telemetria, eventos, a autorização das
+// rotas e o registro de verificações de saúde. É sempre instalado por NewApp.
+func CoreModule() Module {
+	return Module{
+		Name: "nanogo.core",
+		Providers: []interface{}{
+			i18n.Factory,
+			env.Factory,
+			log.Factory,
+			di.Factory,
+			context_manager.NewSafeContextManager,
+			telemetry.Factory,
+			event.Factory,
+			authz.Factory,
+			health.Factory,
+		},
+	}
+}
+
+// WebServerModule registra o servidor HTTP e o broker de Server-Sent Events.
+// Com o MetricModule, o servidor também publica métricas das requisições.
+func WebServerModule() Module {
+	return Module{
+		Name:      "nanogo.webserver",
+		Imports:   []Module{CoreModule()},
+		Providers: []interface{}{webserver.Factory, ratelimit.Factory, webserver_sse.Factory},
+	}
+}
+
+// WebSocketModule registra o servidor WebSocket, que usa o servidor HTTP.
+func WebSocketModule() Module {
+	return Module{
+		Name:      "nanogo.websocketserver",
+		Imports:   []Module{WebServerModule()},
+		Providers: []interface{}{websocketserver.Factory},
+	}
+}
+
+// GrpcModule registra o servidor gRPC.
+func GrpcModule() Module {
+	return Module{
+		Name:      "nanogo.grpc",
+		Imports:   []Module{CoreModule()},
+		Providers: []interface{}{grpc_webserver.Factory},
+	}
+}
+
+// DatabaseModule registra a conexão com o MongoDB, o ORM e a verificação de
+// saúde "mongodb".
+func DatabaseModule() Module {
+	return Module{
+		Name:         "nanogo.db",
+		Imports:      []Module{CoreModule()},
+		Providers:    []interface{}{db.Factory, db.NewMongoORM[any]},
+		HealthChecks: []health.Check{health.Component("mongodb", db.Factory)},
+	}
+}
+
+// MetricModule registra o coletor de métricas.
+func MetricModule() Module {
+	return Module{
+		Name:      "nanogo.metric",
+		Imports:   []Module{CoreModule()},
+		Providers: []interface{}{metric.Factory},
+	}
+}
+
+// QueueModule registra o provider de fila definido em QUEUE_PROVIDER e a
+// verificação de saúde "queue".
+func QueueModule() Module {
+	return Module{
+		Name:         "nanogo.queue",
+		Imports:      []Module{MetricModule()},
+		Providers:    []interface{}{queue.Factory},
+		HealthChecks: []health.Check{health.Component("queue", queue.Factory)},
+	}
+}
+
+// CacheModule registra o cache definido em CACHE_PROVIDER e a verificação de
+// saúde "redis".
+func CacheModule() Module {
+	return Module{
+		Name:         "nanogo.cache",
+		Imports:      []Module{CoreModule()},
+		Providers:    []interface{}{cache.Factory},
+		HealthChecks: []health.Check{health.Component("redis", cache.Factory)},
+	}
+}
+
+// JWTModule registra o JWTManager configurado pelas variáveis JWT_*.
+func JWTModule() Module {
+	return Module{
+		Name:      "nanogo.jwt",
+		Imports:   []Module{CoreModule()},
+		Providers: []interface{}{jwt.Factory},
+	}
+}
+
+// JWKSModule publica as chaves públicas do JWTManager em /.well-known/jwks.json,
+// para que outros serviços validem os tokens emitidos por esta aplicação.
+func JWKSModule() Module {
+	return Module{
+		Name:    "nanogo.jwks",
+		Imports: []Module{JWTModule(), WebServerModule()},
+		Routes: []webserver_types.Route{{
+			Path:        "/.well-known/jwks.json",
+			Method:      http.MethodGet,
+			IHandler:    jwt.NewJWKSController,
+			HandlerFunc: "Handler",
+			Name:        "jwks",
+		}},
+	}
+}
+
+// DefaultModules são os módulos que Bootstrap registrava antes da existência de
+// módulos. Novas aplicações devem incluir apenas os módulos que usam.
+func DefaultModules() []Module {
+	return []Module{
+		WebServerModule(),
+		WebSocketModule(),
+		DatabaseModule(),
+		MetricModule(),
+		QueueModule(),
+		GrpcModule(),
+	}
+}
@@ -18,11 +18,15 @@ package nanogo
 import (
 	"os"
 	"os/signal"
+	"syscall"
 )
 
+// WaitSignalStop bloqueia até o processo receber SIGINT ou SIGTERM.
+//
+// Deprecated: use App.Run, que também encerra os componentes de forma graciosa.
 func WaitSignalStop() {
 	// Aguarde um sinal de encerramento
 	sig := make(chan os.Signal, 1)
-	signal.Notify(sig, os.Interrupt, os.Kill)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
 	<-sig
 }
@@ -0,0 +1,69 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package queue
+
+import (
+	"context"
+	"sync"
+)
+
+// inFlight conta as mensagens em processamento para que o encerramento aguarde
+// os handlers terminarem antes de fechar a conexão.
+type inFlight struct {
+	mu       sync.Mutex
+	draining bool
+	wg       sync.WaitGroup
+}
+
+// track processa a mensagem em background. Depois que wait começa, recusa novas
+// mensagens e retorna false, para que o provider as devolva ao broker.
+func (f *inFlight) track(process func()) bool {
+	f.mu.Lock()
+	if f.draining {
+		f.mu.Unlock()
+		return false
+	}
+	f.wg.Add(1)
+	f.mu.Unlock()
+
+	go func() {
+		defer f.wg.Done()
+		process()
+	}()
+
+	return true
+}
+
+// wait bloqueia até que todas as mensagens terminem ou o ctx expire.
+func (f *inFlight) wait(ctx context.Context) error {
+	f.mu.Lock()
+	f.draining = true
+	f.mu.Unlock()
+
+	done := make(chan struct{})
+
+	go func() {
+		f.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package queue
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInFlight_WaitsForRunningMessages(t *testing.T) {
+	messages := &inFlight{}
+	var processed atomic.Int32
+
+	for i := 0; i < 3; i++ {
+		messages.track(func() {
+			time.Sleep(10 * time.Millisecond)
+			processed.Add(1)
+		})
+	}
+
+	assert.NoError(t, messages.wait(context.Background()))
+	assert.Equal(t, int32(3), processed.Load())
+}
+
+func TestInFlight_WaitRespectsContext(t *testing.T) {
+	messages := &inFlight{}
+	release := make(chan struct{})
+	defer close(release)
+
+	messages.track(func() { <-release })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, messages.wait(ctx), context.DeadlineExceeded)
+}
+
+func TestInFlight_RefusesMessagesOnceDraining(t *testing.T) {
+	messages := &inFlight{}
+	release := make(chan struct{})
+
+	assert.True(t, messages.track(func() { <-release }))
+
+	waited := make(chan error, 1)
+	go func() { waited <- messages.wait(context.Background()) }()
+
+	assert.Eventually(t, func() bool {
+		messages.mu.Lock()
+		defer messages.mu.Unlock()
+		return messages.draining
+	}, time.Second, time.Millisecond)
+
+	// Uma entrega já retirada do canal chega depois que wait começou.
+	assert.False(t, messages.track(func() { t.Error("message processed after draining started") }))
+
+	close(release)
+	assert.NoError(t, <-waited)
+}
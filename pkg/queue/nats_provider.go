@@ -1,16 +1,21 @@
 package queue
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
+	"sync"
 
 	"github.com/caiomarcatti12/nanogo/pkg/context_manager"
 	"github.com/caiomarcatti12/nanogo/pkg/di"
 	"github.com/caiomarcatti12/nanogo/pkg/env"
+	"github.com/caiomarcatti12/nanogo/pkg/i18n"
 	"github.com/caiomarcatti12/nanogo/pkg/log"
 	"github.com/caiomarcatti12/nanogo/pkg/mapper"
 	"github.com/caiomarcatti12/nanogo/pkg/metric"
+	"github.com/caiomarcatti12/nanogo/pkg/recovery"
 	"github.com/caiomarcatti12/nanogo/pkg/telemetry"
 	"github.com/google/uuid"
 	nats "github.com/nats-io/nats.go"
@@ -23,7 +28,11 @@ type Nats struct {
 	logger        log.ILog
 	metricMonitor metric.IMetric
 	telemetry     telemetry.ITelemetry
+	recoverer     *recovery.Recoverer
 	queues        map[string]NatsQueue
+	inFlight      *inFlight
+	subscriptions []*nats.Subscription
+	subMu         sync.Mutex
 }
 
 // NatsQueue holds configuration of a subscription subject and queue group.
@@ -35,13 +44,15 @@ type NatsQueue struct {
 func (n *NatsQueue) GetName() string { return n.Name }
 
 // NewInstanceNats creates a new NATS provider instance.
-func NewInstanceNats(env env.IEnv, logger log.ILog, metricMonitor metric.IMetric, telemetry telemetry.ITelemetry) (IQueue, error) {
+func NewInstanceNats(env env.IEnv, logger log.ILog, i18n i18n.I18N, metricMonitor metric.IMetric, telemetry telemetry.ITelemetry) (IQueue, error) {
 	instance := &Nats{
 		url:           env.GetEnv("NATS_URL", nats.DefaultURL),
 		logger:        logger,
 		metricMonitor: metricMonitor,
 		telemetry:     telemetry,
+		recoverer:     recovery.NewRecoverer("queue", logger, i18n, metricMonitor),
 		queues:        make(map[string]NatsQueue),
+		inFlight:      &inFlight{},
 	}
 
 	if err := instance.Connect(); err != nil {
@@ -117,18 +128,29 @@ func (n *Nats) Consume(queue Queue, consumerHandler interface{}) error {
 		return fmt.Errorf("queue %s not found in configuration", queue.GetName())
 	}
 
-	consumerInstance, err := n.createConsumerInstance(consumerHandler)
-	if err != nil {
-		return err
+	if !di.GetInstance().IsRegistered(consumerHandler) {
+		if err := di.GetInstance().Register(consumerHandler); err != nil {
+			return err
+		}
 	}
 
-	_, err = n.Conn.QueueSubscribe(qCfg.Name, qCfg.QueueGroup, func(m *nats.Msg) {
-		go n.processMessages(m, queue, consumerInstance)
+	subscription, err := n.Conn.QueueSubscribe(qCfg.Name, qCfg.QueueGroup, func(m *nats.Msg) {
+		accepted := n.inFlight.track(func() {
+			n.processMessages(m, queue, consumerHandler)
+		})
+
+		if !accepted {
+			n.logger.Warning("NATS message received while shutting down was not processed")
+		}
 	})
 	if err != nil {
 		return err
 	}
 
+	n.subMu.Lock()
+	n.subscriptions = append(n.subscriptions, subscription)
+	n.subMu.Unlock()
+
 	n.metricMonitor.SetGauge(QueueConsummerConnected.String(), 1, map[string]string{"queue": qCfg.Name})
 	n.Conn.Flush()
 	return nil
@@ -143,6 +165,38 @@ func (n *Nats) Disconnect() error {
 	return nil
 }
 
+// HealthCheck fails while the NATS connection is not established, including
+// while the client is reconnecting.
+func (n *Nats) HealthCheck(ctx context.Context) error {
+	if n.Conn == nil {
+		return errors.New("NATS is not connected")
+	}
+
+	if !n.Conn.IsConnected() {
+		return fmt.Errorf("NATS connection is %s", n.Conn.Status())
+	}
+
+	return nil
+}
+
+// OnStop unsubscribes the consumers, waits for the messages being processed and
+// closes the NATS connection when the application stops.
+func (n *Nats) OnStop(ctx context.Context) error {
+	n.subMu.Lock()
+	for _, subscription := range n.subscriptions {
+		if err := subscription.Unsubscribe(); err != nil {
+			n.logger.Error(err.Error())
+		}
+	}
+	n.subscriptions = nil
+	n.subMu.Unlock()
+
+	n.logger.Info("Waiting for in-flight NATS messages...")
+	err := n.inFlight.wait(ctx)
+
+	return errors.Join(err, n.Disconnect())
+}
+
 // AddConsumer configures a queue and sets up a consumer in a single call
 func (n *Nats) AddConsumer(consumer QueueConsumer) error {
 	// Configure the queue
@@ -151,7 +205,7 @@ func (n *Nats) AddConsumer(consumer QueueConsumer) error {
 	}
 
 	// Register the handler in the DI container
-	if err := di.GetInstance().Register(consumer.Handler); err != nil {
+	if err := di.GetInstance().Register(consumer.Handler, di.WithLifetime(consumer.Lifetime)); err != nil {
 		n.logger.Warning("Handler already registered in DI container")
 	}
 
@@ -164,12 +218,14 @@ func (n *Nats) AddConsumer(consumer QueueConsumer) error {
 	return nil
 }
 
-func (n *Nats) createConsumerInstance(consumerHandler interface{}) (interface{}, error) {
-	di.GetInstance().Register(consumerHandler)
-	return di.GetInstance().GetByFactory(consumerHandler)
+func (n *Nats) createConsumerInstance(scope di.IScope, consumerHandler interface{}) (interface{}, error) {
+	return scope.GetByFactory(consumerHandler)
 }
 
-func (n *Nats) processMessages(m *nats.Msg, queue Queue, consumer interface{}) {
+func (n *Nats) processMessages(m *nats.Msg, queue Queue, consumerHandler interface{}) {
+	scope := di.GetInstance().CreateScope()
+	defer scope.Close()
+
 	fcm := context_manager.NewSafeContextManager()
 	correlationID := m.Header.Get("x-correlation-id")
 	if correlationID == "" {
@@ -178,8 +234,10 @@ func (n *Nats) processMessages(m *nats.Msg, queue Queue, consumer interface{}) {
 
 	ctxVals := fcm.CreateValue("x-correlation-id", correlationID)
 	fcm.SetValues(ctxVals, func() {
+		var err error
+
 		root := n.telemetry.CreateRootSpan(fmt.Sprintf("Process message queue %s", queue.GetName()), map[string]interface{}{"correlationID": correlationID})
-		defer func() { n.telemetry.EndSpan(root, nil) }()
+		defer func() { n.telemetry.EndSpan(root, err) }()
 
 		headers := map[string]interface{}{}
 		for k, v := range m.Header {
@@ -188,7 +246,12 @@ func (n *Nats) processMessages(m *nats.Msg, queue Queue, consumer interface{}) {
 			}
 		}
 
-		err := n.callConsumerHandler(consumer, m.Data, headers)
+		var consumer interface{}
+		consumer, err = n.createConsumerInstance(scope, consumerHandler)
+		if err == nil {
+			err = n.callConsumerHandler(consumer, m.Data, headers)
+		}
+
 		if err != nil {
 			n.logger.Error(err.Error())
 			n.metricMonitor.IncrementCounter(QueueMessageNack.String(), map[string]string{"queue": queue.GetName()})
@@ -198,7 +261,13 @@ func (n *Nats) processMessages(m *nats.Msg, queue Queue, consumer interface{}) {
 	})
 }
 
-func (n *Nats) callConsumerHandler(consumer interface{}, body []byte, headers map[string]interface{}) error {
+func (n *Nats) callConsumerHandler(consumer interface{}, body []byte, headers map[string]interface{}) (err error) {
+	defer func() {
+		if value := recover(); value != nil {
+			err = n.recoverer.Recover(value)
+		}
+	}()
+
 	handlerType := reflect.TypeOf(consumer)
 	method, exists := handlerType.MethodByName("Handler")
 	if !exists {
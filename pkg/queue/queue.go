@@ -18,7 +18,9 @@ package queue
 import (
 	"fmt"
 
+	"github.com/caiomarcatti12/nanogo/pkg/di"
 	"github.com/caiomarcatti12/nanogo/pkg/env"
+	"github.com/caiomarcatti12/nanogo/pkg/i18n"
 	"github.com/caiomarcatti12/nanogo/pkg/log"
 	"github.com/caiomarcatti12/nanogo/pkg/metric"
 	"github.com/caiomarcatti12/nanogo/pkg/telemetry"
@@ -33,8 +35,9 @@ type IConsumerHandler interface {
 }
 
 type QueueConsumer struct {
-	Queue   Queue
-	Handler interface{}
+	Queue    Queue
+	Handler  interface{}
+	Lifetime di.Lifetime
 }
 
 type IConfig interface {
@@ -71,7 +74,7 @@ func (qm QueueMetrics) String() string {
 	return string(qm)
 }
 
-func Factory(env env.IEnv, logger log.ILog, metricMonitor metric.IMetric, telemetry telemetry.ITelemetry) IQueue {
+func Factory(env env.IEnv, logger log.ILog, i18n i18n.I18N, metricMonitor metric.IMetric, telemetry telemetry.ITelemetry) IQueue {
 	logger.Info("Creating queue provider...")
 
 	logger.Info("Creating metric monitor queues...")
@@ -88,7 +91,7 @@ func Factory(env env.IEnv, logger log.ILog, metricMonitor metric.IMetric, teleme
 
 	switch provider {
 	case "RABBITMQ":
-		instance, err := NewInstanceRabbitmq(env, logger, metricMonitor, telemetry)
+		instance, err := NewInstanceRabbitmq(env, logger, i18n, metricMonitor, telemetry)
 
 		if err != nil {
 			panic(err)
@@ -96,7 +99,7 @@ func Factory(env env.IEnv, logger log.ILog, metricMonitor metric.IMetric, teleme
 
 		return instance
 	case "NATS":
-		instance, err := NewInstanceNats(env, logger, metricMonitor, telemetry)
+		instance, err := NewInstanceNats(env, logger, i18n, metricMonitor, telemetry)
 
 		if err != nil {
 			panic(err)
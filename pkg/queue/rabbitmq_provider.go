@@ -16,6 +16,7 @@
 package queue
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -25,9 +26,11 @@ import (
 	"github.com/caiomarcatti12/nanogo/pkg/context_manager"
 	"github.com/caiomarcatti12/nanogo/pkg/di"
 	"github.com/caiomarcatti12/nanogo/pkg/env"
+	"github.com/caiomarcatti12/nanogo/pkg/i18n"
 	"github.com/caiomarcatti12/nanogo/pkg/log"
 	"github.com/caiomarcatti12/nanogo/pkg/mapper"
 	"github.com/caiomarcatti12/nanogo/pkg/metric"
+	"github.com/caiomarcatti12/nanogo/pkg/recovery"
 	"github.com/caiomarcatti12/nanogo/pkg/telemetry"
 	"github.com/google/uuid"
 	"github.com/streadway/amqp"
@@ -45,8 +48,12 @@ type Rabbitmq struct {
 	logger         log.ILog
 	metricMonitor  metric.IMetric
 	telemetry      telemetry.ITelemetry
+	recoverer      *recovery.Recoverer
 	exchanges      map[string]RabbitmqExchange
 	queues         map[string]RabbitmqQueue
+	inFlight       *inFlight
+	consumerTags   []string
+	consumerMu     sync.Mutex
 }
 
 type DataConnection struct {
@@ -103,7 +110,7 @@ func (r *RabbitmqQueue) GetName() string {
 	return r.Name
 }
 
-func NewInstanceRabbitmq(env env.IEnv, logger log.ILog, metricMonitor metric.IMetric, telemetry telemetry.ITelemetry) (IQueue, error) {
+func NewInstanceRabbitmq(env env.IEnv, logger log.ILog, i18n i18n.I18N, metricMonitor metric.IMetric, telemetry telemetry.ITelemetry) (IQueue, error) {
 	logger.Info("Creating instance of RabbitMQ...")
 
 	once.Do(func() {
@@ -111,6 +118,7 @@ func NewInstanceRabbitmq(env env.IEnv, logger log.ILog, metricMonitor metric.IMe
 			logger:        logger,
 			metricMonitor: metricMonitor,
 			telemetry:     telemetry,
+			recoverer:     recovery.NewRecoverer("queue", logger, i18n, metricMonitor),
 			DataConnection: DataConnection{
 				Protocol: env.GetEnv("RABBITMQ_PROTOCOL"),
 				User:     env.GetEnv("RABBITMQ_USER"),
@@ -121,6 +129,7 @@ func NewInstanceRabbitmq(env env.IEnv, logger log.ILog, metricMonitor metric.IMe
 			},
 			exchanges: make(map[string]RabbitmqExchange),
 			queues:    make(map[string]RabbitmqQueue),
+			inFlight:  &inFlight{},
 		}
 
 		instance.Connect()
@@ -308,39 +317,55 @@ func (r *Rabbitmq) Consume(queue Queue, consumerHandler interface{}) error {
 		return fmt.Errorf("queue %s not found in configuration", queue.GetName())
 	}
 
+	// A tag é necessária para cancelar o consumo no encerramento
+	consumerTag := rabbitmQueueConfig.ConsumerTag
+	if consumerTag == "" {
+		consumerTag = uuid.New().String()
+	}
+
 	msgs, err := r.Channel.Consume(
-		rabbitmQueueConfig.Name,        // queue
-		rabbitmQueueConfig.ConsumerTag, // consumer
-		false,                          // auto-ack
-		rabbitmQueueConfig.Exclusive,   // exclusive
-		rabbitmQueueConfig.NoLocal,     // no-local
-		rabbitmQueueConfig.NoWait,      // no-wait
-		nil,                            // args
+		rabbitmQueueConfig.Name,      // queue
+		consumerTag,                  // consumer
+		false,                        // auto-ack
+		rabbitmQueueConfig.Exclusive, // exclusive
+		rabbitmQueueConfig.NoLocal,   // no-local
+		rabbitmQueueConfig.NoWait,    // no-wait
+		nil,                          // args
 	)
 
 	if err != nil {
 		return err
 	}
 
-	// Criar a instância do consumidor uma vez
-	consumerInstance, err := r.createConsumerInstance(consumerHandler)
-	if err != nil {
-		return err
+	// Registra o consumidor caso AddConsumer não tenha feito isso
+	if !di.GetInstance().IsRegistered(consumerHandler) {
+		if err := di.GetInstance().Register(consumerHandler); err != nil {
+			return err
+		}
 	}
 
+	r.consumerMu.Lock()
+	r.consumerTags = append(r.consumerTags, consumerTag)
+	r.consumerMu.Unlock()
+
 	r.metricMonitor.SetGauge(QueueConsummerConnected.String(), 1, map[string]string{"queue": rabbitmQueueConfig.Name})
 
 	for d := range msgs {
-		go r.processMessages(d, queue, consumerInstance)
+		accepted := r.inFlight.track(func() {
+			r.processMessages(d, queue, consumerHandler)
+		})
+
+		// A mensagem chegou depois do início do encerramento; volta para a fila.
+		if !accepted {
+			d.Nack(false, true)
+		}
 	}
 
 	return nil
 }
 
-func (r *Rabbitmq) createConsumerInstance(consumerHandler interface{}) (interface{}, error) {
-	di.GetInstance().Register(consumerHandler)
-
-	consumer, err := di.GetInstance().GetByFactory(consumerHandler)
+func (r *Rabbitmq) createConsumerInstance(scope di.IScope, consumerHandler interface{}) (interface{}, error) {
+	consumer, err := scope.GetByFactory(consumerHandler)
 	if err != nil {
 		return nil, err
 	}
@@ -349,9 +374,13 @@ func (r *Rabbitmq) createConsumerInstance(consumerHandler interface{}) (interfac
 }
 
 // Função privada para processar mensagens
-func (r *Rabbitmq) processMessages(d amqp.Delivery, queue Queue, consumer interface{}) {
+func (r *Rabbitmq) processMessages(d amqp.Delivery, queue Queue, consumerHandler interface{}) {
 	r.logger.Trace("Processing message from %s", queue.GetName())
 
+	// Cada entrega possui seu próprio escopo de DI
+	scope := di.GetInstance().CreateScope()
+	defer scope.Close()
+
 	fcm := context_manager.NewSafeContextManager()
 
 	correlationID, ok := d.Headers["x-correlation-id"].(string)
@@ -361,15 +390,22 @@ func (r *Rabbitmq) processMessages(d amqp.Delivery, queue Queue, consumer interf
 
 	contextValues := fcm.CreateValue("x-correlation-id", correlationID)
 	fcm.SetValues(contextValues, func() {
+		var err error
+
 		rootSpan := r.telemetry.CreateRootSpan(fmt.Sprintf("Process message queue %s", queue.GetName()), map[string]interface{}{"correlationID": correlationID})
-		defer func() { r.telemetry.EndSpan(rootSpan, nil) }()
+		defer func() { r.telemetry.EndSpan(rootSpan, err) }()
 
 		headers := make(map[string]interface{})
 		for k, v := range d.Headers {
 			headers[k] = v
 		}
 
-		err := r.callConsumerHandler(consumer, d.Body, headers)
+		var consumer interface{}
+		consumer, err = r.createConsumerInstance(scope, consumerHandler)
+		if err == nil {
+			err = r.callConsumerHandler(consumer, d.Body, headers)
+		}
+
 		if err != nil {
 			r.logger.Error(err.Error())
 			d.Nack(false, false)
@@ -386,6 +422,38 @@ func (r *Rabbitmq) Disconnect() error {
 	return r.Connection.Close()
 }
 
+// HealthCheck fails when the connection to RabbitMQ was never opened or has
+// been closed by the broker.
+func (r *Rabbitmq) HealthCheck(ctx context.Context) error {
+	if r.Connection == nil || r.Connection.IsClosed() {
+		return errors.New("RabbitMQ is not connected")
+	}
+
+	return nil
+}
+
+// OnStop cancels the consumers, waits for the messages being processed and
+// closes the RabbitMQ connection when the application stops.
+func (r *Rabbitmq) OnStop(ctx context.Context) error {
+	if r.Connection == nil || r.Connection.IsClosed() {
+		return nil
+	}
+
+	r.consumerMu.Lock()
+	for _, consumerTag := range r.consumerTags {
+		if err := r.Channel.Cancel(consumerTag, false); err != nil {
+			r.logger.Error(err.Error())
+		}
+	}
+	r.consumerTags = nil
+	r.consumerMu.Unlock()
+
+	r.logger.Info("Waiting for in-flight RabbitMQ messages...")
+	err := r.inFlight.wait(ctx)
+
+	return errors.Join(err, r.Disconnect())
+}
+
 // AddConsumer configures a queue and sets up a consumer in a single call
 func (r *Rabbitmq) AddConsumer(consumer QueueConsumer) error {
 	// Configure the queue
@@ -394,7 +462,7 @@ func (r *Rabbitmq) AddConsumer(consumer QueueConsumer) error {
 	}
 
 	// Register the handler in the DI container
-	if err := di.GetInstance().Register(consumer.Handler); err != nil {
+	if err := di.GetInstance().Register(consumer.Handler, di.WithLifetime(consumer.Lifetime)); err != nil {
 		r.logger.Warning("Handler already registered in DI container")
 	}
 
@@ -503,7 +571,14 @@ func (r *Rabbitmq) bindQueue(exchange RabbitmqExchange, queue RabbitmqQueue) err
 // Função para verificar se consumerHandler implementa IConsumer ignorando o tipo
 func (r *Rabbitmq) callConsumerHandler(consumer interface{}, body []byte, headers map[string]interface{}) (err error) {
 	span := r.telemetry.StartChildSpan("consumerHandler")
-	defer r.telemetry.EndSpan(span, err)
+	defer func() { r.telemetry.EndSpan(span, err) }()
+
+	// Um panic no handler vira erro e a mensagem recebe Nack.
+	defer func() {
+		if value := recover(); value != nil {
+			err = r.recoverer.Recover(value)
+		}
+	}()
 
 	handlerType := reflect.TypeOf(consumer)
 	handlerValue := reflect.ValueOf(consumer)
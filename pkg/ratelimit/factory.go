@@ -0,0 +1,63 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package ratelimit
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/caiomarcatti12/nanogo/pkg/cache"
+	"github.com/caiomarcatti12/nanogo/pkg/di"
+	"github.com/caiomarcatti12/nanogo/pkg/env"
+	"github.com/caiomarcatti12/nanogo/pkg/i18n"
+	"github.com/caiomarcatti12/nanogo/pkg/log"
+)
+
+type FactoryParams struct {
+	di.In
+	Env   env.IEnv
+	Log   log.ILog
+	I18N  i18n.I18N
+	Cache cache.ICache `optional:"true"`
+}
+
+// Factory cria o limitador do backend definido em RATE_LIMIT_STORE: "memory"
+// (padrão) ou "redis", que usa o cache registrado pelo CacheModule. Sem o
+// cache, o "redis" cai para o "memory".
+func Factory(params FactoryParams) (IRateLimiter, error) {
+	store := strings.ToLower(params.Env.GetEnv("RATE_LIMIT_STORE", "memory"))
+
+	if store == "redis" && params.Cache == nil {
+		params.Log.Warning(params.I18N.Get("ratelimit.cache_not_registered"))
+		store = "memory"
+	}
+
+	params.Log.Trace(params.I18N.Get("ratelimit.store_created", map[string]interface{}{"store": store}))
+
+	switch store {
+	case "memory":
+		return NewMemoryLimiter(), nil
+	case "redis":
+		pool, ok := params.Cache.(cache.IRedisPool)
+		if !ok {
+			return nil, errors.New(params.I18N.Get("ratelimit.redis_unavailable", map[string]interface{}{"error": "cache is not backed by Redis"}))
+		}
+
+		return NewRedisLimiter(pool, params.Env.GetEnv("REDIS_NAMESPACE", "")+"ratelimit:"), nil
+	default:
+		return nil, errors.New(params.I18N.Get("ratelimit.store_not_found", map[string]interface{}{"store": store}))
+	}
+}
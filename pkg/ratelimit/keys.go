@@ -0,0 +1,97 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package ratelimit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/caiomarcatti12/nanogo/pkg/jwt"
+)
+
+// KeyFunc identifica o cliente de uma requisição. Uma string vazia indica que
+// a requisição não tem a informação usada pela KeyFunc; nesse caso o
+// middleware usa o IP.
+type KeyFunc func(r *http.Request) string
+
+// ByIP usa o IP de origem. Com trustedProxies maior que zero, a requisição
+// passa por esse número de proxies confiáveis, cada um acrescentando um
+// endereço ao fim de X-Forwarded-For, e o IP é o endereço acrescentado pelo
+// primeiro deles. Os endereços à esquerda vêm do cliente e são ignorados.
+func ByIP(trustedProxies int) KeyFunc {
+	return func(r *http.Request) string {
+		if trustedProxies > 0 {
+			if client := forwardedFor(r, trustedProxies); client != "" {
+				return "ip:" + client
+			}
+		}
+
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+
+		return "ip:" + host
+	}
+}
+
+// forwardedFor retorna o endereço na posição hops a partir da direita de
+// X-Forwarded-For, ou o mais à esquerda quando a lista é menor.
+func forwardedFor(r *http.Request, hops int) string {
+	var addresses []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		for _, address := range strings.Split(header, ",") {
+			if address = strings.TrimSpace(address); address != "" {
+				addresses = append(addresses, address)
+			}
+		}
+	}
+
+	if len(addresses) == 0 {
+		return ""
+	}
+
+	return addresses[max(len(addresses)-hops, 0)]
+}
+
+// ByAPIKey usa a chave enviada no cabeçalho header. A chave é guardada como
+// hash, para não aparecer no backend.
+func ByAPIKey(header string) KeyFunc {
+	return func(r *http.Request) string {
+		apiKey := r.Header.Get(header)
+		if apiKey == "" {
+			return ""
+		}
+
+		sum := sha256.Sum256([]byte(apiKey))
+		return "key:" + hex.EncodeToString(sum[:])
+	}
+}
+
+// BySubject usa a claim sub do token validado pelo JWTMiddleware.
+func BySubject() KeyFunc {
+	return func(r *http.Request) string {
+		claims, ok := jwt.FromContext(r.Context())
+		if !ok || claims.Subject() == "" {
+			return ""
+		}
+
+		return "sub:" + claims.Subject()
+	}
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepInterval é o intervalo mínimo entre as limpezas dos contadores expirados.
+const sweepInterval = time.Minute
+
+// MemoryLimiter guarda os contadores no processo; cada réplica aplica o limite
+// separadamente. Use o RedisLimiter para um limite compartilhado.
+type MemoryLimiter struct {
+	entries   map[string]*memoryEntry
+	nextSweep time.Time
+	mu        sync.Mutex
+	now       func() time.Time
+}
+
+type memoryEntry struct {
+	// token bucket
+	tokens float64
+	last   time.Time
+
+	// sliding window
+	window   int64
+	previous int
+	current  int
+
+	expires time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		entries: make(map[string]*memoryEntry),
+		now:     time.Now,
+	}
+}
+
+func (m *MemoryLimiter) Allow(key string, policy Policy) (Result, error) {
+	policy = policy.WithDefaults(Policy{})
+	key = policy.Name + ":" + key
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	entry, ok := m.entries[key]
+
+	if policy.Algorithm == SlidingWindow {
+		window := now.UnixNano() / int64(policy.Period)
+
+		if !ok {
+			entry = &memoryEntry{window: window}
+			m.entries[key] = entry
+		}
+
+		switch {
+		case window == entry.window+1:
+			entry.previous, entry.current = entry.current, 0
+		case window != entry.window:
+			entry.previous, entry.current = 0, 0
+		}
+		entry.window = window
+		entry.expires = now.Add(2 * policy.Period)
+
+		elapsed := time.Duration(now.UnixNano() - window*int64(policy.Period))
+		allowed := estimate(policy, entry.previous, entry.current, elapsed)+1 <= float64(policy.Requests)
+		if allowed {
+			entry.current++
+		}
+
+		return slidingWindowResult(policy, allowed, entry.previous, entry.current, elapsed), nil
+	}
+
+	if !ok {
+		entry = &memoryEntry{tokens: float64(policy.Burst), last: now}
+		m.entries[key] = entry
+	}
+
+	allowed, tokens := takeToken(policy, entry.tokens, now.Sub(entry.last))
+	entry.tokens = tokens
+	entry.last = now
+	entry.expires = now.Add(seconds(float64(policy.Burst) / policy.rate()))
+
+	return tokenBucketResult(policy, allowed, tokens), nil
+}
+
+// sweep remove os contadores que já voltaram ao estado inicial.
+func (m *MemoryLimiter) sweep(now time.Time) {
+	if now.Before(m.nextSweep) {
+		return
+	}
+
+	for key, entry := range m.entries {
+		if now.After(entry.expires) {
+			delete(m.entries, key)
+		}
+	}
+
+	m.nextSweep = now.Add(sweepInterval)
+}
@@ -0,0 +1,226 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caiomarcatti12/nanogo/pkg/env"
+)
+
+type Algorithm string
+
+const (
+	// TokenBucket permite rajadas de até Burst requisições e repõe Requests
+	// fichas a cada Period.
+	TokenBucket Algorithm = "token_bucket"
+	// SlidingWindow limita a Requests por Period em uma janela deslizante,
+	// aproximada pela contagem da janela atual e da anterior.
+	SlidingWindow Algorithm = "sliding_window"
+)
+
+// Policy define um limite. Name separa os contadores de políticas diferentes;
+// o mesmo cliente tem um contador por Name. Campos vazios herdam da política
+// padrão (WithDefaults).
+type Policy struct {
+	Name      string
+	Algorithm Algorithm
+	Requests  int
+	Period    time.Duration
+	Burst     int
+	Key       KeyFunc
+}
+
+// Result é a decisão do limitador, já no formato dos cabeçalhos RateLimit-*.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+type IRateLimiter interface {
+	// Allow consome uma requisição do cliente key na política informada.
+	Allow(key string, policy Policy) (Result, error)
+}
+
+func (p Policy) IsEmpty() bool {
+	return p.Requests <= 0
+}
+
+// WithDefaults completa os campos vazios da política com os de defaults.
+func (p Policy) WithDefaults(defaults Policy) Policy {
+	if p.Algorithm == "" {
+		p.Algorithm = defaults.Algorithm
+	}
+	if p.Algorithm == "" {
+		p.Algorithm = TokenBucket
+	}
+	if p.Period <= 0 {
+		p.Period = defaults.Period
+	}
+	if p.Period <= 0 {
+		p.Period = time.Minute
+	}
+	if p.Burst <= 0 {
+		p.Burst = p.Requests
+	}
+	if p.Key == nil {
+		p.Key = defaults.Key
+	}
+	if p.Key == nil {
+		p.Key = ByIP(0)
+	}
+
+	return p
+}
+
+// Header retorna o valor de RateLimit-Policy, ex.: "100;w=60".
+func (p Policy) Header() string {
+	return fmt.Sprintf("%d;w=%d", p.Requests, int(math.Ceil(p.Period.Seconds())))
+}
+
+// DefaultPolicy lê a política global das variáveis RATE_LIMIT_*. Com
+// RATE_LIMIT_REQUESTS igual a zero a política fica vazia e o limite global
+// desligado, mas Algorithm, Period e Key continuam servindo de padrão para as
+// políticas das rotas.
+func DefaultPolicy(env env.IEnv) (Policy, error) {
+	requests, err := strconv.Atoi(env.GetEnv("RATE_LIMIT_REQUESTS", "0"))
+	if err != nil {
+		return Policy{}, fmt.Errorf("RATE_LIMIT_REQUESTS: %w", err)
+	}
+
+	period, err := time.ParseDuration(env.GetEnv("RATE_LIMIT_PERIOD", "1m"))
+	if err != nil {
+		return Policy{}, fmt.Errorf("RATE_LIMIT_PERIOD: %w", err)
+	}
+
+	burst, err := strconv.Atoi(env.GetEnv("RATE_LIMIT_BURST", "0"))
+	if err != nil {
+		return Policy{}, fmt.Errorf("RATE_LIMIT_BURST: %w", err)
+	}
+
+	algorithm := Algorithm(strings.ToLower(env.GetEnv("RATE_LIMIT_ALGORITHM", string(TokenBucket))))
+	if algorithm != TokenBucket && algorithm != SlidingWindow {
+		return Policy{}, fmt.Errorf("RATE_LIMIT_ALGORITHM: unknown algorithm %q", algorithm)
+	}
+
+	trustedProxies, err := strconv.Atoi(env.GetEnv("RATE_LIMIT_TRUSTED_PROXIES", "0"))
+	if err != nil || trustedProxies < 0 {
+		return Policy{}, fmt.Errorf("RATE_LIMIT_TRUSTED_PROXIES: invalid number of proxies %q", env.GetEnv("RATE_LIMIT_TRUSTED_PROXIES"))
+	}
+
+	var key KeyFunc
+	switch strings.ToLower(env.GetEnv("RATE_LIMIT_KEY", "ip")) {
+	case "ip":
+		key = ByIP(trustedProxies)
+	case "api_key":
+		key = ByAPIKey(env.GetEnv("RATE_LIMIT_API_KEY_HEADER", "X-API-Key"))
+	case "subject":
+		// O limite global roda antes do JWTMiddleware das rotas e não veria a
+		// claim; sem ele, subject serve só de padrão para as políticas das rotas.
+		if requests > 0 {
+			return Policy{}, fmt.Errorf("RATE_LIMIT_KEY: subject is only available to route policies")
+		}
+		key = BySubject()
+	default:
+		return Policy{}, fmt.Errorf("RATE_LIMIT_KEY: unknown key %q", env.GetEnv("RATE_LIMIT_KEY"))
+	}
+
+	return Policy{
+		Name:      "global",
+		Algorithm: algorithm,
+		Requests:  requests,
+		Period:    period,
+		Burst:     burst,
+		Key:       key,
+	}.WithDefaults(Policy{}), nil
+}
+
+func (p Policy) rate() float64 {
+	return float64(p.Requests) / p.Period.Seconds()
+}
+
+// takeToken repõe as fichas desde o último acesso e tenta consumir uma.
+func takeToken(policy Policy, tokens float64, elapsed time.Duration) (bool, float64) {
+	tokens = math.Min(float64(policy.Burst), tokens+elapsed.Seconds()*policy.rate())
+
+	if tokens >= 1 {
+		return true, tokens - 1
+	}
+
+	return false, tokens
+}
+
+func tokenBucketResult(policy Policy, allowed bool, tokens float64) Result {
+	result := Result{
+		Allowed:   allowed,
+		Limit:     policy.Burst,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((float64(policy.Burst) - tokens) / policy.rate()),
+	}
+
+	if !allowed {
+		result.RetryAfter = seconds((1 - tokens) / policy.rate())
+	}
+
+	return result
+}
+
+// estimate é a contagem da janela deslizante: a janela anterior pesa a fração
+// dela que ainda cabe no período.
+func estimate(policy Policy, previous int, current int, elapsed time.Duration) float64 {
+	weight := float64(policy.Period-elapsed) / float64(policy.Period)
+
+	return float64(previous)*weight + float64(current)
+}
+
+func slidingWindowResult(policy Policy, allowed bool, previous int, current int, elapsed time.Duration) Result {
+	estimated := estimate(policy, previous, current, elapsed)
+
+	result := Result{
+		Allowed:   allowed,
+		Limit:     policy.Requests,
+		Remaining: max(0, policy.Requests-int(math.Ceil(estimated))),
+		Reset:     policy.Period - elapsed,
+	}
+
+	if !allowed {
+		result.RetryAfter = result.Reset
+
+		// Enquanto a janela atual cabe no limite, basta esperar o peso da
+		// anterior diminuir.
+		if current < policy.Requests && previous > 0 {
+			wait := (estimated - float64(policy.Requests-1)) / float64(previous) * policy.Period.Seconds()
+			result.RetryAfter = min(result.RetryAfter, seconds(wait))
+		}
+	}
+
+	return result
+}
+
+func seconds(value float64) time.Duration {
+	if value <= 0 {
+		return 0
+	}
+
+	return time.Duration(value * float64(time.Second))
+}
@@ -0,0 +1,213 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/caiomarcatti12/nanogo/pkg/di/ditest"
+	"github.com/caiomarcatti12/nanogo/pkg/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeEnv map[string]string
+
+func (e fakeEnv) GetEnv(variable string, defaultValue ...string) string {
+	if value, ok := e[variable]; ok {
+		return value
+	}
+	if len(defaultValue) > 0 {
+		return defaultValue[0]
+	}
+	return ""
+}
+
+func (e fakeEnv) GetEnvBool(variable string, defaultValue ...string) bool {
+	return e.GetEnv(variable, defaultValue...) == "true"
+}
+
+func newTestLimiter(now *time.Time) *MemoryLimiter {
+	limiter := NewMemoryLimiter()
+	limiter.now = func() time.Time { return *now }
+	return limiter
+}
+
+func TestMemoryTokenBucket(t *testing.T) {
+	now := time.Unix(600, 0)
+	limiter := newTestLimiter(&now)
+	policy := Policy{Name: "test", Requests: 2, Period: time.Second}
+
+	for i := 0; i < 2; i++ {
+		result, err := limiter.Allow("client", policy)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 2, result.Limit)
+		assert.Equal(t, 1-i, result.Remaining)
+	}
+
+	result, err := limiter.Allow("client", policy)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 500*time.Millisecond, result.RetryAfter)
+
+	other, err := limiter.Allow("other", policy)
+	require.NoError(t, err)
+	assert.True(t, other.Allowed, "each key has its own bucket")
+
+	now = now.Add(500 * time.Millisecond)
+	result, err = limiter.Allow("client", policy)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed, "one token is refilled after 1/rate")
+}
+
+func TestMemoryTokenBucketBurst(t *testing.T) {
+	now := time.Unix(600, 0)
+	limiter := newTestLimiter(&now)
+	policy := Policy{Name: "test", Requests: 1, Period: time.Second, Burst: 3}
+
+	for i := 0; i < 3; i++ {
+		result, _ := limiter.Allow("client", policy)
+		assert.True(t, result.Allowed)
+	}
+
+	result, _ := limiter.Allow("client", policy)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+}
+
+func TestMemorySlidingWindow(t *testing.T) {
+	now := time.Unix(600, 0)
+	limiter := newTestLimiter(&now)
+	policy := Policy{Name: "test", Algorithm: SlidingWindow, Requests: 2, Period: time.Minute}
+
+	for i := 0; i < 2; i++ {
+		result, _ := limiter.Allow("client", policy)
+		assert.True(t, result.Allowed)
+	}
+
+	result, _ := limiter.Allow("client", policy)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Minute, result.RetryAfter)
+
+	// Na metade da janela seguinte a anterior pesa 2 * 0.5 = 1.
+	now = now.Add(90 * time.Second)
+
+	result, _ = limiter.Allow("client", policy)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	result, _ = limiter.Allow("client", policy)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 30*time.Second, result.RetryAfter)
+
+	// Duas janelas depois o contador recomeça.
+	now = now.Add(2 * time.Minute)
+
+	result, _ = limiter.Allow("client", policy)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, result.Remaining)
+}
+
+func TestPolicyWithDefaults(t *testing.T) {
+	policy := Policy{Requests: 10}.WithDefaults(Policy{Algorithm: SlidingWindow, Period: time.Hour})
+
+	assert.Equal(t, SlidingWindow, policy.Algorithm)
+	assert.Equal(t, time.Hour, policy.Period)
+	assert.Equal(t, 10, policy.Burst)
+	assert.NotNil(t, policy.Key)
+	assert.Equal(t, "10;w=3600", policy.Header())
+}
+
+func TestDefaultPolicy(t *testing.T) {
+	policy, err := DefaultPolicy(fakeEnv{})
+	require.NoError(t, err)
+	assert.True(t, policy.IsEmpty())
+	assert.Equal(t, TokenBucket, policy.Algorithm)
+	assert.Equal(t, time.Minute, policy.Period)
+
+	policy, err = DefaultPolicy(fakeEnv{
+		"RATE_LIMIT_REQUESTS":  "100",
+		"RATE_LIMIT_PERIOD":    "10s",
+		"RATE_LIMIT_ALGORITHM": "sliding_window",
+		"RATE_LIMIT_KEY":       "api_key",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "global", policy.Name)
+	assert.Equal(t, 100, policy.Requests)
+	assert.Equal(t, 10*time.Second, policy.Period)
+	assert.Equal(t, SlidingWindow, policy.Algorithm)
+
+	_, err = DefaultPolicy(fakeEnv{"RATE_LIMIT_ALGORITHM": "leaky_bucket"})
+	assert.Error(t, err)
+
+	_, err = DefaultPolicy(fakeEnv{"RATE_LIMIT_KEY": "cookie"})
+	assert.Error(t, err)
+
+	_, err = DefaultPolicy(fakeEnv{"RATE_LIMIT_TRUSTED_PROXIES": "-1"})
+	assert.Error(t, err)
+
+	// O limite global roda antes da autenticação das rotas.
+	_, err = DefaultPolicy(fakeEnv{"RATE_LIMIT_REQUESTS": "100", "RATE_LIMIT_KEY": "subject"})
+	assert.Error(t, err)
+
+	policy, err = DefaultPolicy(fakeEnv{"RATE_LIMIT_KEY": "subject"})
+	require.NoError(t, err)
+	assert.True(t, policy.IsEmpty())
+}
+
+func TestKeys(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.0.0.1:5000"
+	r.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.2")
+
+	assert.Equal(t, "ip:10.0.0.1", ByIP(0)(r))
+	assert.Equal(t, "ip:10.0.0.2", ByIP(1)(r))
+	assert.Equal(t, "ip:203.0.113.7", ByIP(2)(r))
+	assert.Equal(t, "ip:203.0.113.7", ByIP(3)(r))
+
+	// Endereços enviados pelo cliente ficam à esquerda dos acrescentados pelos proxies.
+	r.Header.Set("X-Forwarded-For", "198.51.100.1, 203.0.113.7")
+	r.Header.Add("X-Forwarded-For", "10.0.0.2")
+	assert.Equal(t, "ip:203.0.113.7", ByIP(2)(r))
+
+	assert.Empty(t, ByAPIKey("X-API-Key")(r))
+	r.Header.Set("X-API-Key", "secret")
+	key := ByAPIKey("X-API-Key")(r)
+	assert.Contains(t, key, "key:")
+	assert.NotContains(t, key, "secret")
+
+	assert.Empty(t, BySubject()(r))
+	r = r.WithContext(jwt.NewContext(context.Background(), jwt.Claims{"sub": "user-1"}))
+	assert.Equal(t, "sub:user-1", BySubject()(r))
+}
+
+func TestFactory(t *testing.T) {
+	params := FactoryParams{Env: fakeEnv{"RATE_LIMIT_STORE": "redis"}, Log: ditest.Logger{}, I18N: ditest.Translator{}}
+
+	// Sem o CacheModule, o redis cai para a memória.
+	limiter, err := Factory(params)
+	require.NoError(t, err)
+	assert.IsType(t, &MemoryLimiter{}, limiter)
+
+	params.Env = fakeEnv{"RATE_LIMIT_STORE": "etcd"}
+	_, err = Factory(params)
+	assert.Error(t, err)
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package ratelimit
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/caiomarcatti12/nanogo/pkg/cache"
+	"github.com/gomodule/redigo/redis"
+)
+
+// Os scripts usam o relógio do Redis, para que todas as réplicas concordem
+// sobre as janelas e a reposição das fichas.
+var tokenBucketScript = redis.NewScript(1, `
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local ttl = tonumber(ARGV[3])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or capacity
+local ts = tonumber(state[2]) or now
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], ttl)
+return {allowed, tostring(tokens)}
+`)
+
+var slidingWindowScript = redis.NewScript(1, `
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local window = math.floor(now / period)
+local elapsed = now - window * period
+local currentKey = KEYS[1] .. ':' .. window
+local previous = tonumber(redis.call('GET', KEYS[1] .. ':' .. (window - 1))) or 0
+local current = tonumber(redis.call('GET', currentKey)) or 0
+local allowed = 0
+if previous * (period - elapsed) / period + current + 1 <= limit then
+  current = redis.call('INCR', currentKey)
+  redis.call('PEXPIRE', currentKey, period * 2)
+  allowed = 1
+end
+return {allowed, previous, current, elapsed}
+`)
+
+// RedisLimiter guarda os contadores no Redis do pacote cache, de modo que o
+// limite vale para todas as réplicas.
+type RedisLimiter struct {
+	pool   cache.IRedisPool
+	prefix string
+}
+
+func NewRedisLimiter(pool cache.IRedisPool, prefix string) *RedisLimiter {
+	return &RedisLimiter{pool: pool, prefix: prefix}
+}
+
+func (l *RedisLimiter) Allow(key string, policy Policy) (Result, error) {
+	policy = policy.WithDefaults(Policy{})
+	key = l.prefix + policy.Name + ":" + key
+
+	conn := l.pool.Conn()
+	defer conn.Close()
+
+	if policy.Algorithm == SlidingWindow {
+		values, err := redis.Int64s(slidingWindowScript.Do(conn, key, policy.Requests, policy.Period.Milliseconds()))
+		if err != nil {
+			return Result{}, err
+		}
+
+		elapsed := time.Duration(values[3]) * time.Millisecond
+		return slidingWindowResult(policy, values[0] == 1, int(values[1]), int(values[2]), elapsed), nil
+	}
+
+	ttl := seconds(float64(policy.Burst) / policy.rate())
+	ratePerMillisecond := policy.rate() / 1000
+
+	values, err := redis.Values(tokenBucketScript.Do(conn, key, policy.Burst, ratePerMillisecond, max(ttl.Milliseconds(), 1)))
+	if err != nil {
+		return Result{}, err
+	}
+
+	allowed, err := redis.Int(values[0], nil)
+	if err != nil {
+		return Result{}, err
+	}
+
+	text, err := redis.String(values[1], nil)
+	if err != nil {
+		return Result{}, err
+	}
+
+	tokens, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return tokenBucketResult(policy, allowed == 1, tokens), nil
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package recovery
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/caiomarcatti12/nanogo/pkg/i18n"
+	"github.com/caiomarcatti12/nanogo/pkg/log"
+	"github.com/caiomarcatti12/nanogo/pkg/metric"
+)
+
+// PanicsMetric conta os panics recuperados, rotulados pelo componente
+// (http, websocket, queue ou event).
+const PanicsMetric = "panics_total"
+
+// PanicError é o erro devolvido no lugar de um panic recuperado.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Recoverer trata os panics de um componente: registra o stack trace (o
+// logger acrescenta o correlation ID), incrementa PanicsMetric e devolve o
+// panic como *PanicError, para que o chamador responda 500 ou faça Nack.
+// Deve ser usado a partir de um defer:
+//
+//	defer func() {
+//		if value := recover(); value != nil {
+//			err = recoverer.Recover(value)
+//		}
+//	}()
+type Recoverer struct {
+	component string
+	logger    log.ILog
+	i18n      i18n.I18N
+	metrics   metric.IMetric
+}
+
+// NewRecoverer cria o Recoverer do componente. metrics pode ser nil quando a
+// aplicação não registra métricas.
+func NewRecoverer(component string, logger log.ILog, i18n i18n.I18N, metrics metric.IMetric) *Recoverer {
+	if metrics != nil {
+		metrics.CreateMetric(metric.Counter, PanicsMetric, "Indicates quantity of panics recovered", metric.LabelsKeys{"component"})
+	}
+
+	return &Recoverer{
+		component: component,
+		logger:    logger,
+		i18n:      i18n,
+		metrics:   metrics,
+	}
+}
+
+func (r *Recoverer) Recover(value interface{}) error {
+	panicError := &PanicError{Value: value, Stack: debug.Stack()}
+
+	r.logger.Error(r.i18n.Get("recovery.panic_recovered", map[string]interface{}{"component": r.component, "error": value, "stack": string(panicError.Stack)}))
+
+	if r.metrics != nil {
+		r.metrics.IncrementCounter(PanicsMetric, metric.Labels{"component": r.component})
+	}
+
+	return panicError
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package recovery
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/caiomarcatti12/nanogo/pkg/di/ditest"
+	"github.com/caiomarcatti12/nanogo/pkg/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLog struct {
+	errors []string
+}
+
+func (l *fakeLog) Fatal(string, ...interface{}) {}
+func (l *fakeLog) Debug(string, ...interface{}) {}
+func (l *fakeLog) Info(string, ...interface{})  {}
+func (l *fakeLog) Error(message string, _ ...interface{}) {
+	l.errors = append(l.errors, message)
+}
+func (l *fakeLog) Warning(string, ...interface{})  {}
+func (l *fakeLog) Trace(string, ...interface{})    {}
+func (l *fakeLog) Fatalf(string, ...interface{})   {}
+func (l *fakeLog) Debugf(string, ...interface{})   {}
+func (l *fakeLog) Infof(string, ...interface{})    {}
+func (l *fakeLog) Errorf(string, ...interface{})   {}
+func (l *fakeLog) Warningf(string, ...interface{}) {}
+func (l *fakeLog) Tracef(string, ...interface{})   {}
+
+type fakeMetric struct {
+	created  []string
+	counters []metric.Labels
+}
+
+func (m *fakeMetric) CreateMetric(_ metric.MetricType, name, _ string, _ metric.LabelsKeys) {
+	m.created = append(m.created, name)
+}
+func (m *fakeMetric) IncrementCounter(_ string, labels metric.Labels) error {
+	m.counters = append(m.counters, labels)
+	return nil
+}
+func (m *fakeMetric) SetGauge(string, float64, metric.Labels) error         { return nil }
+func (m *fakeMetric) ObserveHistogram(string, float64, metric.Labels) error { return nil }
+func (m *fakeMetric) ObserveSummary(string, float64, metric.Labels) error   { return nil }
+
+func TestRecoverReturnsPanicAsError(t *testing.T) {
+	logger := &fakeLog{}
+	metrics := &fakeMetric{}
+	recoverer := NewRecoverer("queue", logger, ditest.Translator{}, metrics)
+
+	call := func() (err error) {
+		defer func() {
+			if value := recover(); value != nil {
+				err = recoverer.Recover(value)
+			}
+		}()
+
+		panic("boom")
+	}
+
+	err := call()
+
+	var panicError *PanicError
+	require.True(t, errors.As(err, &panicError))
+	assert.Equal(t, "boom", panicError.Value)
+	assert.Equal(t, "panic: boom", err.Error())
+	assert.Contains(t, string(panicError.Stack), "TestRecoverReturnsPanicAsError")
+
+	assert.Equal(t, []string{PanicsMetric}, metrics.created)
+	assert.Equal(t, []metric.Labels{{"component": "queue"}}, metrics.counters)
+
+	require.Len(t, logger.errors, 1)
+	assert.Contains(t, logger.errors[0], "recovery.panic_recovered component=queue error=boom stack=")
+}
+
+func TestRecoverWithoutMetrics(t *testing.T) {
+	recoverer := NewRecoverer("event", &fakeLog{}, ditest.Translator{}, nil)
+
+	assert.NotPanics(t, func() {
+		assert.Error(t, recoverer.Recover(fmt.Errorf("boom")))
+	})
+}
@@ -148,6 +148,15 @@ func (t *Telemetry) Shutdown() {
 	}
 }
 
+// OnStop flushes pending spans and shuts down the TracerProvider when the application stops.
+func (t *Telemetry) OnStop(ctx context.Context) error {
+	if t.tp == nil {
+		return nil
+	}
+
+	return t.tp.Shutdown(ctx)
+}
+
 func (t *Telemetry) extractAttributes(optionalAttrs ...interface{}) []attribute.KeyValue {
 	var attributes []attribute.KeyValue
 
@@ -17,6 +17,7 @@ package validator
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/caiomarcatti12/nanogo/pkg/errors"
@@ -40,30 +41,25 @@ func getValidatorInstance() *Validator {
 	return instance
 }
 
+// ValidateStruct retorna um CustomError 400 com todas as falhas de validação
+// em Errors; Message traz a primeira delas.
 func ValidateStruct(s interface{}) *errors.CustomError {
 	getValidatorInstance()
 
 	err := instance.validator.Struct(s)
 	if err != nil {
 		// Verifica se o erro é do tipo ValidationErrors
-		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok && len(validationErrors) > 0 {
+			fieldErrors := make([]errors.FieldError, 0, len(validationErrors))
+
 			for _, err := range validationErrors {
-				field := err.Field()
-				tag := err.Tag()
-
-				switch tag {
-				case "required":
-					return InvalidStructException(fmt.Sprintf("field %s is required", field))
-				case "email":
-					return InvalidStructException(fmt.Sprintf("field %s is not a valid email", field))
-				case "gte":
-					return InvalidStructException(fmt.Sprintf("field %s should be greater than or equal to %s", field, err.Param()))
-				case "lte":
-					return InvalidStructException(fmt.Sprintf("field %s should be less than or equal to %s", field, err.Param()))
-				default:
-					return InvalidStructException(fmt.Sprintf("field %s has invalid value", field))
-				}
+				fieldErrors = append(fieldErrors, fieldError(err))
 			}
+
+			customError := InvalidStructException(fieldErrors[0].Message)
+			customError.Errors = fieldErrors
+
+			return customError
 		} /* else if invalidValidationError, ok := err.(*validator.InvalidValidationError); ok {
 			return InvalidStructException(invalidValidationError.Error())
 		} else {
@@ -73,3 +69,34 @@ func ValidateStruct(s interface{}) *errors.CustomError {
 	}
 	return nil
 }
+
+func fieldError(err validator.FieldError) errors.FieldError {
+	field := err.Field()
+
+	// Namespace inclui o nome da struct raiz ("Request.Address.Street").
+	if _, namespace, found := strings.Cut(err.Namespace(), "."); found {
+		field = namespace
+	}
+
+	var message string
+
+	switch err.Tag() {
+	case "required":
+		message = fmt.Sprintf("field %s is required", field)
+	case "email":
+		message = fmt.Sprintf("field %s is not a valid email", field)
+	case "gte":
+		message = fmt.Sprintf("field %s should be greater than or equal to %s", field, err.Param())
+	case "lte":
+		message = fmt.Sprintf("field %s should be less than or equal to %s", field, err.Param())
+	default:
+		message = fmt.Sprintf("field %s has invalid value", field)
+	}
+
+	return errors.FieldError{
+		Field:   field,
+		Rule:    err.Tag(),
+		Param:   err.Param(),
+		Message: message,
+	}
+}
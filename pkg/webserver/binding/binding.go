@@ -0,0 +1,411 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_binding
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caiomarcatti12/nanogo/pkg/errors"
+	webserver_types "github.com/caiomarcatti12/nanogo/pkg/webserver/types"
+)
+
+// Tags que definem de onde vem o valor de um campo. O nome vem antes da
+// vírgula; em body, um nome vazio (`body:""`) recebe o corpo inteiro.
+const (
+	TagPath   = "path"
+	TagQuery  = "query"
+	TagHeader = "header"
+	TagForm   = "form"
+	TagBody   = "body"
+)
+
+// Sources lista as tags na ordem em que são procuradas em um campo.
+var Sources = []string{TagPath, TagQuery, TagHeader, TagForm, TagBody}
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+	fileUploadType      = reflect.TypeOf(webserver_types.FileUpload{})
+)
+
+// Request guarda as partes da requisição lidas pelo PayloadExtractorMiddleware.
+type Request struct {
+	Path   map[string]string
+	Query  url.Values
+	Header http.Header
+	Form   url.Values
+	Files  map[string][]*webserver_types.FileUpload
+	Body   map[string]interface{}
+}
+
+type contextKey struct{}
+
+// NewContext retorna um contexto com as partes da requisição.
+func NewContext(ctx context.Context, request Request) context.Context {
+	return context.WithValue(ctx, contextKey{}, request)
+}
+
+// FromContext retorna as partes gravadas pelo PayloadExtractorMiddleware.
+func FromContext(ctx context.Context) (Request, bool) {
+	request, ok := ctx.Value(contextKey{}).(Request)
+	return request, ok
+}
+
+// Source retorna a tag de origem do campo e o nome informado nela. O nome "-"
+// indica que o campo não deve ser vinculado.
+func Source(field reflect.StructField) (string, string, bool) {
+	for _, source := range Sources {
+		if tag, ok := field.Tag.Lookup(source); ok {
+			name, _, _ := strings.Cut(tag, ",")
+			return source, name, true
+		}
+	}
+
+	return "", "", false
+}
+
+// BindsPath informa se a struct t tem um campo que recebe a variável name do
+// caminho, pela tag path ou, sem tag, pelo nome Go.
+func BindsPath(t reflect.Type, name string) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		source, tagName, tagged := Source(field)
+
+		switch {
+		case tagged:
+			if source == TagPath && tagName == name {
+				return true
+			}
+		case field.Anonymous:
+			if BindsPath(field.Type, name) {
+				return true
+			}
+		case field.IsExported() && field.Name == name:
+			return true
+		}
+	}
+
+	return false
+}
+
+// Bind preenche a struct apontada por target. Campos com tag de origem leem
+// apenas dela e ficam zerados quando o valor não veio, de modo que o corpo não
+// sobrescreve um parâmetro do caminho. Campos sem tag são procurados pelo nome
+// Go no caminho, na query, no formulário e no corpo, nessa ordem.
+//
+// Textos são convertidos para o tipo do campo (números, booleanos, durações,
+// encoding.TextUnmarshaler como time.Time e uuid.UUID, e slices com os valores
+// repetidos). Falhas de conversão retornam um CustomError 400 com um item em
+// Errors por campo.
+func Bind(request Request, target interface{}) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	binder := &binder{request: request}
+	binder.bindStruct(value.Elem())
+
+	if len(binder.errors) == 0 {
+		return nil
+	}
+
+	return &errors.CustomError{
+		Code:    http.StatusBadRequest,
+		Message: binder.errors[0].Message,
+		Errors:  binder.errors,
+	}
+}
+
+type binder struct {
+	request Request
+	errors  []errors.FieldError
+}
+
+func (b *binder) bindStruct(value reflect.Value) {
+	structType := value.Type()
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		fieldValue := value.Field(i)
+
+		source, name, tagged := Source(field)
+
+		if field.Anonymous && !tagged {
+			if embedded, ok := b.embedded(fieldValue); ok {
+				b.bindStruct(embedded)
+			}
+			continue
+		}
+
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+
+		if tagged {
+			fieldValue.Set(reflect.Zero(field.Type))
+			b.bindSource(field, fieldValue, source, name)
+			continue
+		}
+
+		b.bindByName(field, fieldValue)
+	}
+}
+
+// embedded retorna a struct embutida, alocando o ponteiro quando necessário.
+func (b *binder) embedded(value reflect.Value) (reflect.Value, bool) {
+	if value.Kind() == reflect.Ptr && value.Type().Elem().Kind() == reflect.Struct {
+		if !value.CanSet() {
+			return reflect.Value{}, false
+		}
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		return value.Elem(), true
+	}
+
+	return value, value.Kind() == reflect.Struct
+}
+
+func (b *binder) bindSource(field reflect.StructField, value reflect.Value, source string, name string) {
+	switch source {
+	case TagPath:
+		if raw, ok := b.request.Path[name]; ok {
+			b.setStrings(field, value, source, name, []string{raw})
+		}
+	case TagQuery:
+		b.setStrings(field, value, source, name, b.request.Query[name])
+	case TagHeader:
+		b.setStrings(field, value, source, name, b.request.Header.Values(name))
+	case TagForm:
+		if files, ok := b.request.Files[name]; ok && b.setFiles(value, files) {
+			return
+		}
+		b.setStrings(field, value, source, name, b.request.Form[name])
+	case TagBody:
+		if name == "" {
+			if b.request.Body != nil {
+				b.setJSON(field, value, source, name, b.request.Body)
+			}
+			return
+		}
+
+		if raw, ok := b.request.Body[name]; ok {
+			b.setJSON(field, value, source, name, raw)
+		}
+	}
+}
+
+// bindByName mantém o vínculo dos campos sem tag, pelo nome Go do campo.
+func (b *binder) bindByName(field reflect.StructField, value reflect.Value) {
+	name := field.Name
+
+	if raw, ok := b.request.Path[name]; ok {
+		b.setStrings(field, value, TagPath, name, []string{raw})
+	} else if values, ok := b.request.Query[name]; ok {
+		b.setStrings(field, value, TagQuery, name, values)
+	} else if files, ok := b.request.Files[name]; ok && b.setFiles(value, files) {
+	} else if values, ok := b.request.Form[name]; ok {
+		b.setStrings(field, value, TagForm, name, values)
+	} else if raw, ok := b.request.Body[name]; ok {
+		b.setJSON(field, value, TagBody, name, raw)
+	}
+}
+
+// setFiles preenche campos FileUpload, *FileUpload e suas slices.
+func (b *binder) setFiles(value reflect.Value, files []*webserver_types.FileUpload) bool {
+	if len(files) == 0 {
+		return false
+	}
+
+	valueType := value.Type()
+
+	switch {
+	case valueType == fileUploadType:
+		value.Set(reflect.ValueOf(*files[0]))
+	case valueType == reflect.PtrTo(fileUploadType):
+		value.Set(reflect.ValueOf(files[0]))
+	case valueType.Kind() == reflect.Slice && valueType.Elem() == reflect.PtrTo(fileUploadType):
+		value.Set(reflect.ValueOf(files))
+	case valueType.Kind() == reflect.Slice && valueType.Elem() == fileUploadType:
+		slice := reflect.MakeSlice(valueType, len(files), len(files))
+		for i, file := range files {
+			slice.Index(i).Set(reflect.ValueOf(*file))
+		}
+		value.Set(slice)
+	default:
+		return false
+	}
+
+	return true
+}
+
+func (b *binder) setStrings(field reflect.StructField, value reflect.Value, source string, name string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+
+	target := value
+	if target.Kind() == reflect.Ptr && target.Type().Elem().Kind() == reflect.Slice {
+		target.Set(reflect.New(target.Type().Elem()))
+		target = target.Elem()
+	}
+
+	if target.Kind() == reflect.Slice && target.Type().Elem().Kind() != reflect.Uint8 && !reflect.PtrTo(target.Type()).Implements(textUnmarshalerType) {
+		slice := reflect.MakeSlice(target.Type(), len(values), len(values))
+		for i, raw := range values {
+			if err := convert(slice.Index(i), raw); err != nil {
+				b.fail(field, source, name, target.Type().Elem(), raw)
+				return
+			}
+		}
+		target.Set(slice)
+		return
+	}
+
+	if err := convert(target, values[0]); err != nil {
+		value.Set(reflect.Zero(value.Type()))
+		b.fail(field, source, name, target.Type(), values[0])
+	}
+}
+
+// setJSON converte um valor do corpo já decodificado para o tipo do campo.
+func (b *binder) setJSON(field reflect.StructField, value reflect.Value, source string, name string, raw interface{}) {
+	data, err := json.Marshal(raw)
+	if err == nil {
+		err = json.Unmarshal(data, value.Addr().Interface())
+	}
+
+	if err != nil {
+		value.Set(reflect.Zero(value.Type()))
+		b.fail(field, source, name, value.Type(), string(data))
+	}
+}
+
+func (b *binder) fail(field reflect.StructField, source string, name string, expected reflect.Type, raw string) {
+	if name == "" {
+		name = field.Name
+	}
+
+	b.errors = append(b.errors, errors.FieldError{
+		Field:   field.Name,
+		Rule:    "type",
+		Param:   typeName(expected),
+		Message: fmt.Sprintf("field %s (%s %s) must be a valid %s, got %q", field.Name, source, name, typeName(expected), raw),
+	})
+}
+
+// convert atribui raw a value conforme o tipo de value.
+func convert(value reflect.Value, raw string) error {
+	if value.Kind() == reflect.Ptr {
+		element := reflect.New(value.Type().Elem())
+		if err := convert(element.Elem(), raw); err != nil {
+			return err
+		}
+		value.Set(element)
+		return nil
+	}
+
+	if value.CanAddr() && value.Addr().Type().Implements(textUnmarshalerType) {
+		return value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw))
+	}
+
+	if value.Type() == durationType {
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(duration))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		value.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(raw, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(raw, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(parsed)
+	case reflect.Interface:
+		if value.NumMethod() > 0 {
+			return fmt.Errorf("cannot convert text to %s", value.Type())
+		}
+		value.Set(reflect.ValueOf(raw))
+	default:
+		// Structs, maps e []byte podem chegar como JSON na query ou no cabeçalho.
+		return json.Unmarshal([]byte(raw), value.Addr().Interface())
+	}
+
+	return nil
+}
+
+// typeName descreve o tipo esperado nas mensagens de erro.
+func typeName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if t == durationType {
+			return "duration"
+		}
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	}
+
+	return t.String()
+}
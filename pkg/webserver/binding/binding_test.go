@@ -0,0 +1,176 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_binding
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/caiomarcatti12/nanogo/pkg/errors"
+	webserver_types "github.com/caiomarcatti12/nanogo/pkg/webserver/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tagged struct {
+	ID      int                         `path:"id"`
+	Tags    []string                    `query:"tags"`
+	Limit   *int                        `query:"limit"`
+	Since   time.Time                   `query:"since"`
+	Timeout time.Duration               `query:"timeout"`
+	Active  bool                        `query:"active"`
+	Tenant  string                      `header:"X-Tenant"`
+	Name    string                      `body:"name"`
+	Ignored string                      `body:"-"`
+	Avatar  *webserver_types.FileUpload `form:"avatar"`
+	Notes   []string                    `form:"notes"`
+}
+
+type whole struct {
+	ID   string `path:"id"`
+	Data struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	} `body:""`
+}
+
+type Embedded struct {
+	Page int `query:"page"`
+}
+
+type untagged struct {
+	Embedded
+	ID   int
+	Name string
+}
+
+func TestBindTaggedSources(t *testing.T) {
+	avatar := &webserver_types.FileUpload{Filename: "a.png", Size: 3}
+	request := Request{
+		Path: map[string]string{"id": "42"},
+		Query: url.Values{
+			"tags":    {"a", "b"},
+			"limit":   {"10"},
+			"since":   {"2024-01-02T03:04:05Z"},
+			"timeout": {"1m30s"},
+			"active":  {"true"},
+		},
+		Header: http.Header{"X-Tenant": {"acme"}},
+		Form:   url.Values{"notes": {"x", "y"}},
+		Files:  map[string][]*webserver_types.FileUpload{"avatar": {avatar}},
+		Body:   map[string]interface{}{"name": "john", "ID": float64(7), "Ignored": "x"},
+	}
+
+	var target tagged
+	require.NoError(t, Bind(request, &target))
+
+	assert.Equal(t, 42, target.ID, "body must not overwrite the path param")
+	assert.Equal(t, []string{"a", "b"}, target.Tags)
+	require.NotNil(t, target.Limit)
+	assert.Equal(t, 10, *target.Limit)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), target.Since)
+	assert.Equal(t, 90*time.Second, target.Timeout)
+	assert.True(t, target.Active)
+	assert.Equal(t, "acme", target.Tenant)
+	assert.Equal(t, "john", target.Name)
+	assert.Empty(t, target.Ignored)
+	assert.Same(t, avatar, target.Avatar)
+	assert.Equal(t, []string{"x", "y"}, target.Notes)
+}
+
+func TestBindWholeBody(t *testing.T) {
+	request := Request{
+		Path: map[string]string{"id": "abc"},
+		Body: map[string]interface{}{"name": "john", "age": float64(30)},
+	}
+
+	var target whole
+	require.NoError(t, Bind(request, &target))
+
+	assert.Equal(t, "abc", target.ID)
+	assert.Equal(t, "john", target.Data.Name)
+	assert.Equal(t, 30, target.Data.Age)
+}
+
+func TestBindUntaggedByName(t *testing.T) {
+	request := Request{
+		Path:  map[string]string{"ID": "5"},
+		Query: url.Values{"page": {"2"}},
+		Body:  map[string]interface{}{"ID": float64(9), "Name": "john"},
+	}
+
+	var target untagged
+	require.NoError(t, Bind(request, &target))
+
+	assert.Equal(t, 5, target.ID, "path takes precedence over body")
+	assert.Equal(t, "john", target.Name)
+	assert.Equal(t, 2, target.Page)
+}
+
+func TestBindConversionErrors(t *testing.T) {
+	request := Request{
+		Path:  map[string]string{"id": "abc"},
+		Query: url.Values{"limit": {"ten"}, "tags": {"ok"}, "active": {"maybe"}},
+	}
+
+	var target tagged
+	err := Bind(request, &target)
+	require.Error(t, err)
+
+	customError, ok := err.(*errors.CustomError)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusBadRequest, customError.Code)
+	require.Len(t, customError.Errors, 3)
+	assert.Equal(t, customError.Errors[0].Message, customError.Message)
+
+	assert.Equal(t, "ID", customError.Errors[0].Field)
+	assert.Equal(t, "type", customError.Errors[0].Rule)
+	assert.Equal(t, "integer", customError.Errors[0].Param)
+	assert.Contains(t, customError.Errors[0].Message, `path id`)
+	assert.Contains(t, customError.Errors[0].Message, `"abc"`)
+
+	assert.Equal(t, "Limit", customError.Errors[1].Field)
+	assert.Nil(t, target.Limit)
+	assert.Equal(t, "Active", customError.Errors[2].Field)
+	assert.Equal(t, "boolean", customError.Errors[2].Param)
+}
+
+func TestBindResetsTaggedFieldsWithoutSource(t *testing.T) {
+	target := tagged{ID: 1, Tenant: "stale"}
+	require.NoError(t, Bind(Request{}, &target))
+
+	assert.Zero(t, target.ID)
+	assert.Empty(t, target.Tenant)
+}
+
+func TestBindIgnoresNonStructTargets(t *testing.T) {
+	values := map[string]interface{}{}
+	assert.NoError(t, Bind(Request{}, &values))
+	assert.NoError(t, Bind(Request{}, tagged{}))
+}
+
+func TestContext(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	assert.False(t, ok)
+
+	request := Request{Path: map[string]string{"id": "1"}}
+	stored, ok := FromContext(NewContext(context.Background(), request))
+	require.True(t, ok)
+	assert.Equal(t, request, stored)
+}
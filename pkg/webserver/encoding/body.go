@@ -0,0 +1,43 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_encoding
+
+import "context"
+
+type contextKey struct{}
+
+// Body é o corpo bruto da requisição e o codec do seu Content-Type. Serve aos
+// destinos que não passam pelo map de payload, como mensagens protobuf.
+type Body struct {
+	Codec ICodec
+	Data  []byte
+}
+
+// Decode lê o corpo em v com o codec da requisição.
+func (b Body) Decode(v interface{}) error {
+	return b.Codec.Decode(b.Data, v)
+}
+
+// NewContext retorna um contexto com o corpo lido pelo PayloadExtractorMiddleware.
+func NewContext(ctx context.Context, body Body) context.Context {
+	return context.WithValue(ctx, contextKey{}, body)
+}
+
+// FromContext retorna o corpo gravado pelo PayloadExtractorMiddleware.
+func FromContext(ctx context.Context) (Body, bool) {
+	body, ok := ctx.Value(contextKey{}).(Body)
+	return body, ok
+}
@@ -0,0 +1,250 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_encoding
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrUnsupported indica que o codec não sabe codificar ou decodificar o valor
+// informado, ex.: protobuf para um map.
+var ErrUnsupported = errors.New("encoding: unsupported value")
+
+// ErrTooDeep indica um documento com mais de MaxDepth níveis de aninhamento.
+var ErrTooDeep = errors.New("encoding: document nested too deeply")
+
+// MaxDepth limita o aninhamento lido pelos decodificadores recursivos. Sem o
+// limite, um corpo com milhões de níveis estoura a pilha, falha que o recover
+// não captura. É o mesmo limite do encoding/json.
+const MaxDepth = 10000
+
+// ICodec serializa respostas e desserializa corpos de requisição de um
+// Content-Type.
+type ICodec interface {
+	ContentType() string
+	// CanEncode informa se o valor pode ser escrito neste formato; a
+	// negociação pula os codecs que não servem para a resposta.
+	CanEncode(v interface{}) bool
+	Encode(w io.Writer, v interface{}) error
+	// Decode aceita um *map[string]interface{}, usado pelo
+	// PayloadExtractorMiddleware, ou um ponteiro para o tipo de destino.
+	Decode(data []byte, v interface{}) error
+}
+
+// Registry guarda os codecs disponíveis para a negociação de conteúdo.
+type Registry struct {
+	codecs []ICodec
+	mu     sync.RWMutex
+}
+
+// NewRegistry cria o registro com os codecs padrão. Sem Accept, ou com
+// empate, a ordem de registro decide, então o JSON vem primeiro.
+func NewRegistry() *Registry {
+	return &Registry{
+		codecs: []ICodec{
+			NewJSONCodec(),
+			NewXMLCodec(),
+			NewMessagePackCodec(),
+			NewProtobufCodec(),
+			NewCSVCodec(),
+		},
+	}
+}
+
+// Register adiciona um codec ou substitui o de mesmo Content-Type.
+func (r *Registry) Register(codec ICodec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, registered := range r.codecs {
+		if registered.ContentType() == codec.ContentType() {
+			r.codecs[i] = codec
+			return
+		}
+	}
+
+	r.codecs = append(r.codecs, codec)
+}
+
+// ContentTypes retorna os Content-Types registrados, na ordem de preferência.
+func (r *Registry) ContentTypes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	contentTypes := make([]string, len(r.codecs))
+	for i, codec := range r.codecs {
+		contentTypes[i] = codec.ContentType()
+	}
+
+	return contentTypes
+}
+
+// Lookup retorna o codec do Content-Type informado, ignorando parâmetros como
+// charset. Sufixos estruturados (application/problem+json,
+// application/vnd.api+xml) usam o codec do formato base.
+func (r *Registry) Lookup(contentType string) (ICodec, bool) {
+	mediaType := parseMediaType(contentType)
+	if mediaType == "" {
+		return nil, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, codec := range r.codecs {
+		if codec.ContentType() == mediaType {
+			return codec, true
+		}
+	}
+
+	if index := strings.LastIndex(mediaType, "+"); index >= 0 {
+		suffix := mediaType[index+1:]
+		for _, codec := range r.codecs {
+			if strings.HasSuffix(codec.ContentType(), "/"+suffix) {
+				return codec, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// Negotiate escolhe, pelo cabeçalho Accept, o codec de maior qualidade capaz
+// de codificar v. Entre qualidades iguais vence a faixa mais específica
+// (application/xml antes de */*) e depois a ordem de registro. Sem Accept
+// qualquer formato é aceito.
+func (r *Registry) Negotiate(accept string, v interface{}) (ICodec, bool) {
+	ranges := parseAccept(accept)
+	if len(ranges) == 0 {
+		ranges = []acceptRange{{value: "*/*", quality: 1}}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var (
+		chosen      ICodec
+		quality     float64
+		specificity int
+	)
+
+	for _, codec := range r.codecs {
+		match, ok := bestMatch(ranges, codec.ContentType(), mediaTypeMatch)
+		if !ok || match.quality <= 0 || !codec.CanEncode(v) {
+			continue
+		}
+
+		matchSpecificity := mediaTypeSpecificity(match.value)
+		if chosen == nil || match.quality > quality || (match.quality == quality && matchSpecificity > specificity) {
+			chosen, quality, specificity = codec, match.quality, matchSpecificity
+		}
+	}
+
+	return chosen, chosen != nil
+}
+
+// acceptRange é um item de Accept ou Accept-Encoding com o seu parâmetro q.
+type acceptRange struct {
+	value   string
+	quality float64
+}
+
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+
+	for _, part := range strings.Split(header, ",") {
+		value, params, _ := strings.Cut(part, ";")
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range strings.Split(params, ";") {
+			name, raw, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || strings.ToLower(strings.TrimSpace(name)) != "q" {
+				continue
+			}
+
+			if parsed, err := strconv.ParseFloat(strings.TrimSpace(raw), 64); err == nil {
+				quality = parsed
+			}
+		}
+
+		ranges = append(ranges, acceptRange{value: value, quality: quality})
+	}
+
+	return ranges
+}
+
+// bestMatch retorna a faixa mais específica que casa com value, pois é ela que
+// define a qualidade (RFC 9110, seção 12.5.1).
+func bestMatch(ranges []acceptRange, value string, match func(string, string) bool) (acceptRange, bool) {
+	candidates := make([]acceptRange, 0, len(ranges))
+	for _, candidate := range ranges {
+		if match(candidate.value, value) {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return acceptRange{}, false
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return mediaTypeSpecificity(candidates[i].value) > mediaTypeSpecificity(candidates[j].value)
+	})
+
+	return candidates[0], true
+}
+
+func mediaTypeMatch(pattern string, mediaType string) bool {
+	if pattern == "*/*" || pattern == "*" || pattern == mediaType {
+		return true
+	}
+
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return strings.HasPrefix(mediaType, prefix+"/")
+	}
+
+	return false
+}
+
+func mediaTypeSpecificity(pattern string) int {
+	switch {
+	case pattern == "*/*" || pattern == "*":
+		return 0
+	case strings.HasSuffix(pattern, "/*"):
+		return 1
+	default:
+		return 2
+	}
+}
+
+func parseMediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, _, _ = strings.Cut(contentType, ";")
+	}
+
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
@@ -0,0 +1,230 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_encoding
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// ICompressor comprime respostas em uma Content-Encoding.
+type ICompressor interface {
+	Encoding() string
+	// NewWriter retorna o writer que comprime para w. Close conclui o corpo,
+	// mas não fecha w. Se o writer tiver Flush() error, ele é usado ao
+	// transmitir respostas aos poucos.
+	NewWriter(w io.Writer) io.WriteCloser
+}
+
+// Compressors guarda as compressões disponíveis para Accept-Encoding.
+type Compressors struct {
+	compressors []ICompressor
+	mu          sync.RWMutex
+}
+
+// NewCompressors cria o registro com zstd, brotli, gzip e deflate, nessa ordem
+// de preferência.
+func NewCompressors() *Compressors {
+	return &Compressors{
+		compressors: []ICompressor{
+			NewZstdCompressor(),
+			NewBrotliCompressor(brotli.DefaultCompression),
+			NewGzipCompressor(gzip.DefaultCompression),
+			NewDeflateCompressor(flate.DefaultCompression),
+		},
+	}
+}
+
+// Register adiciona uma compressão, ou substitui a de mesma Content-Encoding.
+// As novas têm preferência sobre as padrão em caso de empate no q.
+func (c *Compressors) Register(compressor ICompressor) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, registered := range c.compressors {
+		if registered.Encoding() == compressor.Encoding() {
+			c.compressors[i] = compressor
+			return
+		}
+	}
+
+	c.compressors = append([]ICompressor{compressor}, c.compressors...)
+}
+
+// Negotiate escolhe a compressão de maior q em Accept-Encoding. Sem o
+// cabeçalho, ou sem nenhuma aceita, a resposta segue sem compressão.
+func (c *Compressors) Negotiate(acceptEncoding string) (ICompressor, bool) {
+	ranges := parseAccept(acceptEncoding)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var (
+		chosen  ICompressor
+		quality float64
+	)
+
+	for _, compressor := range c.compressors {
+		match, ok := bestMatch(ranges, compressor.Encoding(), func(pattern string, encoding string) bool {
+			return pattern == "*" || pattern == encoding
+		})
+		if !ok || match.quality <= 0 {
+			continue
+		}
+
+		if chosen == nil || match.quality > quality {
+			chosen, quality = compressor, match.quality
+		}
+	}
+
+	return chosen, chosen != nil
+}
+
+// pooledWriter devolve o writer ao pool no Close, evitando alocar os buffers
+// da compressão a cada resposta.
+type pooledWriter struct {
+	writer interface {
+		io.WriteCloser
+		Flush() error
+	}
+	pool *sync.Pool
+}
+
+func (p *pooledWriter) Write(data []byte) (int, error) {
+	return p.writer.Write(data)
+}
+
+func (p *pooledWriter) Flush() error {
+	return p.writer.Flush()
+}
+
+func (p *pooledWriter) Close() error {
+	err := p.writer.Close()
+	p.pool.Put(p.writer)
+
+	return err
+}
+
+type GzipCompressor struct {
+	pool sync.Pool
+}
+
+func NewGzipCompressor(level int) *GzipCompressor {
+	compressor := &GzipCompressor{}
+	compressor.pool.New = func() interface{} {
+		writer, err := gzip.NewWriterLevel(nil, level)
+		if err != nil {
+			writer = gzip.NewWriter(nil)
+		}
+		return writer
+	}
+
+	return compressor
+}
+
+func (c *GzipCompressor) Encoding() string {
+	return "gzip"
+}
+
+func (c *GzipCompressor) NewWriter(w io.Writer) io.WriteCloser {
+	writer := c.pool.Get().(*gzip.Writer)
+	writer.Reset(w)
+
+	return &pooledWriter{writer: writer, pool: &c.pool}
+}
+
+type BrotliCompressor struct {
+	pool sync.Pool
+}
+
+func NewBrotliCompressor(level int) *BrotliCompressor {
+	compressor := &BrotliCompressor{}
+	compressor.pool.New = func() interface{} {
+		return brotli.NewWriterLevel(nil, level)
+	}
+
+	return compressor
+}
+
+func (c *BrotliCompressor) Encoding() string {
+	return "br"
+}
+
+func (c *BrotliCompressor) NewWriter(w io.Writer) io.WriteCloser {
+	writer := c.pool.Get().(*brotli.Writer)
+	writer.Reset(w)
+
+	return &pooledWriter{writer: writer, pool: &c.pool}
+}
+
+type DeflateCompressor struct {
+	pool sync.Pool
+}
+
+func NewDeflateCompressor(level int) *DeflateCompressor {
+	compressor := &DeflateCompressor{}
+	compressor.pool.New = func() interface{} {
+		writer, err := flate.NewWriter(nil, level)
+		if err != nil {
+			writer, _ = flate.NewWriter(nil, flate.DefaultCompression)
+		}
+		return writer
+	}
+
+	return compressor
+}
+
+func (c *DeflateCompressor) Encoding() string {
+	return "deflate"
+}
+
+func (c *DeflateCompressor) NewWriter(w io.Writer) io.WriteCloser {
+	writer := c.pool.Get().(*flate.Writer)
+	writer.Reset(w)
+
+	return &pooledWriter{writer: writer, pool: &c.pool}
+}
+
+type ZstdCompressor struct {
+	pool sync.Pool
+}
+
+func NewZstdCompressor() *ZstdCompressor {
+	compressor := &ZstdCompressor{}
+	compressor.pool.New = func() interface{} {
+		// Uma goroutine por encoder: cada resposta já roda na sua.
+		writer, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		return writer
+	}
+
+	return compressor
+}
+
+func (c *ZstdCompressor) Encoding() string {
+	return "zstd"
+}
+
+func (c *ZstdCompressor) NewWriter(w io.Writer) io.WriteCloser {
+	writer := c.pool.Get().(*zstd.Encoder)
+	writer.Reset(w)
+
+	return &pooledWriter{writer: writer, pool: &c.pool}
+}
@@ -0,0 +1,204 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_encoding
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// CSVCodec escreve slices como CSV, com uma linha de cabeçalho. Em slices de
+// structs as colunas seguem a ordem dos campos e os nomes da tag json; em
+// slices de maps, a ordem alfabética das chaves. Valores aninhados são
+// escritos como JSON na célula.
+type CSVCodec struct{}
+
+func NewCSVCodec() *CSVCodec {
+	return &CSVCodec{}
+}
+
+func (c *CSVCodec) ContentType() string {
+	return "text/csv"
+}
+
+func (c *CSVCodec) CanEncode(v interface{}) bool {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	return (value.Kind() == reflect.Slice && value.Type().Elem().Kind() != reflect.Uint8) || value.Kind() == reflect.Array
+}
+
+func (c *CSVCodec) Encode(w io.Writer, v interface{}) error {
+	if !c.CanEncode(v) {
+		return ErrUnsupported
+	}
+
+	generic, err := toGeneric(v)
+	if err != nil {
+		return err
+	}
+
+	rows, _ := generic.([]interface{})
+	header := csvHeader(reflect.TypeOf(v), rows)
+
+	writer := csv.NewWriter(w)
+
+	if header != nil {
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+	}
+
+	for _, row := range rows {
+		var record []string
+
+		switch typed := row.(type) {
+		case map[string]interface{}:
+			record = make([]string, len(header))
+			for i, column := range header {
+				record[i] = csvCell(typed[column])
+			}
+		case []interface{}:
+			record = make([]string, len(typed))
+			for i, cell := range typed {
+				record[i] = csvCell(cell)
+			}
+		default:
+			record = []string{csvCell(typed)}
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// Decode lê um CSV com cabeçalho em uma lista de objetos. Como o corpo não é
+// um objeto, não pode ser lido em um map.
+func (c *CSVCodec) Decode(data []byte, v interface{}) error {
+	if _, ok := v.(*map[string]interface{}); ok {
+		return ErrUnsupported
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return err
+	}
+
+	rows := make([]interface{}, 0, len(records))
+	if len(records) > 1 {
+		header := records[0]
+		for _, record := range records[1:] {
+			row := make(map[string]interface{}, len(header))
+			for i, column := range header {
+				if i < len(record) {
+					row[column] = record[i]
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	return fromGeneric(rows, v)
+}
+
+// csvHeader usa os campos do tipo dos elementos quando é uma struct; senão,
+// as chaves de todos os maps. Listas de listas e de valores simples não têm
+// cabeçalho.
+func csvHeader(sliceType reflect.Type, rows []interface{}) []string {
+	for sliceType.Kind() == reflect.Ptr {
+		sliceType = sliceType.Elem()
+	}
+
+	elementType := sliceType.Elem()
+	for elementType.Kind() == reflect.Ptr {
+		elementType = elementType.Elem()
+	}
+
+	if elementType.Kind() == reflect.Struct {
+		if header := structColumns(elementType); len(header) > 0 {
+			return header
+		}
+	}
+
+	columns := make(map[string]bool)
+	for _, row := range rows {
+		values, ok := row.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		for key := range values {
+			columns[key] = true
+		}
+	}
+
+	header := make([]string, 0, len(columns))
+	for column := range columns {
+		header = append(header, column)
+	}
+	sort.Strings(header)
+
+	return header
+}
+
+func structColumns(structType reflect.Type) []string {
+	var columns []string
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		columns = append(columns, name)
+	}
+
+	return columns
+}
+
+func csvCell(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return typed
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(typed)
+		return string(data)
+	default:
+		return fmt.Sprint(typed)
+	}
+}
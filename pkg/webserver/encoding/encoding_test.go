@@ -0,0 +1,292 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_encoding
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type user struct {
+	ID     int    `json:"id" xml:"id"`
+	Name   string `json:"name" xml:"name"`
+	Secret string `json:"-" xml:"-"`
+}
+
+func TestNegotiate(t *testing.T) {
+	registry := NewRegistry()
+
+	tests := []struct {
+		accept   string
+		value    interface{}
+		expected string
+	}{
+		{"", user{}, "application/json"},
+		{"*/*", user{}, "application/json"},
+		{"application/xml, */*", user{}, "application/xml"},
+		{"application/json;q=0.5, application/msgpack", user{}, "application/msgpack"},
+		{"text/*", []user{}, "text/csv"},
+		{"application/x-protobuf, application/json;q=0.1", user{}, "application/json"},
+		{"application/x-protobuf, application/json;q=0.1", wrapperspb.String("x"), "application/x-protobuf"},
+		{"application/*;q=0.2, application/xml;q=0, */*;q=0.1", user{}, "application/json"},
+	}
+
+	for _, test := range tests {
+		codec, ok := registry.Negotiate(test.accept, test.value)
+		require.True(t, ok, test.accept)
+		assert.Equal(t, test.expected, codec.ContentType(), test.accept)
+	}
+
+	_, ok := registry.Negotiate("text/csv", user{})
+	assert.False(t, ok, "CSV only represents lists")
+
+	_, ok = registry.Negotiate("image/png", user{})
+	assert.False(t, ok)
+}
+
+func TestLookup(t *testing.T) {
+	registry := NewRegistry()
+
+	codec, ok := registry.Lookup("application/json; charset=utf-8")
+	require.True(t, ok)
+	assert.Equal(t, "application/json", codec.ContentType())
+
+	codec, ok = registry.Lookup("application/problem+json")
+	require.True(t, ok)
+	assert.Equal(t, "application/json", codec.ContentType())
+
+	codec, ok = registry.Lookup("application/vnd.api+xml")
+	require.True(t, ok)
+	assert.Equal(t, "application/xml", codec.ContentType())
+
+	_, ok = registry.Lookup("application/x-www-form-urlencoded")
+	assert.False(t, ok)
+}
+
+func TestRegisterReplacesCodec(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(NewJSONCodec())
+	registry.Register(&CSVCodec{})
+
+	assert.Equal(t, []string{"application/json", "application/xml", "application/msgpack", "application/x-protobuf", "text/csv"}, registry.ContentTypes())
+}
+
+func TestXMLCodec(t *testing.T) {
+	codec := NewXMLCodec()
+
+	var buffer bytes.Buffer
+	require.NoError(t, codec.Encode(&buffer, &user{ID: 1, Name: "Ana"}))
+	assert.Contains(t, buffer.String(), "<user><id>1</id><name>Ana</name></user>")
+
+	buffer.Reset()
+	require.NoError(t, codec.Encode(&buffer, map[string]interface{}{"tags": []string{"a", "b"}, "total": 2}))
+	assert.Contains(t, buffer.String(), "<response><tags><item>a</item><item>b</item></tags><total>2</total></response>")
+
+	payload := map[string]interface{}{"id": "from-route"}
+	err := codec.Decode([]byte(`<user><Name>Ana</Name><Age>30</Age><Active>true</Active><Tag>a</Tag><Tag>b</Tag><Address><City>Recife</City></Address></user>`), &payload)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"id":      "from-route",
+		"Name":    "Ana",
+		"Age":     "30",
+		"Active":  "true",
+		"Tag":     []interface{}{"a", "b"},
+		"Address": map[string]interface{}{"City": "Recife"},
+	}, payload)
+}
+
+func TestXMLCodec_RejectsInvalidElementNames(t *testing.T) {
+	codec := NewXMLCodec()
+
+	for _, name := range []string{"x><evil/", "", "1st", "a b", "ns:name", "a&b"} {
+		var buffer bytes.Buffer
+
+		assert.Error(t, codec.Encode(&buffer, map[string]interface{}{name: 1}), name)
+		assert.NotContains(t, buffer.String(), "<evil/>")
+	}
+}
+
+func TestXMLCodec_RejectsDeepNesting(t *testing.T) {
+	depth := MaxDepth + 1
+	document := strings.Repeat("<a>", depth) + strings.Repeat("</a>", depth)
+
+	payload := map[string]interface{}{}
+	assert.ErrorIs(t, NewXMLCodec().Decode([]byte(document), &payload), ErrTooDeep)
+
+	document = strings.Repeat("<a>", MaxDepth) + strings.Repeat("</a>", MaxDepth)
+	assert.NoError(t, NewXMLCodec().Decode([]byte(document), &payload))
+}
+
+func TestMessagePackCodec_RejectsDeepNesting(t *testing.T) {
+	codec := NewMessagePackCodec()
+	payload := map[string]interface{}{}
+
+	// 5 MB de fixarray com um único elemento: um nível por byte.
+	assert.ErrorIs(t, codec.Decode(bytes.Repeat([]byte{0x91}, 5<<20), &payload), ErrTooDeep)
+
+	nested := append(bytes.Repeat([]byte{0x91}, MaxDepth-1), 0x80)
+	var decoded []interface{}
+	assert.NoError(t, codec.Decode(nested, &decoded))
+}
+
+func TestMessagePackCodec(t *testing.T) {
+	codec := NewMessagePackCodec()
+
+	var buffer bytes.Buffer
+	require.NoError(t, codec.Encode(&buffer, map[string]interface{}{"a": 1, "b": []interface{}{true, nil, -5, 1.5, "x"}}))
+	assert.Equal(t, []byte{
+		0x82,
+		0xa1, 'a', 0x01,
+		0xa1, 'b', 0x95, 0xc3, 0xc0, 0xfb, 0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0, 0xa1, 'x',
+	}, buffer.Bytes())
+
+	buffer.Reset()
+	original := []user{{ID: 300, Name: "Ana"}, {ID: -200, Name: "Bia"}}
+	require.NoError(t, codec.Encode(&buffer, original))
+
+	var decoded []user
+	require.NoError(t, codec.Decode(buffer.Bytes(), &decoded))
+	assert.Equal(t, original, decoded)
+
+	payload := map[string]interface{}{}
+	require.NoError(t, codec.Decode([]byte{0x81, 0xa4, 'N', 'a', 'm', 'e', 0xa3, 'A', 'n', 'a'}, &payload))
+	assert.Equal(t, map[string]interface{}{"Name": "Ana"}, payload)
+
+	assert.Error(t, codec.Decode([]byte{0x92, 0x01}, &decoded), "truncated array")
+	assert.Error(t, codec.Decode([]byte{0xdd, 0xff, 0xff, 0xff, 0xff}, &decoded), "forged length")
+}
+
+func TestCSVCodec(t *testing.T) {
+	codec := NewCSVCodec()
+
+	var buffer bytes.Buffer
+	require.NoError(t, codec.Encode(&buffer, []user{{ID: 1, Name: "Ana, Maria"}, {ID: 2, Name: "Bia"}}))
+	assert.Equal(t, "id,name\n1,\"Ana, Maria\"\n2,Bia\n", buffer.String())
+
+	buffer.Reset()
+	require.NoError(t, codec.Encode(&buffer, []map[string]interface{}{{"b": 1, "a": map[string]int{"x": 1}}, {"c": true}}))
+	assert.Equal(t, "a,b,c\n\"{\"\"x\"\":1}\",1,\n,,true\n", buffer.String())
+
+	var decoded []map[string]string
+	require.NoError(t, codec.Decode([]byte("id,name\n1,Ana\n"), &decoded))
+	assert.Equal(t, []map[string]string{{"id": "1", "name": "Ana"}}, decoded)
+
+	payload := map[string]interface{}{}
+	assert.ErrorIs(t, codec.Decode([]byte("id\n1\n"), &payload), ErrUnsupported)
+}
+
+func TestProtobufCodec(t *testing.T) {
+	codec := NewProtobufCodec()
+
+	var buffer bytes.Buffer
+	require.NoError(t, codec.Encode(&buffer, wrapperspb.String("nanogo")))
+
+	message := &wrapperspb.StringValue{}
+	require.NoError(t, codec.Decode(buffer.Bytes(), message))
+	assert.Equal(t, "nanogo", message.GetValue())
+
+	assert.ErrorIs(t, codec.Encode(&buffer, user{}), ErrUnsupported)
+
+	buffer.Reset()
+	require.NoError(t, NewJSONCodec().Encode(&buffer, wrapperspb.String("nanogo")))
+	assert.Equal(t, "\"nanogo\"\n", buffer.String())
+}
+
+func TestCompressorsNegotiate(t *testing.T) {
+	compressors := NewCompressors()
+
+	tests := map[string]string{
+		"gzip":                    "gzip",
+		"gzip, deflate, zstd":     "zstd",
+		"gzip;q=1, zstd;q=0.5":    "gzip",
+		"*":                       "zstd",
+		"*, zstd;q=0":             "br",
+		"deflate, gzip;q=0.9, br": "br",
+		"br;q=0.5, gzip":          "gzip",
+		"br, gzip, deflate":       "br",
+	}
+
+	for header, expected := range tests {
+		compressor, ok := compressors.Negotiate(header)
+		require.True(t, ok, header)
+		assert.Equal(t, expected, compressor.Encoding(), header)
+	}
+
+	for _, header := range []string{"", "identity", "compress", "gzip;q=0"} {
+		_, ok := compressors.Negotiate(header)
+		assert.False(t, ok, header)
+	}
+}
+
+type fakeCompressor struct{}
+
+func (fakeCompressor) Encoding() string { return "xz" }
+
+func (fakeCompressor) NewWriter(w io.Writer) io.WriteCloser { return nil }
+
+func TestRegisteredCompressorIsPreferred(t *testing.T) {
+	compressors := NewCompressors()
+	compressors.Register(fakeCompressor{})
+
+	compressor, ok := compressors.Negotiate("gzip, deflate, br, xz, zstd")
+	require.True(t, ok)
+	assert.Equal(t, "xz", compressor.Encoding())
+}
+
+func TestCompressorsRoundTrip(t *testing.T) {
+	content := bytes.Repeat([]byte("nanogo "), 100)
+
+	var buffer bytes.Buffer
+	writer := NewGzipCompressor(gzip.BestSpeed).NewWriter(&buffer)
+	writer.Write(content)
+	require.NoError(t, writer.Close())
+
+	reader, err := gzip.NewReader(&buffer)
+	require.NoError(t, err)
+	decoded, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, content, decoded)
+
+	buffer.Reset()
+	writer = NewZstdCompressor().NewWriter(&buffer)
+	writer.Write(content)
+	require.NoError(t, writer.Close())
+
+	decoder, err := zstd.NewReader(&buffer)
+	require.NoError(t, err)
+	defer decoder.Close()
+	decoded, err = io.ReadAll(decoder)
+	require.NoError(t, err)
+	assert.Equal(t, content, decoded)
+
+	buffer.Reset()
+	writer = NewBrotliCompressor(brotli.BestSpeed).NewWriter(&buffer)
+	writer.Write(content)
+	require.NoError(t, writer.Close())
+
+	decoded, err = io.ReadAll(brotli.NewReader(&buffer))
+	require.NoError(t, err)
+	assert.Equal(t, content, decoded)
+}
@@ -0,0 +1,110 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_encoding
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// JSONCodec usa encoding/json, ou protojson para mensagens protobuf.
+type JSONCodec struct{}
+
+func NewJSONCodec() *JSONCodec {
+	return &JSONCodec{}
+}
+
+func (c *JSONCodec) ContentType() string {
+	return "application/json"
+}
+
+func (c *JSONCodec) CanEncode(v interface{}) bool {
+	return true
+}
+
+func (c *JSONCodec) Encode(w io.Writer, v interface{}) error {
+	if message, ok := v.(proto.Message); ok {
+		data, err := protojson.Marshal(message)
+		if err != nil {
+			return err
+		}
+
+		_, err = w.Write(append(data, '\n'))
+		return err
+	}
+
+	return json.NewEncoder(w).Encode(v)
+}
+
+func (c *JSONCodec) Decode(data []byte, v interface{}) error {
+	if message, ok := v.(proto.Message); ok {
+		return protojson.Unmarshal(data, message)
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// toGeneric converte v em maps, slices, json.Number, strings, bools e nil
+// passando pelo JSON. Assim os formatos sem suporte a structs usam os mesmos
+// nomes de campo (tag json) das respostas JSON.
+func toGeneric(v interface{}) (interface{}, error) {
+	var buffer bytes.Buffer
+	if err := NewJSONCodec().Encode(&buffer, v); err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(&buffer)
+	decoder.UseNumber()
+
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+
+	return generic, nil
+}
+
+// fromGeneric preenche v a partir do valor genérico. Um *map[string]interface{}
+// recebe as chaves diretamente; os demais destinos passam pelo JSON.
+func fromGeneric(generic interface{}, v interface{}) error {
+	if target, ok := v.(*map[string]interface{}); ok {
+		values, ok := generic.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("encoding: cannot decode %T into a map", generic)
+		}
+
+		if *target == nil {
+			*target = make(map[string]interface{}, len(values))
+		}
+		for key, value := range values {
+			(*target)[key] = value
+		}
+
+		return nil
+	}
+
+	data, err := json.Marshal(generic)
+	if err != nil {
+		return err
+	}
+
+	return NewJSONCodec().Decode(data, v)
+}
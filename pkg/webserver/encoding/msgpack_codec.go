@@ -0,0 +1,389 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_encoding
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+var errMessagePackTruncated = errors.New("msgpack: unexpected end of data")
+
+// MessagePackCodec escreve e lê MessagePack. Os valores passam pelo JSON
+// antes de serem codificados, então os campos seguem a tag json; []byte
+// chegam como string base64, do mesmo jeito que no JSON.
+type MessagePackCodec struct{}
+
+func NewMessagePackCodec() *MessagePackCodec {
+	return &MessagePackCodec{}
+}
+
+func (c *MessagePackCodec) ContentType() string {
+	return "application/msgpack"
+}
+
+func (c *MessagePackCodec) CanEncode(v interface{}) bool {
+	return true
+}
+
+func (c *MessagePackCodec) Encode(w io.Writer, v interface{}) error {
+	generic, err := toGeneric(v)
+	if err != nil {
+		return err
+	}
+
+	var buffer bytes.Buffer
+	if err := writeMessagePack(&buffer, generic); err != nil {
+		return err
+	}
+
+	_, err = w.Write(buffer.Bytes())
+	return err
+}
+
+func (c *MessagePackCodec) Decode(data []byte, v interface{}) error {
+	reader := &messagePackReader{data: data}
+
+	generic, err := reader.read()
+	if err != nil {
+		return err
+	}
+
+	if reader.offset != len(data) {
+		return errors.New("msgpack: unexpected data after the value")
+	}
+
+	return fromGeneric(generic, v)
+}
+
+func writeMessagePack(buffer *bytes.Buffer, value interface{}) error {
+	switch typed := value.(type) {
+	case nil:
+		buffer.WriteByte(0xc0)
+	case bool:
+		if typed {
+			buffer.WriteByte(0xc3)
+		} else {
+			buffer.WriteByte(0xc2)
+		}
+	case json.Number:
+		if integer, err := typed.Int64(); err == nil {
+			writeMessagePackInt(buffer, integer)
+			return nil
+		}
+
+		float, err := typed.Float64()
+		if err != nil {
+			return err
+		}
+
+		buffer.WriteByte(0xcb)
+		binary.Write(buffer, binary.BigEndian, math.Float64bits(float))
+	case string:
+		writeMessagePackHeader(buffer, len(typed), 0xa0, 32, 0xd9, 0xda, 0xdb)
+		buffer.WriteString(typed)
+	case []interface{}:
+		writeMessagePackHeader(buffer, len(typed), 0x90, 16, 0, 0xdc, 0xdd)
+		for _, item := range typed {
+			if err := writeMessagePack(buffer, item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		writeMessagePackHeader(buffer, len(typed), 0x80, 16, 0, 0xde, 0xdf)
+		for _, key := range keys {
+			writeMessagePack(buffer, key)
+			if err := writeMessagePack(buffer, typed[key]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack: unsupported type %T", value)
+	}
+
+	return nil
+}
+
+func writeMessagePackInt(buffer *bytes.Buffer, value int64) {
+	switch {
+	case value >= 0 && value <= math.MaxInt8:
+		buffer.WriteByte(byte(value))
+	case value >= -32 && value < 0:
+		buffer.WriteByte(byte(int8(value)))
+	case value >= 0 && value <= math.MaxUint8:
+		buffer.WriteByte(0xcc)
+		buffer.WriteByte(byte(value))
+	case value >= 0 && value <= math.MaxUint16:
+		buffer.WriteByte(0xcd)
+		binary.Write(buffer, binary.BigEndian, uint16(value))
+	case value >= 0 && value <= math.MaxUint32:
+		buffer.WriteByte(0xce)
+		binary.Write(buffer, binary.BigEndian, uint32(value))
+	case value >= 0:
+		buffer.WriteByte(0xcf)
+		binary.Write(buffer, binary.BigEndian, uint64(value))
+	case value >= math.MinInt8:
+		buffer.WriteByte(0xd0)
+		buffer.WriteByte(byte(int8(value)))
+	case value >= math.MinInt16:
+		buffer.WriteByte(0xd1)
+		binary.Write(buffer, binary.BigEndian, int16(value))
+	case value >= math.MinInt32:
+		buffer.WriteByte(0xd2)
+		binary.Write(buffer, binary.BigEndian, int32(value))
+	default:
+		buffer.WriteByte(0xd3)
+		binary.Write(buffer, binary.BigEndian, value)
+	}
+}
+
+// writeMessagePackHeader escreve o tamanho de strings, arrays e maps no menor
+// formato: fixo (abaixo de fixLimit), 8, 16 ou 32 bits. format8 zero indica que
+// o tipo não tem a variante de 8 bits.
+func writeMessagePackHeader(buffer *bytes.Buffer, length int, fixPrefix byte, fixLimit int, format8 byte, format16 byte, format32 byte) {
+	switch {
+	case length < fixLimit:
+		buffer.WriteByte(fixPrefix | byte(length))
+	case format8 != 0 && length <= math.MaxUint8:
+		buffer.WriteByte(format8)
+		buffer.WriteByte(byte(length))
+	case length <= math.MaxUint16:
+		buffer.WriteByte(format16)
+		binary.Write(buffer, binary.BigEndian, uint16(length))
+	default:
+		buffer.WriteByte(format32)
+		binary.Write(buffer, binary.BigEndian, uint32(length))
+	}
+}
+
+// messagePackReader lê um valor MessagePack em maps, slices, int64, uint64,
+// float64, string, []byte, bool e nil. Extensões não são suportadas.
+type messagePackReader struct {
+	data   []byte
+	offset int
+	depth  int
+}
+
+func (r *messagePackReader) next(size int) ([]byte, error) {
+	if size < 0 || r.offset+size > len(r.data) {
+		return nil, errMessagePackTruncated
+	}
+
+	chunk := r.data[r.offset : r.offset+size]
+	r.offset += size
+
+	return chunk, nil
+}
+
+func (r *messagePackReader) uint(size int) (uint64, error) {
+	chunk, err := r.next(size)
+	if err != nil {
+		return 0, err
+	}
+
+	var value uint64
+	for _, b := range chunk {
+		value = value<<8 | uint64(b)
+	}
+
+	return value, nil
+}
+
+func (r *messagePackReader) read() (interface{}, error) {
+	prefix, err := r.uint(1)
+	if err != nil {
+		return nil, err
+	}
+
+	b := byte(prefix)
+
+	switch {
+	case b <= 0x7f:
+		return int64(b), nil
+	case b >= 0xe0:
+		return int64(int8(b)), nil
+	case b&0xe0 == 0xa0:
+		return r.string(int(b & 0x1f))
+	case b&0xf0 == 0x90:
+		return r.array(int(b & 0x0f))
+	case b&0xf0 == 0x80:
+		return r.object(int(b & 0x0f))
+	}
+
+	switch b {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		length, err := r.uint(1 << (b - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+
+		chunk, err := r.next(int(length))
+		if err != nil {
+			return nil, err
+		}
+
+		return append([]byte(nil), chunk...), nil
+	case 0xca:
+		bits, err := r.uint(4)
+		return float64(math.Float32frombits(uint32(bits))), err
+	case 0xcb:
+		bits, err := r.uint(8)
+		return math.Float64frombits(bits), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		value, err := r.uint(1 << (b - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+
+		if value > math.MaxInt64 {
+			return value, nil
+		}
+
+		return int64(value), nil
+	case 0xd0:
+		value, err := r.uint(1)
+		return int64(int8(value)), err
+	case 0xd1:
+		value, err := r.uint(2)
+		return int64(int16(value)), err
+	case 0xd2:
+		value, err := r.uint(4)
+		return int64(int32(value)), err
+	case 0xd3:
+		value, err := r.uint(8)
+		return int64(value), err
+	case 0xd9, 0xda, 0xdb:
+		length, err := r.uint(1 << (b - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+
+		return r.string(int(length))
+	case 0xdc, 0xdd:
+		length, err := r.uint(2 << (b - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+
+		return r.array(int(length))
+	case 0xde, 0xdf:
+		length, err := r.uint(2 << (b - 0xde))
+		if err != nil {
+			return nil, err
+		}
+
+		return r.object(int(length))
+	}
+
+	return nil, fmt.Errorf("msgpack: unsupported format 0x%02x", b)
+}
+
+func (r *messagePackReader) string(length int) (interface{}, error) {
+	chunk, err := r.next(length)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(chunk), nil
+}
+
+// enter conta um nível de aninhamento; chame leave ao sair dele.
+func (r *messagePackReader) enter() error {
+	r.depth++
+	if r.depth > MaxDepth {
+		return ErrTooDeep
+	}
+
+	return nil
+}
+
+func (r *messagePackReader) leave() {
+	r.depth--
+}
+
+func (r *messagePackReader) array(length int) (interface{}, error) {
+	if err := r.enter(); err != nil {
+		return nil, err
+	}
+	defer r.leave()
+
+	// Cada item ocupa ao menos um byte; evita alocar a partir de um tamanho
+	// forjado.
+	if length > len(r.data)-r.offset {
+		return nil, errMessagePackTruncated
+	}
+
+	items := make([]interface{}, length)
+	for i := range items {
+		item, err := r.read()
+		if err != nil {
+			return nil, err
+		}
+		items[i] = item
+	}
+
+	return items, nil
+}
+
+func (r *messagePackReader) object(length int) (interface{}, error) {
+	if err := r.enter(); err != nil {
+		return nil, err
+	}
+	defer r.leave()
+
+	if length > len(r.data)-r.offset {
+		return nil, errMessagePackTruncated
+	}
+
+	values := make(map[string]interface{}, length)
+	for i := 0; i < length; i++ {
+		key, err := r.read()
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := r.read()
+		if err != nil {
+			return nil, err
+		}
+
+		name, ok := key.(string)
+		if !ok {
+			name = fmt.Sprint(key)
+		}
+		values[name] = value
+	}
+
+	return values, nil
+}
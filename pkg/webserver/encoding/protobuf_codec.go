@@ -0,0 +1,63 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_encoding
+
+import (
+	"io"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ProtobufCodec escreve e lê mensagens protobuf no formato binário. Serve
+// apenas para valores que implementam proto.Message.
+type ProtobufCodec struct{}
+
+func NewProtobufCodec() *ProtobufCodec {
+	return &ProtobufCodec{}
+}
+
+func (c *ProtobufCodec) ContentType() string {
+	return "application/x-protobuf"
+}
+
+func (c *ProtobufCodec) CanEncode(v interface{}) bool {
+	_, ok := v.(proto.Message)
+	return ok
+}
+
+func (c *ProtobufCodec) Encode(w io.Writer, v interface{}) error {
+	message, ok := v.(proto.Message)
+	if !ok {
+		return ErrUnsupported
+	}
+
+	data, err := proto.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+func (c *ProtobufCodec) Decode(data []byte, v interface{}) error {
+	message, ok := v.(proto.Message)
+	if !ok {
+		return ErrUnsupported
+	}
+
+	return proto.Unmarshal(data, message)
+}
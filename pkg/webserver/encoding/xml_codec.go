@@ -0,0 +1,209 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_encoding
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// XMLCodec usa encoding/xml para structs, respeitando as tags xml. Maps,
+// slices e valores simples são escritos sob o elemento <response>, com os
+// nomes da tag json e um <item> por elemento de slice. Chaves que não são
+// nomes XML válidos fazem o Encode falhar, em vez de gerar marcação.
+type XMLCodec struct{}
+
+func NewXMLCodec() *XMLCodec {
+	return &XMLCodec{}
+}
+
+func (c *XMLCodec) ContentType() string {
+	return "application/xml"
+}
+
+func (c *XMLCodec) CanEncode(v interface{}) bool {
+	return true
+}
+
+func (c *XMLCodec) Encode(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	if value.Kind() == reflect.Struct {
+		return xml.NewEncoder(w).Encode(v)
+	}
+
+	generic, err := toGeneric(v)
+	if err != nil {
+		return err
+	}
+
+	var buffer bytes.Buffer
+	if err := writeXMLElement(&buffer, "response", generic); err != nil {
+		return err
+	}
+
+	_, err = w.Write(buffer.Bytes())
+	return err
+}
+
+// Decode lê o documento em v com encoding/xml. Num *map[string]interface{},
+// os filhos do elemento raiz viram chaves; elementos repetidos viram listas e
+// os textos ficam como string, sem adivinhar o tipo, como nas demais origens do
+// payload. Documentos com mais de MaxDepth níveis retornam ErrTooDeep.
+func (c *XMLCodec) Decode(data []byte, v interface{}) error {
+	if _, ok := v.(*map[string]interface{}); !ok {
+		return xml.Unmarshal(data, v)
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			root, err := readXMLElement(decoder, start, 1)
+			if err != nil {
+				return err
+			}
+
+			if _, ok := root.(map[string]interface{}); !ok {
+				root = map[string]interface{}{}
+			}
+
+			return fromGeneric(root, v)
+		}
+	}
+}
+
+func writeXMLElement(buffer *bytes.Buffer, name string, value interface{}) error {
+	if !isXMLName(name) {
+		return fmt.Errorf("xml: %q is not a valid element name", name)
+	}
+
+	buffer.WriteString("<" + name + ">")
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if err := writeXMLElement(buffer, key, typed[key]); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, item := range typed {
+			if err := writeXMLElement(buffer, "item", item); err != nil {
+				return err
+			}
+		}
+	case nil:
+	default:
+		xml.EscapeText(buffer, []byte(fmt.Sprint(typed)))
+	}
+
+	buffer.WriteString("</" + name + ">")
+
+	return nil
+}
+
+// isXMLName informa se name pode ser usado como nome de elemento: começa com
+// letra ou "_" e segue com letras, dígitos, "-", "_" ou ".". Prefixos de
+// namespace (":") não são aceitos.
+func isXMLName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i, char := range name {
+		switch {
+		case unicode.IsLetter(char) || char == '_':
+		case i > 0 && (unicode.IsDigit(char) || char == '-' || char == '.'):
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func readXMLElement(decoder *xml.Decoder, start xml.StartElement, depth int) (interface{}, error) {
+	if depth > MaxDepth {
+		return nil, ErrTooDeep
+	}
+
+	var (
+		text     strings.Builder
+		children map[string]interface{}
+	)
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch typed := token.(type) {
+		case xml.StartElement:
+			child, err := readXMLElement(decoder, typed, depth+1)
+			if err != nil {
+				return nil, err
+			}
+
+			if children == nil {
+				children = make(map[string]interface{})
+			}
+
+			name := typed.Name.Local
+			switch existing := children[name].(type) {
+			case nil:
+				children[name] = child
+			case []interface{}:
+				children[name] = append(existing, child)
+			default:
+				children[name] = []interface{}{existing, child}
+			}
+		case xml.CharData:
+			text.Write(typed)
+		case xml.EndElement:
+			if children != nil {
+				return children, nil
+			}
+
+			return strings.TrimSpace(text.String()), nil
+		}
+	}
+}
@@ -16,19 +16,32 @@
 package webserver
 
 import (
+	"github.com/caiomarcatti12/nanogo/pkg/authz"
 	"github.com/caiomarcatti12/nanogo/pkg/context_manager"
 	"github.com/caiomarcatti12/nanogo/pkg/di"
 	"github.com/caiomarcatti12/nanogo/pkg/env"
 	"github.com/caiomarcatti12/nanogo/pkg/i18n"
 	"github.com/caiomarcatti12/nanogo/pkg/log"
+	"github.com/caiomarcatti12/nanogo/pkg/metric"
+	"github.com/caiomarcatti12/nanogo/pkg/ratelimit"
 	"github.com/caiomarcatti12/nanogo/pkg/telemetry"
 )
 
-func Factory(env env.IEnv,
-	logger log.ILog,
-	i18n i18n.I18N,
-	diContainer di.IContainer,
-	telemetry telemetry.ITelemetry,
-	contextManager context_manager.ISafeContextManager) IWebServer {
-	return newWebServer(env, logger, i18n, diContainer, telemetry, contextManager)
+// FactoryParams são as dependências de Factory. Metrics é opcional: sem o
+// MetricModule as requisições não são contadas.
+type FactoryParams struct {
+	di.In
+	Env            env.IEnv
+	Log            log.ILog
+	I18N           i18n.I18N
+	Container      di.IContainer
+	Telemetry      telemetry.ITelemetry
+	ContextManager context_manager.ISafeContextManager
+	Authorizer     authz.IAuthorizer
+	Limiter        ratelimit.IRateLimiter
+	Metrics        metric.IMetric `optional:"true"`
+}
+
+func Factory(params FactoryParams) (IWebServer, error) {
+	return newWebServer(params.Env, params.Log, params.I18N, params.Container, params.Telemetry, params.ContextManager, params.Metrics, params.Authorizer, params.Limiter)
 }
@@ -0,0 +1,255 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/caiomarcatti12/nanogo/pkg/ratelimit"
+	webserver_binding "github.com/caiomarcatti12/nanogo/pkg/webserver/binding"
+	webserver_middleware "github.com/caiomarcatti12/nanogo/pkg/webserver/middleware"
+	webserver_types "github.com/caiomarcatti12/nanogo/pkg/webserver/types"
+	"github.com/gorilla/mux"
+)
+
+// RouteOption completa a rota registrada com Handle.
+type RouteOption func(route *webserver_types.Route)
+
+func WithName(name string) RouteOption {
+	return func(route *webserver_types.Route) { route.Name = name }
+}
+
+func WithSummary(summary string) RouteOption {
+	return func(route *webserver_types.Route) { route.Summary = summary }
+}
+
+func WithTags(tags ...string) RouteOption {
+	return func(route *webserver_types.Route) { route.Tags = append(route.Tags, tags...) }
+}
+
+func WithRoles(roles ...string) RouteOption {
+	return func(route *webserver_types.Route) { route.Roles = append(route.Roles, roles...) }
+}
+
+func WithScopes(scopes ...string) RouteOption {
+	return func(route *webserver_types.Route) { route.Scopes = append(route.Scopes, scopes...) }
+}
+
+func WithMiddlewares(middlewares ...webserver_middleware.IMiddleware) RouteOption {
+	return func(route *webserver_types.Route) { route.Middlewares = append(route.Middlewares, middlewares...) }
+}
+
+func WithSkipMiddlewares(names ...string) RouteOption {
+	return func(route *webserver_types.Route) { route.SkipMiddlewares = append(route.SkipMiddlewares, names...) }
+}
+
+func WithRateLimit(policy ratelimit.Policy) RouteOption {
+	return func(route *webserver_types.Route) { route.RateLimit = &policy }
+}
+
+func WithUpload(policy webserver_types.UploadPolicy) RouteOption {
+	return func(route *webserver_types.Route) { route.Upload = &policy }
+}
+
+// typedRoute é uma rota registrada com Handle: call recebe o Req já vinculado
+// e validado e chama a função tipada.
+type typedRoute struct {
+	route    webserver_types.Route
+	request  reflect.Type
+	response reflect.Type
+	call     func(w http.ResponseWriter, r *http.Request, payload reflect.Value) (interface{}, error)
+}
+
+// Handle registra uma rota tipada no servidor ou em um grupo. Req é vinculado
+// e validado a partir do caminho, da query, dos cabeçalhos, do corpo e das
+// claims do token, como os parâmetros das rotas por reflexão, e Resp é escrito
+// no formato negociado. Um tipo types.Response controla status e cabeçalhos.
+//
+// Erros de configuração, como um Req que não é struct ou uma variável do
+// caminho sem campo correspondente em Req, causam panic no registro em vez de
+// falhar na primeira requisição.
+func Handle[Req any, Resp any](router IRouteGroup, method string, path string, handler func(ctx webserver_types.HandlerContext[Req]) (Resp, error), options ...RouteOption) {
+	route := webserver_types.Route{Method: method, Path: path}
+	for _, option := range options {
+		option(&route)
+	}
+
+	typed := typedRoute{
+		route:    route,
+		request:  reflect.TypeOf((*Req)(nil)).Elem(),
+		response: reflect.TypeOf((*Resp)(nil)).Elem(),
+		call: func(w http.ResponseWriter, r *http.Request, payload reflect.Value) (interface{}, error) {
+			response, err := handler(webserver_types.HandlerContext[Req]{
+				Payload:  payload.Interface().(Req),
+				RawQuery: r.URL.RawQuery,
+				Headers:  r.Header,
+				Request:  r,
+				Response: w,
+			})
+
+			return nilIfEmpty(response), err
+		},
+	}
+
+	var (
+		ws     *WebServer
+		parent *mux.Router
+		prefix string
+	)
+
+	switch target := router.(type) {
+	case *WebServer:
+		ws, parent = target, target.router
+	case *RouteGroup:
+		ws, parent, prefix = target.ws, target.router, target.prefix
+	default:
+		panic(errors.New("webserver: Handle requires a router created by the webserver"))
+	}
+
+	if handler == nil {
+		ws.invalidRoute(route, prefix, ws.i18n.Get("webserver.typed_handler_nil"))
+	}
+
+	ws.addTypedRoute(parent, prefix, typed)
+}
+
+func (ws *WebServer) addTypedRoute(router *mux.Router, prefix string, typed typedRoute) {
+	route := typed.route
+
+	ws.logger.Trace(ws.i18n.Get("webserver.add_route", map[string]interface{}{"method": route.Method, "path": prefix + route.Path}))
+
+	if err := ws.checkTypedRoute(prefix, typed); err != "" {
+		ws.invalidRoute(route, prefix, err)
+	}
+
+	var output reflect.Type
+	if typed.response.Kind() != reflect.Interface || typed.response.NumMethod() > 0 {
+		output = typed.response
+	}
+
+	ws.mount(router, prefix, route, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws.logger.Trace(ws.i18n.Get("webserver.execute_handler", map[string]interface{}{"method": r.Method, "path": r.URL.Path}))
+
+		data, err := ws.callTyped(w, r, prefix, typed, ws.payload(w, r))
+
+		ws.respond(w, r, data, err)
+	}), []reflect.Type{typed.request}, output)
+}
+
+func (ws *WebServer) callTyped(w http.ResponseWriter, r *http.Request, prefix string, typed typedRoute, contextPayload map[string]interface{}) (response interface{}, err error) {
+	span := ws.telemetry.StartChildSpan(typed.route.Method + " " + prefix + typed.route.Path)
+	defer (func() { ws.telemetry.EndSpan(span, err) })()
+
+	// Executado antes do EndSpan, para que o span registre o panic como falha.
+	defer ws.recoverPanic(&response, &err)
+
+	payload, err := ws.bindPayload(r, typed.request, contextPayload, r.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	return typed.call(w, r, payload)
+}
+
+// checkTypedRoute retorna a mensagem do erro de configuração da rota, ou
+// vazio quando ela é válida.
+func (ws *WebServer) checkTypedRoute(prefix string, typed typedRoute) string {
+	route := typed.route
+
+	if route.Method == "" || strings.ContainsAny(route.Method, " \t/") {
+		return ws.i18n.Get("webserver.typed_invalid_method", map[string]interface{}{"method": route.Method})
+	}
+
+	if path := prefix + route.Path; !strings.HasPrefix(path, "/") {
+		return ws.i18n.Get("webserver.typed_invalid_path", map[string]interface{}{"path": path})
+	}
+
+	if err := mux.NewRouter().Handle(prefix+route.Path, http.NotFoundHandler()).GetError(); err != nil {
+		return err.Error()
+	}
+
+	request := typed.request
+
+	if request.Kind() == reflect.Ptr && request.Implements(protoMessageType) {
+		return ""
+	}
+
+	if request.Kind() != reflect.Struct {
+		return ws.i18n.Get("webserver.typed_request_not_struct", map[string]interface{}{"type": request.String()})
+	}
+
+	// Sem um campo que receba a variável do caminho, o valor seria descartado
+	// em silêncio.
+	for _, variable := range pathVariables(prefix + route.Path) {
+		if !webserver_binding.BindsPath(request, variable) {
+			return ws.i18n.Get("webserver.typed_path_variable_not_bound", map[string]interface{}{"variable": variable, "type": request.String()})
+		}
+	}
+
+	return ""
+}
+
+func (ws *WebServer) invalidRoute(route webserver_types.Route, prefix string, reason string) {
+	panic(errors.New(ws.i18n.Get("webserver.typed_invalid_route", map[string]interface{}{"method": route.Method, "path": prefix + route.Path, "error": reason})))
+}
+
+// pathVariables retorna os nomes das variáveis do template, ex.: "id" em
+// "/users/{id:[0-9]{3}}". As chaves da expressão regular são balanceadas.
+func pathVariables(path string) []string {
+	var (
+		variables []string
+		depth     int
+		start     int
+	)
+
+	for i, char := range path {
+		switch char {
+		case '{':
+			if depth == 0 {
+				start = i + 1
+			}
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				name, _, _ := strings.Cut(path[start:i], ":")
+				variables = append(variables, strings.TrimSpace(name))
+			}
+		}
+	}
+
+	return variables
+}
+
+// nilIfEmpty converte ponteiros, maps, slices e interfaces nulos em nil, para
+// que a resposta siga sem corpo, como nas rotas por reflexão.
+func nilIfEmpty(value interface{}) interface{} {
+	reflected := reflect.ValueOf(value)
+
+	switch reflected.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func:
+		if reflected.IsNil() {
+			return nil
+		}
+	}
+
+	return value
+}
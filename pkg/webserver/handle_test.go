@@ -0,0 +1,223 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/caiomarcatti12/nanogo/pkg/types"
+	webserver_types "github.com/caiomarcatti12/nanogo/pkg/webserver/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type updateUserRequest struct {
+	ID     int      `path:"id"`
+	Tags   []string `query:"tag"`
+	Tenant string   `header:"X-Tenant"`
+	Body   struct {
+		Name  string `json:"name" validate:"required"`
+		Email string `json:"email" validate:"omitempty,email"`
+	} `body:""`
+}
+
+type updateUserResponse struct {
+	ID     int      `json:"id"`
+	Tags   []string `json:"tags"`
+	Tenant string   `json:"tenant"`
+	Name   string   `json:"name"`
+}
+
+// registrationPanic retorna a mensagem do panic de fn, ou vazio se não houve panic.
+func registrationPanic(fn func()) (message string) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			message = fmt.Sprint(recovered)
+		}
+	}()
+
+	fn()
+
+	return ""
+}
+
+func TestHandle_PanicsOnInvalidRoute(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(ws *WebServer)
+		reason   string
+	}{
+		{
+			name:     "empty method",
+			register: func(ws *WebServer) { Handle(ws, "", "/users", ok) },
+			reason:   "webserver.typed_invalid_method",
+		},
+		{
+			name:     "method with spaces",
+			register: func(ws *WebServer) { Handle(ws, "GET /users", "/users", ok) },
+			reason:   "webserver.typed_invalid_method",
+		},
+		{
+			name:     "empty path",
+			register: func(ws *WebServer) { Handle(ws, http.MethodGet, "", ok) },
+			reason:   "webserver.typed_invalid_path",
+		},
+		{
+			name:     "path without leading slash",
+			register: func(ws *WebServer) { Handle(ws, http.MethodGet, "users", ok) },
+			reason:   "webserver.typed_invalid_path",
+		},
+		{
+			name:     "unbalanced path",
+			register: func(ws *WebServer) { Handle(ws, http.MethodGet, "/users/{id", ok) },
+			reason:   "unbalanced braces",
+		},
+		{
+			name: "request is not a struct",
+			register: func(ws *WebServer) {
+				Handle(ws, http.MethodGet, "/users", func(webserver_types.HandlerContext[string]) (string, error) { return "", nil })
+			},
+			reason: "webserver.typed_request_not_struct",
+		},
+		{
+			name:     "path variable without field",
+			register: func(ws *WebServer) { Handle(ws, http.MethodGet, "/users/{id}", ok) },
+			reason:   "webserver.typed_path_variable_not_bound",
+		},
+		{
+			name:     "path variable without field in group prefix",
+			register: func(ws *WebServer) { Handle(ws.Group("/tenants/{tenant}"), http.MethodGet, "/users/{id}", ok) },
+			reason:   "webserver.typed_path_variable_not_bound",
+		},
+		{
+			name: "nil handler",
+			register: func(ws *WebServer) {
+				Handle[empty, string](ws, http.MethodGet, "/users", nil)
+			},
+			reason: "webserver.typed_handler_nil",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := newTestWebServer(t, testEnv{})
+
+			message := registrationPanic(func() { tt.register(ws) })
+
+			assert.Contains(t, message, "webserver.typed_invalid_route")
+			assert.Contains(t, message, tt.reason)
+		})
+	}
+}
+
+func TestHandle_AcceptsValidRoute(t *testing.T) {
+	ws := newTestWebServer(t, testEnv{})
+
+	assert.Empty(t, registrationPanic(func() {
+		Handle(ws.Group("/api"), http.MethodPut, "/users/{id:[0-9]+}", func(webserver_types.HandlerContext[updateUserRequest]) (string, error) { return "", nil })
+	}))
+}
+
+func TestHandle_BindsValidatesAndSerializes(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		header   map[string]string
+		body     string
+		status   int
+		expected *updateUserResponse
+	}{
+		{
+			name:     "binds path, query, header and body",
+			path:     "/users/7?tag=a&tag=b",
+			header:   map[string]string{"X-Tenant": "acme", "Content-Type": "application/json"},
+			body:     `{"name":"Ana","email":"ana@example.com"}`,
+			status:   http.StatusOK,
+			expected: &updateUserResponse{ID: 7, Tags: []string{"a", "b"}, Tenant: "acme", Name: "Ana"},
+		},
+		{
+			name:   "missing required field",
+			path:   "/users/7",
+			header: map[string]string{"Content-Type": "application/json"},
+			body:   `{"email":"ana@example.com"}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "invalid field",
+			path:   "/users/7",
+			header: map[string]string{"Content-Type": "application/json"},
+			body:   `{"name":"Ana","email":"not-an-email"}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "path value of the wrong type",
+			path:   "/users/abc",
+			header: map[string]string{"Content-Type": "application/json"},
+			body:   `{"name":"Ana"}`,
+			status: http.StatusBadRequest,
+		},
+	}
+
+	ws := newTestWebServer(t, testEnv{})
+
+	Handle(ws, http.MethodPut, "/users/{id}", func(ctx webserver_types.HandlerContext[updateUserRequest]) (updateUserResponse, error) {
+		return updateUserResponse{ID: ctx.Payload.ID, Tags: ctx.Payload.Tags, Tenant: ctx.Payload.Tenant, Name: ctx.Payload.Body.Name}, nil
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, tt.path, strings.NewReader(tt.body))
+			for key, value := range tt.header {
+				r.Header.Set(key, value)
+			}
+
+			recorder := serve(ws, r)
+
+			require.Equal(t, tt.status, recorder.Code, recorder.Body.String())
+
+			if tt.expected != nil {
+				assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+				var response updateUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				assert.Equal(t, *tt.expected, response)
+			}
+		})
+	}
+}
+
+func TestHandle_WritesResponseStatusAndHeaders(t *testing.T) {
+	ws := newTestWebServer(t, testEnv{})
+
+	Handle(ws, http.MethodPost, "/users", func(webserver_types.HandlerContext[empty]) (types.Response, error) {
+		return types.Response{
+			StatusCode: http.StatusCreated,
+			Headers:    map[string]string{"Location": "/users/7"},
+			Data:       map[string]int{"id": 7},
+		}, nil
+	})
+
+	recorder := serve(ws, httptest.NewRequest(http.MethodPost, "/users", nil))
+
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Equal(t, "/users/7", recorder.Header().Get("Location"))
+	assert.JSONEq(t, `{"id":7}`, recorder.Body.String())
+}
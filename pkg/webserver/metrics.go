@@ -0,0 +1,77 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/caiomarcatti12/nanogo/pkg/env"
+	webserver_route "github.com/caiomarcatti12/nanogo/pkg/webserver/routes"
+)
+
+// serveMetrics publica as métricas do Prometheus no roteador principal ou,
+// com WEB_SERVER_METRICS_PORT, em um servidor separado iniciado pelo Start.
+func (ws *WebServer) serveMetrics(env env.IEnv) {
+	if !env.GetEnvBool("WEB_SERVER_METRICS_ENABLED", "true") {
+		return
+	}
+
+	path := env.GetEnv("WEB_SERVER_METRICS_PATH", "/metrics")
+	handler := webserver_route.MetricsHandler(env.GetEnv("PROMETHEUS_TOKEN", ""))
+	port := env.GetEnv("WEB_SERVER_METRICS_PORT", "")
+
+	if port == "" {
+		ws.router.Handle(path, handler).Methods(http.MethodGet)
+		return
+	}
+
+	router := http.NewServeMux()
+	router.Handle(path, handler)
+
+	ws.metricsServer = &http.Server{
+		Addr:              fmt.Sprintf("%s:%s", ws.host, port),
+		Handler:           router,
+		ReadHeaderTimeout: ws.timeouts.readHeader,
+		ReadTimeout:       ws.timeouts.read,
+		WriteTimeout:      ws.timeouts.write,
+		IdleTimeout:       ws.timeouts.idle,
+	}
+}
+
+// startMetricsServer abre a porta de métricas e atende em background. Falhas
+// ao abrir a porta são retornadas para o Start.
+func (ws *WebServer) startMetricsServer() error {
+	if ws.metricsServer == nil {
+		return nil
+	}
+
+	listener, err := ws.listen(ws.metricsServer)
+	if err != nil {
+		return err
+	}
+
+	ws.logger.Info(ws.i18n.Get("webserver.metrics_server_started", map[string]interface{}{"address": ws.metricsServer.Addr}))
+
+	go func() {
+		if err := ws.metricsServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			ws.logger.Error(ws.i18n.Get("webserver.metrics_server_failed", map[string]interface{}{"error": err.Error()}))
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeMetrics_RequiresBearerToken(t *testing.T) {
+	env := testEnv{"PROMETHEUS_TOKEN": "secret"}
+	ws := newTestWebServer(t, env)
+	ws.serveMetrics(env)
+
+	tests := []struct {
+		name          string
+		authorization string
+		status        int
+	}{
+		{name: "missing", authorization: "", status: http.StatusUnauthorized},
+		{name: "wrong token", authorization: "Bearer other", status: http.StatusUnauthorized},
+		{name: "without scheme", authorization: "secret", status: http.StatusUnauthorized},
+		{name: "valid", authorization: "Bearer secret", status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+
+			recorder := serve(ws, r)
+
+			assert.Equal(t, tt.status, recorder.Code)
+			if tt.status == http.StatusUnauthorized {
+				assert.Equal(t, "Bearer", recorder.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestServeMetrics_SeparatePort(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+	require.NoError(t, listener.Close())
+
+	env := testEnv{"WEB_SERVER_METRICS_PORT": port}
+	ws := newTestWebServer(t, env)
+	ws.host = "127.0.0.1"
+	ws.serveMetrics(env)
+
+	assert.Equal(t, http.StatusNotFound, serve(ws, httptest.NewRequest(http.MethodGet, "/metrics", nil)).Code)
+
+	require.NoError(t, ws.startMetricsServer())
+	defer ws.metricsServer.Shutdown(context.Background())
+
+	response, err := http.Get("http://127.0.0.1:" + port + "/metrics")
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
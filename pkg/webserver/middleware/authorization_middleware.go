@@ -0,0 +1,69 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_middleware
+
+import (
+	"net/http"
+
+	"github.com/caiomarcatti12/nanogo/pkg/authz"
+	"github.com/caiomarcatti12/nanogo/pkg/i18n"
+	"github.com/caiomarcatti12/nanogo/pkg/jwt"
+	"github.com/caiomarcatti12/nanogo/pkg/log"
+	webserver_problem "github.com/caiomarcatti12/nanogo/pkg/webserver/problem"
+)
+
+// AuthorizationMiddleware confere a Policy da rota contra o principal
+// autenticado pelo JWTMiddleware. O webserver o adiciona às rotas com Roles ou
+// Scopes, depois dos demais middlewares.
+type AuthorizationMiddleware struct {
+	authorizer authz.IAuthorizer
+	policy     authz.Policy
+	log        log.ILog
+	i18n       i18n.I18N
+}
+
+func NewAuthorizationMiddleware(authorizer authz.IAuthorizer, policy authz.Policy, log log.ILog, i18n i18n.I18N) IMiddleware {
+	return &AuthorizationMiddleware{
+		authorizer: authorizer,
+		policy:     policy,
+		log:        log,
+		i18n:       i18n,
+	}
+}
+
+func (m *AuthorizationMiddleware) GetName() string {
+	return "AuthorizationMiddleware"
+}
+
+func (m *AuthorizationMiddleware) Process(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	m.log.Trace(m.i18n.Get("webserver.middleware.authorizing"))
+
+	if r.Method == http.MethodOptions {
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	claims, _ := jwt.FromContext(r.Context())
+
+	if err := m.authorizer.Authorize(m.authorizer.Principal(claims), m.policy); err != nil {
+		m.log.Warning(err.Error())
+
+		webserver_problem.Write(w, r, err)
+		return
+	}
+
+	next.ServeHTTP(w, r)
+}
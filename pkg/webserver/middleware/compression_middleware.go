@@ -0,0 +1,241 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_middleware
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/caiomarcatti12/nanogo/pkg/env"
+	"github.com/caiomarcatti12/nanogo/pkg/i18n"
+	"github.com/caiomarcatti12/nanogo/pkg/log"
+	webserver_encoding "github.com/caiomarcatti12/nanogo/pkg/webserver/encoding"
+	"github.com/gorilla/websocket"
+)
+
+// incompressibleTypes são formatos que já chegam comprimidos, e text/event-stream,
+// que alguns proxies e clientes só entregam sem compressão.
+var incompressibleTypes = []string{"image/", "video/", "audio/", "application/zip", "application/gzip", "application/x-gzip", "application/zstd", "text/event-stream"}
+
+// CompressionMiddleware comprime a resposta com a Content-Encoding negociada
+// em Accept-Encoding. Respostas menores que minSize seguem sem compressão; as
+// transmitidas com Flush são comprimidas desde o primeiro Flush.
+type CompressionMiddleware struct {
+	compressors *webserver_encoding.Compressors
+	minSize     int
+	log         log.ILog
+	i18n        i18n.I18N
+}
+
+func NewCompressionMiddleware(env env.IEnv, compressors *webserver_encoding.Compressors, log log.ILog, i18n i18n.I18N) IMiddleware {
+	minSize, err := strconv.Atoi(env.GetEnv("WEB_SERVER_COMPRESSION_MIN_SIZE", "1024"))
+	if err != nil || minSize < 0 {
+		minSize = 1024
+	}
+
+	return &CompressionMiddleware{
+		compressors: compressors,
+		minSize:     minSize,
+		log:         log,
+		i18n:        i18n,
+	}
+}
+
+func (m *CompressionMiddleware) GetName() string {
+	return "CompressionMiddleware"
+}
+
+func (m *CompressionMiddleware) Process(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	m.log.Trace(m.i18n.Get("webserver.middleware.compressing"))
+
+	if r.Method == http.MethodHead || websocket.IsWebSocketUpgrade(r) {
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	w.Header().Add("Vary", "Accept-Encoding")
+
+	compressor, ok := m.compressors.Negotiate(r.Header.Get("Accept-Encoding"))
+	if !ok {
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	writer := &compressWriter{ResponseWriter: w, compressor: compressor, minSize: m.minSize}
+	defer writer.Close()
+
+	next.ServeHTTP(writer, r)
+}
+
+// compressWriter acumula o início do corpo até minSize para decidir se
+// comprime; só então escreve o status e os cabeçalhos.
+type compressWriter struct {
+	http.ResponseWriter
+	compressor webserver_encoding.ICompressor
+	minSize    int
+	status     int
+	buffer     []byte
+	writer     io.WriteCloser
+	started    bool
+	hijacked   bool
+}
+
+func (c *compressWriter) WriteHeader(status int) {
+	// Respostas informativas (ex.: 103 Early Hints) não encerram os cabeçalhos.
+	if status >= 100 && status < 200 && status != http.StatusSwitchingProtocols {
+		c.ResponseWriter.WriteHeader(status)
+		return
+	}
+
+	if !c.started && c.status == 0 {
+		c.status = status
+	}
+}
+
+func (c *compressWriter) Write(data []byte) (int, error) {
+	if !c.started {
+		c.buffer = append(c.buffer, data...)
+
+		if len(c.buffer) >= c.minSize {
+			if err := c.start(true); err != nil {
+				return 0, err
+			}
+		}
+
+		return len(data), nil
+	}
+
+	if c.writer != nil {
+		return c.writer.Write(data)
+	}
+
+	return c.ResponseWriter.Write(data)
+}
+
+// start escreve os cabeçalhos, com Content-Encoding quando compress é
+// verdadeiro e a resposta admite compressão, e descarrega o que foi acumulado.
+func (c *compressWriter) start(compress bool) error {
+	c.started = true
+
+	if c.status == 0 {
+		c.status = http.StatusOK
+	}
+
+	if compress && c.compressible() {
+		c.Header().Set("Content-Encoding", c.compressor.Encoding())
+		c.Header().Del("Content-Length")
+		c.writer = c.compressor.NewWriter(c.ResponseWriter)
+	}
+
+	c.ResponseWriter.WriteHeader(c.status)
+
+	buffer := c.buffer
+	c.buffer = nil
+
+	if len(buffer) == 0 {
+		return nil
+	}
+
+	if c.writer != nil {
+		_, err := c.writer.Write(buffer)
+		return err
+	}
+
+	_, err := c.ResponseWriter.Write(buffer)
+	return err
+}
+
+func (c *compressWriter) compressible() bool {
+	if c.status < http.StatusOK || c.status == http.StatusNoContent || c.status == http.StatusNotModified {
+		return false
+	}
+
+	if c.Header().Get("Content-Encoding") != "" {
+		return false
+	}
+
+	contentType := strings.ToLower(c.Header().Get("Content-Type"))
+	if strings.HasPrefix(contentType, "image/svg") {
+		return true
+	}
+
+	for _, incompressible := range incompressibleTypes {
+		if strings.HasPrefix(contentType, incompressible) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c *compressWriter) Flush() {
+	if c.hijacked {
+		return
+	}
+
+	if !c.started {
+		c.start(true)
+	}
+
+	if flusher, ok := c.writer.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+
+	if flusher, ok := c.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Close conclui a resposta. Um corpo que não chegou a minSize é escrito sem
+// compressão.
+func (c *compressWriter) Close() error {
+	if c.hijacked {
+		return nil
+	}
+
+	if !c.started {
+		// Sem WriteHeader nem Write, o net/http responde 200 sozinho.
+		if c.status == 0 && len(c.buffer) == 0 {
+			return nil
+		}
+
+		return c.start(false)
+	}
+
+	if c.writer != nil {
+		return c.writer.Close()
+	}
+
+	return nil
+}
+
+func (c *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := c.ResponseWriter.(http.Hijacker); ok {
+		c.hijacked = true
+		return hijacker.Hijack()
+	}
+
+	return nil, nil, errors.New("webserver: response writer does not support hijacking")
+}
+
+func (c *compressWriter) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/caiomarcatti12/nanogo/pkg/errors"
+	"github.com/caiomarcatti12/nanogo/pkg/i18n"
+	"github.com/caiomarcatti12/nanogo/pkg/jwt"
+	"github.com/caiomarcatti12/nanogo/pkg/log"
+	webserver_problem "github.com/caiomarcatti12/nanogo/pkg/webserver/problem"
+)
+
+// JWTMiddleware rejeita com 401 as requisições sem um token Bearer válido e
+// grava as claims no contexto, de onde são injetadas nos campos com a tag
+// claim dos parâmetros do handler. Use em grupos ou rotas que exigem
+// autenticação.
+type JWTMiddleware struct {
+	manager jwt.IJWTManager
+	log     log.ILog
+	i18n    i18n.I18N
+}
+
+func NewJWTMiddleware(manager jwt.IJWTManager, log log.ILog, i18n i18n.I18N) IMiddleware {
+	return &JWTMiddleware{
+		manager: manager,
+		log:     log,
+		i18n:    i18n,
+	}
+}
+
+func (m *JWTMiddleware) GetName() string {
+	return "JWTMiddleware"
+}
+
+func (m *JWTMiddleware) Process(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	m.log.Trace(m.i18n.Get("webserver.middleware.authenticating"))
+
+	// Requisições de preflight do CORS não enviam credenciais.
+	if r.Method == http.MethodOptions {
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	if !found || token == "" {
+		m.unauthorized(w, r, m.i18n.Get("webserver.middleware.missing_token"))
+		return
+	}
+
+	claims, err := m.manager.Validate(token)
+
+	if err != nil {
+		m.log.Debug(err.Error())
+		m.unauthorized(w, r, m.i18n.Get("webserver.middleware.invalid_token"))
+		return
+	}
+
+	next.ServeHTTP(w, r.WithContext(jwt.NewContext(r.Context(), claims)))
+}
+
+func (m *JWTMiddleware) unauthorized(w http.ResponseWriter, r *http.Request, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+
+	webserver_problem.Write(w, r, errors.Unauthorized(message))
+}
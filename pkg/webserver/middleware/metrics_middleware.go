@@ -0,0 +1,103 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/caiomarcatti12/nanogo/pkg/env"
+	"github.com/caiomarcatti12/nanogo/pkg/i18n"
+	"github.com/caiomarcatti12/nanogo/pkg/log"
+	"github.com/caiomarcatti12/nanogo/pkg/metric"
+	"github.com/gorilla/mux"
+)
+
+const (
+	requestsTotalMetric   = "http_requests_total"
+	requestErrorsMetric   = "http_request_errors_total"
+	requestDurationMetric = "http_request_duration_seconds"
+)
+
+// MetricsMiddleware registra as métricas RED (taxa, erros e duração) das
+// requisições, rotuladas por método, template da rota e status.
+type MetricsMiddleware struct {
+	enable bool
+	metric metric.IMetric
+	log    log.ILog
+	i18n   i18n.I18N
+}
+
+func NewMetricsMiddleware(env env.IEnv, log log.ILog, i18n i18n.I18N, metrics metric.IMetric) IMiddleware {
+	m := &MetricsMiddleware{
+		enable: env.GetEnvBool("WEB_SERVER_METRICS_ENABLED", "true") && metrics != nil,
+		metric: metrics,
+		log:    log,
+		i18n:   i18n,
+	}
+
+	if m.enable {
+		labels := metric.LabelsKeys{"method", "route", "status"}
+
+		metrics.CreateMetric(metric.Counter, requestsTotalMetric, "Indicates quantity of HTTP requests served", labels)
+		metrics.CreateMetric(metric.Counter, requestErrorsMetric, "Indicates quantity of HTTP requests answered with a 5xx status", labels)
+		metrics.CreateMetric(metric.Histogram, requestDurationMetric, "Indicates HTTP request duration in seconds", labels)
+	}
+
+	return m
+}
+
+func (m *MetricsMiddleware) GetName() string {
+	return "MetricsMiddleware"
+}
+
+func (m *MetricsMiddleware) Process(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	if !m.enable {
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	start := time.Now()
+	recorder := newStatusRecorder(w)
+
+	next.ServeHTTP(recorder, r)
+
+	labels := metric.Labels{
+		"method": r.Method,
+		"route":  m.routeTemplate(r),
+		"status": strconv.Itoa(recorder.status),
+	}
+
+	m.metric.IncrementCounter(requestsTotalMetric, labels)
+	m.metric.ObserveHistogram(requestDurationMetric, time.Since(start).Seconds(), labels)
+
+	if recorder.status >= http.StatusInternalServerError {
+		m.metric.IncrementCounter(requestErrorsMetric, labels)
+	}
+}
+
+// routeTemplate usa o template da rota (/users/{id}) para manter a
+// cardinalidade dos rótulos baixa.
+func (m *MetricsMiddleware) routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+
+	return "unmatched"
+}
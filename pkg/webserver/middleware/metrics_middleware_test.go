@@ -0,0 +1,142 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/caiomarcatti12/nanogo/pkg/di/ditest"
+	"github.com/caiomarcatti12/nanogo/pkg/metric"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+type testEnv map[string]string
+
+func (e testEnv) GetEnv(variable string, defaultValue ...string) string {
+	if value, ok := e[variable]; ok {
+		return value
+	}
+
+	if len(defaultValue) > 0 {
+		return defaultValue[0]
+	}
+
+	return ""
+}
+
+func (e testEnv) GetEnvBool(variable string, defaultValue ...string) bool {
+	return e.GetEnv(variable, defaultValue...) == "true"
+}
+
+// recordingMetric guarda os rótulos de cada contador incrementado.
+type recordingMetric struct {
+	mu       sync.Mutex
+	counters map[string][]metric.Labels
+}
+
+func newRecordingMetric() *recordingMetric {
+	return &recordingMetric{counters: make(map[string][]metric.Labels)}
+}
+
+func (m *recordingMetric) CreateMetric(metric.MetricType, string, string, metric.LabelsKeys) {}
+
+func (m *recordingMetric) IncrementCounter(name string, labels metric.Labels) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.counters[name] = append(m.counters[name], labels)
+
+	return nil
+}
+
+func (m *recordingMetric) SetGauge(string, float64, metric.Labels) error         { return nil }
+func (m *recordingMetric) ObserveHistogram(string, float64, metric.Labels) error { return nil }
+func (m *recordingMetric) ObserveSummary(string, float64, metric.Labels) error   { return nil }
+
+func newTestMetricsMiddleware(metrics metric.IMetric) IMiddleware {
+	return NewMetricsMiddleware(testEnv{}, ditest.Logger{}, ditest.Translator{}, metrics)
+}
+
+func TestMetricsMiddleware_LabelsWithRouteTemplate(t *testing.T) {
+	metrics := newRecordingMetric()
+	middleware := newTestMetricsMiddleware(metrics)
+
+	router := mux.NewRouter()
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			middleware.Process(w, r, next)
+		})
+	})
+	router.HandleFunc("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}).Methods(http.MethodPost)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/users/42", nil))
+
+	assert.Equal(t, []metric.Labels{{"method": "POST", "route": "/users/{id}", "status": "201"}}, metrics.counters[requestsTotalMetric])
+}
+
+func TestMetricsMiddleware_LabelsUnmatchedRequests(t *testing.T) {
+	metrics := newRecordingMetric()
+	middleware := newTestMetricsMiddleware(metrics)
+
+	middleware.Process(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil), http.NotFoundHandler())
+
+	assert.Equal(t, []metric.Labels{{"method": "GET", "route": "unmatched", "status": "404"}}, metrics.counters[requestsTotalMetric])
+}
+
+func TestMetricsMiddleware_CountsErrorsOnlyFor5xx(t *testing.T) {
+	tests := []struct {
+		status int
+		errors int
+	}{
+		{status: http.StatusOK, errors: 0},
+		{status: http.StatusBadRequest, errors: 0},
+		{status: http.StatusNotFound, errors: 0},
+		{status: http.StatusInternalServerError, errors: 1},
+		{status: http.StatusServiceUnavailable, errors: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			metrics := newRecordingMetric()
+			middleware := newTestMetricsMiddleware(metrics)
+
+			middleware.Process(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+
+			assert.Len(t, metrics.counters[requestsTotalMetric], 1)
+			assert.Len(t, metrics.counters[requestErrorsMetric], tt.errors)
+		})
+	}
+}
+
+func TestMetricsMiddleware_PassesThroughWithoutMetrics(t *testing.T) {
+	middleware := newTestMetricsMiddleware(nil)
+	recorder := httptest.NewRecorder()
+
+	assert.NotPanics(t, func() {
+		middleware.Process(recorder, httptest.NewRequest(http.MethodGet, "/", nil), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		}))
+	})
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
+}
@@ -1,8 +1,7 @@
 package webserver_middleware
 
-import "net/http"
+import webserver_types "github.com/caiomarcatti12/nanogo/pkg/webserver/types"
 
-type IMiddleware interface {
-	GetName() string
-	Process(w http.ResponseWriter, r *http.Request, next http.Handler)
-}
+// IMiddleware é definido em webserver_types para que as rotas possam declarar
+// seus próprios middlewares.
+type IMiddleware = webserver_types.IMiddleware
@@ -17,33 +17,40 @@ package webserver_middleware
 
 import (
 	"context"
-	"encoding/json"
+	stderrors "errors"
 	"io"
-	"io/ioutil"
 	"net/http"
-	"strconv"
 	"strings"
-	"time"
 
-	"github.com/caiomarcatti12/nanogo/pkg/env"
+	"github.com/caiomarcatti12/nanogo/pkg/errors"
 	"github.com/caiomarcatti12/nanogo/pkg/i18n"
 	"github.com/caiomarcatti12/nanogo/pkg/log"
-	webserver_types "github.com/caiomarcatti12/nanogo/pkg/webserver/types"
-	"github.com/google/uuid"
+	webserver_binding "github.com/caiomarcatti12/nanogo/pkg/webserver/binding"
+	webserver_encoding "github.com/caiomarcatti12/nanogo/pkg/webserver/encoding"
+	webserver_problem "github.com/caiomarcatti12/nanogo/pkg/webserver/problem"
+	webserver_upload "github.com/caiomarcatti12/nanogo/pkg/webserver/upload"
 	"github.com/gorilla/mux"
 )
 
+// PayloadExtractorMiddleware lê rota, query, cabeçalhos, formulário, uploads e
+// corpo da requisição. Cada origem fica separada em webserver_binding.FromContext,
+// usada no vínculo dos handlers, e também é juntada no map de payload, em que o
+// caminho prevalece sobre a query e a query sobre o corpo. O corpo é lido pelo
+// codec do Content-Type (JSON quando ausente) e fica disponível bruto em
+// webserver_encoding.FromContext.
 type PayloadExtractorMiddleware struct {
-	maxUploadSize string
-	log           log.ILog
-	i18n          i18n.I18N
+	codecs  *webserver_encoding.Registry
+	uploads *webserver_upload.Receiver
+	log     log.ILog
+	i18n    i18n.I18N
 }
 
-func NewPayloadExtractorMiddleware(env env.IEnv, log log.ILog, i18n i18n.I18N) IMiddleware {
+func NewPayloadExtractorMiddleware(codecs *webserver_encoding.Registry, uploads *webserver_upload.Receiver, log log.ILog, i18n i18n.I18N) IMiddleware {
 	return &PayloadExtractorMiddleware{
-		maxUploadSize: env.GetEnv("WEB_SERVER_MAX_UPLOAD_SIZE", "5"),
-		log:           log,
-		i18n:          i18n,
+		codecs:  codecs,
+		uploads: uploads,
+		log:     log,
+		i18n:    i18n,
 	}
 }
 
@@ -54,123 +61,172 @@ func (m *PayloadExtractorMiddleware) GetName() string {
 func (m *PayloadExtractorMiddleware) Process(w http.ResponseWriter, r *http.Request, next http.Handler) {
 	m.log.Trace(m.i18n.Get("webserver.middleware.extracting_payload"))
 
-	payload := make(map[string]interface{})
-
-	if r.Method == http.MethodGet {
-		m.parseGetPayload(r, payload)
+	request := webserver_binding.Request{
+		Path:   mux.Vars(r),
+		Query:  r.URL.Query(),
+		Header: r.Header,
 	}
 
-	m.parseRoutePayload(r, payload)
+	ctx := r.Context()
 
-	if m.isMultiPart(r) {
-		if err := m.parseMultiPartPayload(r, w, payload); err != nil {
+	switch {
+	case m.isMultiPart(r):
+		form, err := m.parseMultiPartPayload(r, w, &request)
+		if err != nil {
 			return
 		}
-	} else {
-		if err := m.parseJSONPayload(r, w, payload); err != nil {
+
+		// Os arquivos temporários são removidos depois do handler.
+		defer func() {
+			if err := form.Release(); err != nil {
+				m.log.Error(m.i18n.Get("webserver.middleware.upload_release_failed", map[string]interface{}{"error": err}))
+			}
+		}()
+	case m.isForm(r):
+		if err := m.parseFormPayload(r, w, &request); err != nil {
+			return
+		}
+	default:
+		body, err := m.parseBodyPayload(r, w, &request)
+		if err != nil {
 			return
 		}
-	}
-
-	newCtx := context.WithValue(r.Context(), "payload", payload)
-	next.ServeHTTP(w, r.WithContext(newCtx))
-}
 
-func (m *PayloadExtractorMiddleware) parseGetPayload(r *http.Request, payload map[string]interface{}) {
-	for key, values := range r.URL.Query() {
-		if len(values) > 0 {
-			payload[key] = m.parseValue(values[0])
+		if body != nil {
+			ctx = webserver_encoding.NewContext(ctx, *body)
 		}
 	}
+
+	ctx = webserver_binding.NewContext(ctx, request)
+	ctx = context.WithValue(ctx, "payload", m.mergePayload(request))
+	next.ServeHTTP(w, r.WithContext(ctx))
 }
 
-func (m *PayloadExtractorMiddleware) parseRoutePayload(r *http.Request, payload map[string]interface{}) {
-	vars := mux.Vars(r)
-	for key, value := range vars {
-		payload[key] = m.parseValue(value)
+// mergePayload junta as origens em um único map. Parâmetros repetidos ficam
+// como []string e os valores são mantidos como texto, sem adivinhar o tipo.
+func (m *PayloadExtractorMiddleware) mergePayload(request webserver_binding.Request) map[string]interface{} {
+	payload := make(map[string]interface{}, len(request.Body))
+
+	for key, value := range request.Body {
+		payload[key] = value
 	}
-}
 
-func (m *PayloadExtractorMiddleware) parseMultiPartPayload(r *http.Request, w http.ResponseWriter, payload map[string]interface{}) error {
-	if err := m.checkMaxUploadSize(r); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return err
+	for key, values := range request.Form {
+		m.setValues(payload, key, values)
 	}
 
-	for key, values := range r.MultipartForm.Value {
-		if len(values) > 0 {
-			payload[key] = m.parseValue(values[0])
+	for key, files := range request.Files {
+		if len(files) == 1 {
+			payload[key] = files[0]
+		} else {
+			payload[key] = files
 		}
 	}
 
-	for key := range r.MultipartForm.File {
-		fileUpload, err := m.parseUpload(r, key)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return err
-		}
-		payload[key] = fileUpload
+	for key, values := range request.Query {
+		m.setValues(payload, key, values)
 	}
-	return nil
-}
 
-func (m *PayloadExtractorMiddleware) parseJSONPayload(r *http.Request, w http.ResponseWriter, payload map[string]interface{}) error {
-	if r.Body != http.NoBody {
-		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && err != io.EOF {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return err
-		}
+	for key, value := range request.Path {
+		payload[key] = value
 	}
-	return nil
-}
 
-func (m *PayloadExtractorMiddleware) parseValue(value string) interface{} {
-	if id, err := uuid.Parse(value); err == nil {
-		return id
-	} else if intValue, err := strconv.Atoi(value); err == nil {
-		return intValue
-	} else if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
-		return floatValue
-	} else if boolValue, err := strconv.ParseBool(value); err == nil {
-		return boolValue
-	} else if timeValue, err := time.Parse(time.RFC3339, value); err == nil {
-		return timeValue
-	}
-	return value
+	return payload
 }
 
-func (m *PayloadExtractorMiddleware) isMultiPart(r *http.Request) bool {
-	return strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data")
+func (m *PayloadExtractorMiddleware) setValues(payload map[string]interface{}, key string, values []string) {
+	switch len(values) {
+	case 0:
+	case 1:
+		payload[key] = values[0]
+	default:
+		payload[key] = values
+	}
 }
 
-func (m *PayloadExtractorMiddleware) checkMaxUploadSize(r *http.Request) error {
-	maxUploadSize, err := strconv.ParseInt(m.maxUploadSize, 10, 64)
-	if err != nil || maxUploadSize <= 0 {
-		maxUploadSize = 5 << 20
+func (m *PayloadExtractorMiddleware) parseMultiPartPayload(r *http.Request, w http.ResponseWriter, request *webserver_binding.Request) (*webserver_upload.Form, error) {
+	form, err := m.uploads.Receive(w, r)
+	if err != nil {
+		var typeError *webserver_upload.TypeError
+
+		switch {
+		case stderrors.Is(err, webserver_upload.ErrTooLarge):
+			webserver_problem.Write(w, r, errors.PayloadTooLarge(m.i18n.Get("webserver.middleware.upload_too_large", map[string]interface{}{"size": webserver_upload.FormatSize(m.uploads.Policy(r).MaxSize)})))
+		case stderrors.As(err, &typeError):
+			webserver_problem.Write(w, r, errors.UnsupportedMediaType(m.i18n.Get("webserver.middleware.upload_type_not_allowed", map[string]interface{}{"file": typeError.Filename, "contentType": typeError.ContentType})))
+		default:
+			m.badRequest(w, r, err)
+		}
+
+		return nil, err
 	}
 
-	err = r.ParseMultipartForm(maxUploadSize)
-	if err != nil {
+	request.Form = form.Values
+	request.Files = form.Files
+	return form, nil
+}
+
+func (m *PayloadExtractorMiddleware) parseFormPayload(r *http.Request, w http.ResponseWriter, request *webserver_binding.Request) error {
+	if err := r.ParseForm(); err != nil {
+		m.badRequest(w, r, err)
 		return err
 	}
+
+	request.Form = r.PostForm
 	return nil
 }
 
-func (m *PayloadExtractorMiddleware) parseUpload(r *http.Request, name string) (*webserver_types.FileUpload, error) {
-	file, handler, err := r.FormFile(name)
+func (m *PayloadExtractorMiddleware) parseBodyPayload(r *http.Request, w http.ResponseWriter, request *webserver_binding.Request) (*webserver_encoding.Body, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+
+	data, err := io.ReadAll(r.Body)
 	if err != nil {
+		m.badRequest(w, r, err)
 		return nil, err
 	}
-	defer file.Close()
 
-	fileBytes, err := ioutil.ReadAll(file)
-	if err != nil {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	codec, ok := m.codecs.Lookup(contentType)
+	if !ok {
+		err := errors.UnsupportedMediaType(m.i18n.Get("webserver.middleware.unsupported_media_type", map[string]interface{}{"contentType": contentType}))
+		webserver_problem.Write(w, r, err)
 		return nil, err
 	}
 
-	return &webserver_types.FileUpload{
-		Filename: handler.Filename,
-		Size:     handler.Size,
-		Content:  fileBytes,
-	}, nil
+	// Corpos que não formam um objeto, como protobuf e CSV, ficam só no
+	// contexto, para o handler que recebe o tipo de destino.
+	body := make(map[string]interface{})
+	if err := codec.Decode(data, &body); err != nil {
+		if !stderrors.Is(err, webserver_encoding.ErrUnsupported) {
+			m.badRequest(w, r, err)
+			return nil, err
+		}
+	} else {
+		request.Body = body
+	}
+
+	return &webserver_encoding.Body{Codec: codec, Data: data}, nil
+}
+
+// badRequest responde 400 no formato de erro configurado.
+func (m *PayloadExtractorMiddleware) badRequest(w http.ResponseWriter, r *http.Request, err error) {
+	webserver_problem.Write(w, r, errors.BadRequest(m.i18n.Get("webserver.middleware.invalid_body", map[string]interface{}{"error": err.Error()})))
+}
+
+func (m *PayloadExtractorMiddleware) isMultiPart(r *http.Request) bool {
+	return strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data")
+}
+
+func (m *PayloadExtractorMiddleware) isForm(r *http.Request) bool {
+	return strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded")
 }
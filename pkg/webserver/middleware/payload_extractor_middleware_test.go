@@ -0,0 +1,129 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/caiomarcatti12/nanogo/pkg/di/ditest"
+	webserver_encoding "github.com/caiomarcatti12/nanogo/pkg/webserver/encoding"
+	webserver_problem "github.com/caiomarcatti12/nanogo/pkg/webserver/problem"
+	webserver_upload "github.com/caiomarcatti12/nanogo/pkg/webserver/upload"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func extractPayload(t *testing.T, r *http.Request) *httptest.ResponseRecorder {
+	env := testEnv{"WEB_SERVER_MAX_UPLOAD_SIZE": "1"}
+	middleware := NewPayloadExtractorMiddleware(webserver_encoding.NewRegistry(), webserver_upload.NewReceiver(env), ditest.Logger{}, ditest.Translator{})
+
+	recorder := httptest.NewRecorder()
+	middleware.Process(recorder, r, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the handler must not run for an invalid payload")
+	}))
+
+	return recorder
+}
+
+func multipartBody(t *testing.T, size int) (*bytes.Buffer, string) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	part, err := writer.CreateFormFile("file", "large.bin")
+	require.NoError(t, err)
+	_, err = part.Write(bytes.Repeat([]byte{0}, size))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	return &body, writer.FormDataContentType()
+}
+
+func TestPayloadExtractor_InvalidPayloadsAreProblems(t *testing.T) {
+	uploadBody, uploadType := multipartBody(t, 2<<20)
+
+	tests := []struct {
+		name        string
+		body        *strings.Reader
+		contentType string
+		status      int
+		detail      string
+	}{
+		{
+			name:        "malformed json",
+			body:        strings.NewReader(`{"name":`),
+			contentType: "application/json",
+			status:      http.StatusBadRequest,
+			detail:      "webserver.middleware.invalid_body",
+		},
+		{
+			name:        "msgpack nested too deeply",
+			body:        strings.NewReader(string(bytes.Repeat([]byte{0x91}, 5<<20))),
+			contentType: "application/msgpack",
+			status:      http.StatusBadRequest,
+			detail:      "webserver.middleware.invalid_body",
+		},
+		{
+			name:        "xml nested too deeply",
+			body:        strings.NewReader(strings.Repeat("<a>", webserver_encoding.MaxDepth+1)),
+			contentType: "application/xml",
+			status:      http.StatusBadRequest,
+			detail:      "webserver.middleware.invalid_body",
+		},
+		{
+			name:        "malformed form",
+			body:        strings.NewReader("name=%zz"),
+			contentType: "application/x-www-form-urlencoded",
+			status:      http.StatusBadRequest,
+			detail:      "webserver.middleware.invalid_body",
+		},
+		{
+			name:        "malformed multipart",
+			body:        strings.NewReader("not multipart"),
+			contentType: "multipart/form-data; boundary=missing",
+			status:      http.StatusBadRequest,
+			detail:      "webserver.middleware.invalid_body",
+		},
+		{
+			name:        "upload too large",
+			body:        strings.NewReader(uploadBody.String()),
+			contentType: uploadType,
+			status:      http.StatusRequestEntityTooLarge,
+			detail:      "webserver.middleware.upload_too_large size=1 MB",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", tt.body)
+			r.Header.Set("Content-Type", tt.contentType)
+
+			recorder := extractPayload(t, r)
+
+			assert.Equal(t, tt.status, recorder.Code)
+			assert.Equal(t, webserver_problem.ContentType, recorder.Header().Get("Content-Type"))
+
+			var problem webserver_problem.Problem
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+			assert.True(t, strings.HasPrefix(problem.Detail, tt.detail), problem.Detail)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/caiomarcatti12/nanogo/pkg/errors"
+	"github.com/caiomarcatti12/nanogo/pkg/i18n"
+	"github.com/caiomarcatti12/nanogo/pkg/log"
+	"github.com/caiomarcatti12/nanogo/pkg/ratelimit"
+	webserver_problem "github.com/caiomarcatti12/nanogo/pkg/webserver/problem"
+)
+
+// RateLimitMiddleware aplica a política ao cliente identificado por
+// policy.Key (o IP quando a requisição não traz a informação da KeyFunc).
+// Toda resposta recebe os cabeçalhos RateLimit-*; acima do limite a resposta é
+// 429 com Retry-After. Se o backend falhar, a requisição é liberada.
+type RateLimitMiddleware struct {
+	limiter ratelimit.IRateLimiter
+	policy  ratelimit.Policy
+	log     log.ILog
+	i18n    i18n.I18N
+}
+
+func NewRateLimitMiddleware(limiter ratelimit.IRateLimiter, policy ratelimit.Policy, log log.ILog, i18n i18n.I18N) IMiddleware {
+	return &RateLimitMiddleware{
+		limiter: limiter,
+		policy:  policy.WithDefaults(ratelimit.Policy{}),
+		log:     log,
+		i18n:    i18n,
+	}
+}
+
+func (m *RateLimitMiddleware) GetName() string {
+	return "RateLimitMiddleware"
+}
+
+func (m *RateLimitMiddleware) Process(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	m.log.Trace(m.i18n.Get("webserver.middleware.rate_limiting"))
+
+	if r.Method == http.MethodOptions {
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	key := m.policy.Key(r)
+	if key == "" {
+		key = ratelimit.ByIP(0)(r)
+	}
+
+	result, err := m.limiter.Allow(key, m.policy)
+
+	if err != nil {
+		m.log.Error(m.i18n.Get("webserver.middleware.rate_limit_failed", map[string]interface{}{"error": err}))
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+	w.Header().Set("RateLimit-Policy", m.policy.Header())
+
+	if !result.Allowed {
+		retryAfter := max(1, ceilSeconds(result.RetryAfter))
+
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		webserver_problem.Write(w, r, errors.TooManyRequests(m.i18n.Get("webserver.middleware.rate_limit_exceeded", map[string]interface{}{"seconds": retryAfter})))
+		return
+	}
+
+	next.ServeHTTP(w, r)
+}
+
+func ceilSeconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
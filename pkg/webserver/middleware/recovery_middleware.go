@@ -0,0 +1,68 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_middleware
+
+import (
+	"net/http"
+
+	"github.com/caiomarcatti12/nanogo/pkg/errors"
+	"github.com/caiomarcatti12/nanogo/pkg/i18n"
+	"github.com/caiomarcatti12/nanogo/pkg/log"
+	"github.com/caiomarcatti12/nanogo/pkg/recovery"
+	webserver_problem "github.com/caiomarcatti12/nanogo/pkg/webserver/problem"
+)
+
+// RecoveryMiddleware responde 500 quando um middleware ou handler entra em
+// panic, em vez de derrubar a conexão. Panics dos handlers já são tratados
+// pelo webserver; este middleware cobre o restante da cadeia.
+type RecoveryMiddleware struct {
+	recoverer *recovery.Recoverer
+	log       log.ILog
+	i18n      i18n.I18N
+}
+
+func NewRecoveryMiddleware(recoverer *recovery.Recoverer, log log.ILog, i18n i18n.I18N) IMiddleware {
+	return &RecoveryMiddleware{
+		recoverer: recoverer,
+		log:       log,
+		i18n:      i18n,
+	}
+}
+
+func (m *RecoveryMiddleware) GetName() string {
+	return "RecoveryMiddleware"
+}
+
+func (m *RecoveryMiddleware) Process(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	m.log.Trace(m.i18n.Get("webserver.middleware.recovering"))
+
+	defer func() {
+		value := recover()
+		if value == nil {
+			return
+		}
+
+		// http.ErrAbortHandler interrompe a resposta de propósito.
+		if value == http.ErrAbortHandler {
+			panic(value)
+		}
+
+		m.recoverer.Recover(value)
+		webserver_problem.Write(w, r, errors.InternalServerError(m.i18n.Get("webserver.panic_recovered")))
+	}()
+
+	next.ServeHTTP(w, r)
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_middleware
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hijackableRecorder é um httptest.ResponseRecorder que também aceita Hijack.
+type hijackableRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (r *hijackableRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	r.hijacked = true
+
+	return nil, nil, nil
+}
+
+func TestStatusRecorder_ForwardsFlush(t *testing.T) {
+	original := httptest.NewRecorder()
+	recorder := newStatusRecorder(original)
+
+	recorder.Flush()
+
+	assert.True(t, original.Flushed)
+}
+
+func TestStatusRecorder_ForwardsHijack(t *testing.T) {
+	original := &hijackableRecorder{ResponseRecorder: httptest.NewRecorder()}
+	recorder := newStatusRecorder(original)
+
+	_, _, err := recorder.Hijack()
+
+	require.NoError(t, err)
+	assert.True(t, original.hijacked)
+	assert.Equal(t, http.StatusSwitchingProtocols, recorder.status)
+}
+
+func TestStatusRecorder_HijackFailsWithoutSupport(t *testing.T) {
+	recorder := newStatusRecorder(httptest.NewRecorder())
+
+	_, _, err := recorder.Hijack()
+
+	assert.Error(t, err)
+}
+
+func TestStatusRecorder_UnwrapReachesOriginalWriter(t *testing.T) {
+	original := httptest.NewRecorder()
+	recorder := newStatusRecorder(original)
+
+	assert.Same(t, original, recorder.Unwrap())
+	assert.NoError(t, http.NewResponseController(recorder).Flush())
+	assert.True(t, original.Flushed)
+}
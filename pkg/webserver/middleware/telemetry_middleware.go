@@ -78,6 +78,5 @@ func (m *TelemetryMiddleware) Process(w http.ResponseWriter, r *http.Request, ne
 		next.ServeHTTP(w, r)
 
 		m.telemetry.EndSpan(span, nil)
-		m.telemetry.Shutdown()
 	})
 }
@@ -0,0 +1,130 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"slices"
+
+	"github.com/caiomarcatti12/nanogo/pkg/env"
+	webserver_openapi "github.com/caiomarcatti12/nanogo/pkg/webserver/openapi"
+	webserver_route "github.com/caiomarcatti12/nanogo/pkg/webserver/routes"
+	webserver_types "github.com/caiomarcatti12/nanogo/pkg/webserver/types"
+)
+
+var (
+	responseWriterType = reflect.TypeOf((*http.ResponseWriter)(nil)).Elem()
+	requestType        = reflect.TypeOf((*http.Request)(nil))
+)
+
+// OpenAPI retorna o documento OpenAPI 3 gerado a partir das rotas registradas.
+func (ws *WebServer) OpenAPI() *webserver_openapi.Document {
+	return ws.openapi.Document()
+}
+
+// serveOpenAPI publica o documento e a Swagger UI nos caminhos configurados.
+// Desligado por padrão: o documento expõe todas as rotas da aplicação.
+func (ws *WebServer) serveOpenAPI(env env.IEnv) {
+	if !env.GetEnvBool("WEB_SERVER_OPENAPI_ENABLED", "false") {
+		return
+	}
+
+	specPath := env.GetEnv("WEB_SERVER_OPENAPI_PATH", "/openapi.json")
+	uiPath := env.GetEnv("WEB_SERVER_SWAGGER_PATH", "/docs")
+
+	ws.logger.Trace(ws.i18n.Get("webserver.openapi_enabled", map[string]interface{}{"spec": specPath, "ui": uiPath}))
+
+	ws.router.HandleFunc(specPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ws.OpenAPI())
+	}).Methods(http.MethodGet)
+
+	ws.router.HandleFunc(uiPath, webserver_route.SwaggerUIHandler(ws.openapi.Title(), specPath, webserver_route.SwaggerUIAssets{
+		CSSIntegrity: env.GetEnv("WEB_SERVER_SWAGGER_CSS_INTEGRITY", ""),
+		JSIntegrity:  env.GetEnv("WEB_SERVER_SWAGGER_JS_INTEGRITY", ""),
+	})).Methods(http.MethodGet)
+}
+
+// documentRoute registra a rota no gerador OpenAPI com os tipos vinculados da
+// requisição e o tipo da resposta.
+func (ws *WebServer) documentRoute(path string, route webserver_types.Route, inputs []reflect.Type, output reflect.Type) {
+	ws.openapi.Add(webserver_openapi.Endpoint{
+		Method:  route.Method,
+		Path:    path,
+		Name:    route.Name,
+		Summary: route.Summary,
+		Tags:    route.Tags,
+		Inputs:  inputs,
+		Output:  output,
+		Secured: len(route.Roles) > 0 || len(route.Scopes) > 0,
+		RateLimited: (!ws.rateLimit.IsEmpty() && !slices.Contains(route.SkipMiddlewares, "RateLimitMiddleware")) ||
+			(route.RateLimit != nil && !route.RateLimit.IsEmpty()),
+	})
+}
+
+// handlerSignature lê por reflexão os parâmetros e o retorno do método
+// HandlerFunc do tipo criado pelo IHandler. Um método inexistente é registrado
+// no log já no AddRoute, em vez de aparecer só na primeira requisição.
+func (ws *WebServer) handlerSignature(route webserver_types.Route) ([]reflect.Type, reflect.Type) {
+	handlerType, method, ok := handlerMethod(route)
+	if handlerType == nil {
+		return nil, nil
+	}
+
+	if !ok {
+		ws.logger.Error(ws.i18n.Get("webserver.method_not_found", map[string]interface{}{"method": route.HandlerFunc, "path": route.Path}))
+		return nil, nil
+	}
+
+	methodType := method.Type
+
+	// Em tipos concretos o primeiro parâmetro é o receiver.
+	first := 1
+	if handlerType.Kind() == reflect.Interface {
+		first = 0
+	}
+
+	var inputs []reflect.Type
+	for i := first; i < methodType.NumIn(); i++ {
+		if paramType := methodType.In(i); paramType != responseWriterType && paramType != requestType && paramType != streamType {
+			inputs = append(inputs, paramType)
+		}
+	}
+
+	var output reflect.Type
+	if methodType.NumOut() == 2 {
+		output = methodType.Out(0)
+	}
+
+	return inputs, output
+}
+
+// handlerMethod retorna o tipo criado pelo IHandler e o método HandlerFunc. O
+// tipo é nil quando IHandler não é uma fábrica.
+func handlerMethod(route webserver_types.Route) (reflect.Type, reflect.Method, bool) {
+	factoryType := reflect.TypeOf(route.IHandler)
+
+	if factoryType == nil || factoryType.Kind() != reflect.Func || factoryType.NumOut() == 0 {
+		return nil, reflect.Method{}, false
+	}
+
+	handlerType := factoryType.Out(0)
+	method, ok := handlerType.MethodByName(route.HandlerFunc)
+
+	return handlerType, method, ok
+}
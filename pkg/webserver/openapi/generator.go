@@ -0,0 +1,426 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_openapi
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/caiomarcatti12/nanogo/pkg/jwt"
+	"github.com/caiomarcatti12/nanogo/pkg/types"
+	webserver_binding "github.com/caiomarcatti12/nanogo/pkg/webserver/binding"
+)
+
+var (
+	responseType = reflect.TypeOf(types.Response{})
+	claimsType   = reflect.TypeOf(jwt.Claims{})
+)
+
+// Endpoint descreve uma rota registrada no webserver. Inputs são os tipos
+// vinculados a partir da requisição e Output o primeiro retorno do handler
+// (nil quando o handler retorna apenas error). Secured indica uma rota com
+// papéis ou escopos exigidos e RateLimited uma rota sujeita a limite de
+// requisições.
+type Endpoint struct {
+	Method      string
+	Path        string
+	Name        string
+	Summary     string
+	Tags        []string
+	Inputs      []reflect.Type
+	Output      reflect.Type
+	Secured     bool
+	RateLimited bool
+}
+
+// Generator acumula os endpoints e monta o documento OpenAPI sob demanda,
+// de modo que rotas adicionadas depois do Start também aparecem.
+type Generator struct {
+	title     string
+	version   string
+	endpoints []Endpoint
+	legacy    bool
+	mu        sync.RWMutex
+}
+
+func NewGenerator(title string, version string) *Generator {
+	return &Generator{title: title, version: version}
+}
+
+// ProblemDetails define se as respostas de erro são documentadas como
+// application/problem+json (RFC 7807, padrão) ou no formato {"error": ...}.
+func (g *Generator) ProblemDetails(enabled bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.legacy = !enabled
+}
+
+func (g *Generator) Title() string {
+	return g.title
+}
+
+func (g *Generator) Add(endpoint Endpoint) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.endpoints = append(g.endpoints, endpoint)
+}
+
+func (g *Generator) Document() *Document {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	document := &Document{
+		OpenAPI: "3.0.3",
+		Info:    Info{Title: g.title, Version: g.version},
+		Paths:   make(map[string]*PathItem),
+		Components: Components{
+			SecuritySchemes: map[string]*SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+			Schemas: map[string]*Schema{
+				"Error": g.errorSchema(),
+			},
+		},
+	}
+
+	for _, endpoint := range g.endpoints {
+		path, variables := parsePath(endpoint.Path)
+
+		item, ok := document.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			document.Paths[path] = item
+		}
+
+		(*item)[strings.ToLower(endpoint.Method)] = operation(endpoint, variables, g.errorMediaType())
+	}
+
+	return document
+}
+
+func operation(endpoint Endpoint, variables []pathVariable, errorMediaType string) *Operation {
+	op := &Operation{
+		OperationID: endpoint.Name,
+		Summary:     endpoint.Summary,
+		Tags:        endpoint.Tags,
+		Responses:   map[string]*Response{"200": success(endpoint.Output)},
+	}
+
+	builder := newSchemaBuilder(inputNaming)
+	fields, authenticated := inputFields(builder, endpoint.Inputs)
+
+	for _, variable := range variables {
+		schema := &Schema{Type: "string"}
+
+		if key, ok := pathField(fields, variable.name); ok {
+			field := fields[key]
+			schema = builder.schema(field.Type)
+			applyValidation(schema, field)
+			delete(fields, key)
+		}
+
+		if variable.pattern != "" {
+			schema.Pattern = "^" + variable.pattern + "$"
+		}
+
+		op.Parameters = append(op.Parameters, &Parameter{Name: variable.name, In: "path", Required: true, Schema: schema})
+	}
+
+	body := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	var wholeBody *Schema
+	multipart := false
+	form := false
+
+	for _, key := range sortedNames(fields) {
+		field := fields[key]
+		source, name, tagged := webserver_binding.Source(field)
+
+		if tagged && name == "-" {
+			continue
+		}
+
+		// Campos com body:"" recebem o corpo inteiro, que passa pelo encoding/json.
+		if source == webserver_binding.TagBody && name == "" {
+			wholeBody = newSchemaBuilder(outputNaming).schema(field.Type)
+			continue
+		}
+
+		schema := builder.schema(field.Type)
+		required := applyValidation(schema, field)
+
+		if !tagged {
+			name = key
+			// Sem tag, só as requisições GET documentam o campo na query string.
+			if endpoint.Method == http.MethodGet {
+				source = webserver_binding.TagQuery
+			}
+		}
+
+		switch source {
+		case webserver_binding.TagPath:
+			// A variável não existe no template e o campo nunca é preenchido.
+			continue
+		case webserver_binding.TagHeader:
+			op.Parameters = append(op.Parameters, &Parameter{Name: name, In: "header", Required: required, Schema: schema})
+			continue
+		case webserver_binding.TagQuery:
+			op.Parameters = append(op.Parameters, &Parameter{Name: name, In: "query", Required: required, Schema: schema})
+			continue
+		case webserver_binding.TagForm:
+			form = true
+		}
+
+		if schema.Format == "binary" || (schema.Items != nil && schema.Items.Format == "binary") {
+			multipart = true
+		}
+
+		if required {
+			body.Required = append(body.Required, name)
+		}
+
+		body.Properties[name] = schema
+	}
+
+	if wholeBody != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{"application/json": {Schema: wholeBody}},
+		}
+	} else if len(body.Properties) > 0 {
+		contentType := "application/json"
+		if multipart {
+			contentType = "multipart/form-data"
+		} else if form {
+			contentType = "application/x-www-form-urlencoded"
+		}
+
+		op.RequestBody = &RequestBody{
+			Required: len(body.Required) > 0,
+			Content:  map[string]*MediaType{contentType: {Schema: body}},
+		}
+	}
+
+	// Falhas de vínculo e validação só existem quando o handler recebe dados.
+	if len(endpoint.Inputs) > 0 {
+		op.Responses["400"] = failure(errorMediaType, "Bad Request")
+	}
+
+	// Campos com claims do token indicam uma rota autenticada.
+	if authenticated || endpoint.Secured {
+		op.Security = []map[string][]string{{"bearerAuth": {}}}
+		op.Responses["401"] = failure(errorMediaType, "Unauthorized")
+	}
+
+	if endpoint.Secured {
+		op.Responses["403"] = failure(errorMediaType, "Forbidden")
+	}
+
+	// Uploads acima de WEB_SERVER_MAX_UPLOAD_SIZE ou do limite da rota.
+	if multipart {
+		op.Responses["413"] = failure(errorMediaType, "Payload Too Large")
+	}
+
+	if endpoint.RateLimited {
+		op.Responses["429"] = failure(errorMediaType, "Too Many Requests")
+	}
+
+	op.Responses["500"] = failure(errorMediaType, "Internal Server Error")
+
+	return op
+}
+
+// inputFields junta os campos de todos os parâmetros do handler, como faz o
+// vínculo da requisição (webserver_binding.Bind), indexados pelo nome do campo. Campos preenchidos com
+// as claims do token não vêm da requisição e ficam de fora.
+func inputFields(builder *schemaBuilder, inputs []reflect.Type) (map[string]reflect.StructField, bool) {
+	fields := make(map[string]reflect.StructField)
+	authenticated := false
+
+	for _, input := range inputs {
+		for input.Kind() == reflect.Ptr {
+			input = input.Elem()
+		}
+
+		if input.Kind() != reflect.Struct {
+			continue
+		}
+
+		for _, field := range builder.fields(input) {
+			if _, ok := field.Tag.Lookup("claim"); ok || field.Type == claimsType {
+				authenticated = true
+				continue
+			}
+
+			fields[field.Name] = field
+		}
+	}
+
+	return fields, authenticated
+}
+
+func sortedNames(fields map[string]reflect.StructField) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// pathField retorna o campo que recebe a variável do caminho: o que tem a tag
+// path com o nome da variável ou, sem tag de origem, o de mesmo nome Go.
+func pathField(fields map[string]reflect.StructField, variable string) (string, bool) {
+	for key, field := range fields {
+		source, name, tagged := webserver_binding.Source(field)
+		if tagged && source == webserver_binding.TagPath && name == variable {
+			return key, true
+		}
+	}
+
+	if field, ok := fields[variable]; ok {
+		if _, _, tagged := webserver_binding.Source(field); !tagged {
+			return variable, true
+		}
+	}
+
+	return "", false
+}
+
+func success(output reflect.Type) *Response {
+	response := &Response{Description: "OK"}
+
+	if output == nil || output.Kind() == reflect.Interface || output == responseType {
+		return response
+	}
+
+	// Rotas de Server-Sent Events enviam um canal de eventos.
+	if output.Kind() == reflect.Chan {
+		response.Content = map[string]*MediaType{"text/event-stream": {Schema: &Schema{Type: "string"}}}
+		return response
+	}
+
+	response.Content = map[string]*MediaType{
+		"application/json": {Schema: newSchemaBuilder(outputNaming).schema(output)},
+	}
+
+	return response
+}
+
+func failure(mediaType string, description string) *Response {
+	return &Response{
+		Description: description,
+		Content: map[string]*MediaType{
+			mediaType: {Schema: &Schema{Ref: "#/components/schemas/Error"}},
+		},
+	}
+}
+
+func (g *Generator) errorMediaType() string {
+	if g.legacy {
+		return "application/json"
+	}
+
+	return "application/problem+json"
+}
+
+func (g *Generator) errorSchema() *Schema {
+	fieldErrors := &Schema{
+		Type: "array",
+		Items: &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"field":   {Type: "string"},
+				"rule":    {Type: "string"},
+				"param":   {Type: "string"},
+				"message": {Type: "string"},
+			},
+			Required: []string{"field", "rule", "message"},
+		},
+	}
+
+	if g.legacy {
+		return &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"error":   {Type: "string"},
+				"details": {},
+				"errors":  fieldErrors,
+			},
+			Required: []string{"error"},
+		}
+	}
+
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"type":          {Type: "string", Format: "uri-reference"},
+			"title":         {Type: "string"},
+			"status":        {Type: "integer"},
+			"detail":        {Type: "string"},
+			"instance":      {Type: "string", Format: "uri-reference"},
+			"correlationId": {Type: "string"},
+			"errors":        fieldErrors,
+			"details":       {},
+		},
+		Required: []string{"type", "title", "status"},
+	}
+}
+
+type pathVariable struct {
+	name    string
+	pattern string
+}
+
+// parsePath converte o template do mux ({id:[0-9]+}) para o formato OpenAPI
+// ({id}) e retorna as variáveis com a expressão regular, quando houver.
+func parsePath(path string) (string, []pathVariable) {
+	var (
+		result    strings.Builder
+		variables []pathVariable
+	)
+
+	for i := 0; i < len(path); i++ {
+		if path[i] != '{' {
+			result.WriteByte(path[i])
+			continue
+		}
+
+		depth, end := 0, i
+		for ; end < len(path); end++ {
+			if path[end] == '{' {
+				depth++
+			} else if path[end] == '}' {
+				depth--
+				if depth == 0 {
+					break
+				}
+			}
+		}
+
+		name, pattern, _ := strings.Cut(path[i+1:end], ":")
+		variables = append(variables, pathVariable{name: name, pattern: pattern})
+		result.WriteString("{" + name + "}")
+		i = end
+	}
+
+	return result.String(), variables
+}
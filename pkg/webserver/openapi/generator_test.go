@@ -0,0 +1,239 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_openapi
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/caiomarcatti12/nanogo/pkg/jwt"
+	"github.com/caiomarcatti12/nanogo/pkg/types"
+	webserver_types "github.com/caiomarcatti12/nanogo/pkg/webserver/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type createUserRequest struct {
+	ID     string   `validate:"required,uuid"`
+	Name   string   `validate:"required,min=3,max=50"`
+	Email  string   `validate:"email"`
+	Age    int      `validate:"gte=18,lt=130"`
+	Role   string   `validate:"oneof=admin user"`
+	Tags   []string `validate:"max=5,dive,min=2"`
+	Tenant string   `header:"X-Tenant" validate:"required"`
+}
+
+type address struct {
+	Street string `json:"street"`
+}
+
+type user struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	Address   *address  `json:"address,omitempty"`
+	Password  string    `json:"-"`
+	Manager   *user     `json:"manager"`
+}
+
+type listUsersRequest struct {
+	Page int `validate:"required,min=1"`
+}
+
+type uploadRequest struct {
+	File *webserver_types.FileUpload `validate:"required"`
+}
+
+func TestDocumentDescribesPathHeaderAndBody(t *testing.T) {
+	generator := NewGenerator("api", "1.0.0")
+	generator.Add(Endpoint{
+		Method: http.MethodPut,
+		Path:   "/users/{ID:[0-9a-f-]+}",
+		Name:   "users.update",
+		Inputs: []reflect.Type{reflect.TypeOf(createUserRequest{})},
+		Output: reflect.TypeOf(&user{}),
+	})
+
+	document := generator.Document()
+	operation := (*document.Paths["/users/{ID}"])["put"]
+
+	assert.Equal(t, "3.0.3", document.OpenAPI)
+	assert.Equal(t, "users.update", operation.OperationID)
+
+	assert.Len(t, operation.Parameters, 2)
+	assert.Equal(t, "ID", operation.Parameters[0].Name)
+	assert.Equal(t, "path", operation.Parameters[0].In)
+	assert.Equal(t, "uuid", operation.Parameters[0].Schema.Format)
+	assert.Equal(t, "^[0-9a-f-]+$", operation.Parameters[0].Schema.Pattern)
+	assert.Equal(t, &Parameter{Name: "X-Tenant", In: "header", Required: true, Schema: &Schema{Type: "string"}}, operation.Parameters[1])
+
+	body := operation.RequestBody.Content["application/json"].Schema
+	assert.True(t, operation.RequestBody.Required)
+	assert.Equal(t, []string{"Name"}, body.Required)
+	assert.Equal(t, uint64(3), *body.Properties["Name"].MinLength)
+	assert.Equal(t, uint64(50), *body.Properties["Name"].MaxLength)
+	assert.Equal(t, "email", body.Properties["Email"].Format)
+	assert.Equal(t, 18.0, *body.Properties["Age"].Minimum)
+	assert.Equal(t, 130.0, *body.Properties["Age"].Maximum)
+	assert.True(t, body.Properties["Age"].ExclusiveMaximum)
+	assert.Equal(t, []interface{}{"admin", "user"}, body.Properties["Role"].Enum)
+	assert.Equal(t, uint64(5), *body.Properties["Tags"].MaxItems)
+	assert.Equal(t, uint64(2), *body.Properties["Tags"].Items.MinLength)
+	assert.NotContains(t, body.Properties, "Tenant")
+
+	assert.Contains(t, operation.Responses, "400")
+	assert.Contains(t, operation.Responses, "500")
+	assert.Equal(t, "#/components/schemas/Error", operation.Responses["500"].Content["application/problem+json"].Schema.Ref)
+}
+
+func TestDocumentDescribesResponseWithJSONNames(t *testing.T) {
+	generator := NewGenerator("api", "1.0.0")
+	generator.Add(Endpoint{Method: http.MethodGet, Path: "/users/{id}", Output: reflect.TypeOf(user{})})
+
+	operation := (*generator.Document().Paths["/users/{id}"])["get"]
+	schema := operation.Responses["200"].Content["application/json"].Schema
+
+	assert.Equal(t, "string", operation.Parameters[0].Schema.Type)
+	assert.ElementsMatch(t, []string{"id", "createdAt", "address", "manager"}, keys(schema.Properties))
+	assert.Equal(t, "date-time", schema.Properties["createdAt"].Format)
+	assert.True(t, schema.Properties["address"].Nullable)
+	assert.Equal(t, "string", schema.Properties["address"].Properties["street"].Type)
+	assert.Equal(t, &Schema{Type: "object", Nullable: true}, schema.Properties["manager"])
+	assert.NotContains(t, operation.Responses, "400")
+}
+
+func TestDocumentUsesQueryForGetAndMultipartForUploads(t *testing.T) {
+	generator := NewGenerator("api", "1.0.0")
+	generator.Add(Endpoint{Method: http.MethodGet, Path: "/users", Inputs: []reflect.Type{reflect.TypeOf(listUsersRequest{})}})
+	generator.Add(Endpoint{Method: http.MethodPost, Path: "/users", Inputs: []reflect.Type{reflect.TypeOf(uploadRequest{})}, Output: reflect.TypeOf(types.Response{})})
+
+	item := *generator.Document().Paths["/users"]
+
+	assert.Equal(t, &Parameter{Name: "Page", In: "query", Required: true, Schema: &Schema{Type: "integer", Format: "int32", Minimum: floatPtr(1)}}, item["get"].Parameters[0])
+	assert.Nil(t, item["get"].RequestBody)
+
+	upload := item["post"].RequestBody.Content["multipart/form-data"].Schema
+	assert.Equal(t, "binary", upload.Properties["File"].Format)
+	assert.Contains(t, item["post"].Responses, "413")
+	assert.NotContains(t, item["get"].Responses, "413")
+	assert.Nil(t, item["post"].Responses["200"].Content)
+}
+
+type taggedRequest struct {
+	ID     string   `path:"id"`
+	Tags   []string `query:"tags"`
+	Tenant string   `header:"X-Tenant"`
+	User   user     `body:""`
+}
+
+type formRequest struct {
+	Name   string `form:"name" validate:"required"`
+	Hidden string `form:"-"`
+}
+
+func TestDocumentDescribesBindingSourceTags(t *testing.T) {
+	generator := NewGenerator("api", "1.0.0")
+	generator.Add(Endpoint{Method: http.MethodPut, Path: "/users/{id}", Inputs: []reflect.Type{reflect.TypeOf(taggedRequest{})}})
+	generator.Add(Endpoint{Method: http.MethodPost, Path: "/forms", Inputs: []reflect.Type{reflect.TypeOf(formRequest{})}})
+
+	document := generator.Document()
+	op := (*document.Paths["/users/{id}"])["put"]
+
+	require.Len(t, op.Parameters, 3)
+	assert.Equal(t, &Parameter{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "string"}}, op.Parameters[0])
+	assert.Equal(t, &Parameter{Name: "tags", In: "query", Schema: &Schema{Type: "array", Items: &Schema{Type: "string"}}}, op.Parameters[1])
+	assert.Equal(t, "X-Tenant", op.Parameters[2].Name)
+	assert.Equal(t, "header", op.Parameters[2].In)
+
+	userSchema := op.RequestBody.Content["application/json"].Schema
+	assert.Contains(t, keys(userSchema.Properties), "createdAt")
+
+	form := (*document.Paths["/forms"])["post"].RequestBody.Content["application/x-www-form-urlencoded"].Schema
+	assert.Equal(t, []string{"name"}, keys(form.Properties))
+	assert.Equal(t, []string{"name"}, form.Required)
+}
+
+func keys(properties map[string]*Schema) []string {
+	result := make([]string, 0, len(properties))
+	for key := range properties {
+		result = append(result, key)
+	}
+
+	return result
+}
+
+func floatPtr(value float64) *float64 {
+	return &value
+}
+
+type meRequest struct {
+	UserID string `claim:"sub"`
+	Claims jwt.Claims
+	Fields string
+}
+
+func TestDocumentMarksRoutesWithClaimsAsAuthenticated(t *testing.T) {
+	generator := NewGenerator("api", "1.0.0")
+	generator.Add(Endpoint{Method: http.MethodGet, Path: "/me", Inputs: []reflect.Type{reflect.TypeOf(meRequest{})}})
+
+	document := generator.Document()
+	operation := (*document.Paths["/me"])["get"]
+
+	assert.Len(t, operation.Parameters, 1)
+	assert.Equal(t, "Fields", operation.Parameters[0].Name)
+	assert.Equal(t, []map[string][]string{{"bearerAuth": {}}}, operation.Security)
+	assert.Contains(t, operation.Responses, "401")
+	assert.Equal(t, "bearer", document.Components.SecuritySchemes["bearerAuth"].Scheme)
+}
+
+func TestDocumentDescribesForbiddenForSecuredRoutes(t *testing.T) {
+	generator := NewGenerator("api", "1.0.0")
+	generator.Add(Endpoint{Method: http.MethodDelete, Path: "/users/{id}", Secured: true})
+
+	operation := (*generator.Document().Paths["/users/{id}"])["delete"]
+
+	assert.Equal(t, []map[string][]string{{"bearerAuth": {}}}, operation.Security)
+	assert.Contains(t, operation.Responses, "401")
+	assert.Contains(t, operation.Responses, "403")
+}
+
+func TestDocumentDescribesTooManyRequestsForRateLimitedRoutes(t *testing.T) {
+	generator := NewGenerator("api", "1.0.0")
+	generator.Add(Endpoint{Method: http.MethodPost, Path: "/login", RateLimited: true})
+	generator.Add(Endpoint{Method: http.MethodGet, Path: "/ping"})
+
+	document := generator.Document()
+
+	assert.Contains(t, (*document.Paths["/login"])["post"].Responses, "429")
+	assert.NotContains(t, (*document.Paths["/ping"])["get"].Responses, "429")
+}
+
+func TestDocumentDescribesErrorsInConfiguredFormat(t *testing.T) {
+	generator := NewGenerator("api", "1.0.0")
+	generator.Add(Endpoint{Method: http.MethodGet, Path: "/ping"})
+
+	document := generator.Document()
+	assert.Contains(t, document.Components.Schemas["Error"].Properties, "correlationId")
+	assert.Equal(t, []string{"type", "title", "status"}, document.Components.Schemas["Error"].Required)
+
+	generator.ProblemDetails(false)
+
+	document = generator.Document()
+	operation := (*document.Paths["/ping"])["get"]
+	assert.Contains(t, operation.Responses["500"].Content, "application/json")
+	assert.Equal(t, []string{"error"}, document.Components.Schemas["Error"].Required)
+}
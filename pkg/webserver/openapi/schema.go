@@ -0,0 +1,282 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	webserver_types "github.com/caiomarcatti12/nanogo/pkg/webserver/types"
+	"github.com/google/uuid"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	uuidType       = reflect.TypeOf(uuid.UUID{})
+	fileUploadType = reflect.TypeOf(webserver_types.FileUpload{})
+	bytesType      = reflect.TypeOf([]byte(nil))
+)
+
+// naming define como o nome de um campo aparece no documento. A entrada sem
+// tag de origem é vinculada pelo nome do campo Go (webserver_binding.Bind) e a
+// saída é escrita pelo encoding/json, que respeita a tag json.
+type naming int
+
+const (
+	inputNaming naming = iota
+	outputNaming
+)
+
+type schemaBuilder struct {
+	naming   naming
+	visiting map[reflect.Type]bool
+}
+
+func newSchemaBuilder(naming naming) *schemaBuilder {
+	return &schemaBuilder{naming: naming, visiting: make(map[reflect.Type]bool)}
+}
+
+func (b *schemaBuilder) schema(t reflect.Type) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+
+	schema := b.schemaOf(t)
+	schema.Nullable = nullable && schema.Ref == ""
+
+	return schema
+}
+
+func (b *schemaBuilder) schemaOf(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case uuidType:
+		return &Schema{Type: "string", Format: "uuid"}
+	case fileUploadType:
+		return &Schema{Type: "string", Format: "binary"}
+	case bytesType:
+		return &Schema{Type: "string", Format: "byte"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: b.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schema(t.Elem())}
+	case reflect.Struct:
+		return b.structSchema(t)
+	default:
+		// interface{} e tipos sem representação em JSON aceitam qualquer valor.
+		return &Schema{}
+	}
+}
+
+func (b *schemaBuilder) structSchema(t reflect.Type) *Schema {
+	// Tipos recursivos são descritos apenas como objeto no segundo nível.
+	if b.visiting[t] {
+		return &Schema{Type: "object"}
+	}
+
+	b.visiting[t] = true
+	defer delete(b.visiting, t)
+
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+
+	for _, field := range b.fields(t) {
+		fieldSchema := b.schema(field.Type)
+
+		if applyValidation(fieldSchema, field) {
+			schema.Required = append(schema.Required, b.name(field))
+		}
+
+		schema.Properties[b.name(field)] = fieldSchema
+	}
+
+	return schema
+}
+
+// fields retorna os campos exportados da struct, incluindo os promovidos por
+// structs embutidas, na ordem de declaração.
+func (b *schemaBuilder) fields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if !field.IsExported() || b.name(field) == "-" {
+			continue
+		}
+
+		embedded := field.Type
+		if embedded.Kind() == reflect.Ptr {
+			embedded = embedded.Elem()
+		}
+
+		if field.Anonymous && embedded.Kind() == reflect.Struct && !b.tagged(field) {
+			fields = append(fields, b.fields(embedded)...)
+			continue
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+func (b *schemaBuilder) name(field reflect.StructField) string {
+	if b.naming == inputNaming {
+		return field.Name
+	}
+
+	if name := strings.Split(field.Tag.Get("json"), ",")[0]; name != "" {
+		return name
+	}
+
+	return field.Name
+}
+
+func (b *schemaBuilder) tagged(field reflect.StructField) bool {
+	return b.naming == outputNaming && strings.Split(field.Tag.Get("json"), ",")[0] != ""
+}
+
+// applyValidation traduz as regras da tag validate (go-playground/validator)
+// para restrições do schema. Retorna true quando o campo é obrigatório.
+func applyValidation(schema *Schema, field reflect.StructField) bool {
+	required := false
+	target := schema
+
+	for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+		name, param, _ := strings.Cut(rule, "=")
+
+		switch name {
+		case "required":
+			if target == schema {
+				required = true
+			}
+		case "dive":
+			// As regras seguintes valem para os itens da lista.
+			if target.Items == nil {
+				return required
+			}
+			target = target.Items
+		case "email":
+			target.Format = "email"
+		case "uuid", "uuid4":
+			target.Format = "uuid"
+		case "url", "uri":
+			target.Format = "uri"
+		case "ipv4", "ipv6":
+			target.Format = name
+		case "oneof":
+			target.Enum = enum(target, strings.Fields(param))
+		case "min", "gte":
+			limit(target, param, true, false)
+		case "max", "lte":
+			limit(target, param, false, false)
+		case "gt":
+			limit(target, param, true, true)
+		case "lt":
+			limit(target, param, false, true)
+		case "len":
+			limit(target, param, true, false)
+			limit(target, param, false, false)
+		}
+	}
+
+	return required
+}
+
+// limit aplica um limite conforme o tipo: valor para números, tamanho para
+// strings e quantidade de itens para listas.
+func limit(schema *Schema, param string, lower bool, exclusive bool) {
+	value, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+
+	switch schema.Type {
+	case "integer", "number":
+		if lower {
+			schema.Minimum = &value
+			schema.ExclusiveMinimum = exclusive
+		} else {
+			schema.Maximum = &value
+			schema.ExclusiveMaximum = exclusive
+		}
+	case "string":
+		length := lengthOf(value, lower, exclusive)
+		if lower {
+			schema.MinLength = &length
+		} else {
+			schema.MaxLength = &length
+		}
+	case "array":
+		length := lengthOf(value, lower, exclusive)
+		if lower {
+			schema.MinItems = &length
+		} else {
+			schema.MaxItems = &length
+		}
+	}
+}
+
+func lengthOf(value float64, lower bool, exclusive bool) uint64 {
+	length := uint64(value)
+
+	if exclusive && lower {
+		return length + 1
+	}
+
+	if exclusive && length > 0 {
+		return length - 1
+	}
+
+	return length
+}
+
+func enum(schema *Schema, values []string) []interface{} {
+	result := make([]interface{}, 0, len(values))
+
+	for _, value := range values {
+		if schema.Type == "integer" || schema.Type == "number" {
+			if number, err := strconv.ParseFloat(value, 64); err == nil {
+				result = append(result, number)
+				continue
+			}
+		}
+
+		result = append(result, value)
+	}
+
+	return result
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServeOpenAPI_DisabledByDefault(t *testing.T) {
+	ws := newTestWebServer(t, testEnv{})
+	ws.serveOpenAPI(testEnv{})
+
+	assert.Equal(t, http.StatusNotFound, serve(ws, httptest.NewRequest(http.MethodGet, "/openapi.json", nil)).Code)
+	assert.Equal(t, http.StatusNotFound, serve(ws, httptest.NewRequest(http.MethodGet, "/docs", nil)).Code)
+}
+
+func TestServeOpenAPI_SwaggerUIPinsAssets(t *testing.T) {
+	env := testEnv{
+		"WEB_SERVER_OPENAPI_ENABLED":       "true",
+		"WEB_SERVER_SWAGGER_CSS_INTEGRITY": "sha384-css",
+		"WEB_SERVER_SWAGGER_JS_INTEGRITY":  "sha384-js",
+	}
+	ws := newTestWebServer(t, env)
+	ws.serveOpenAPI(env)
+
+	assert.Equal(t, http.StatusOK, serve(ws, httptest.NewRequest(http.MethodGet, "/openapi.json", nil)).Code)
+
+	body := serve(ws, httptest.NewRequest(http.MethodGet, "/docs", nil)).Body.String()
+
+	assert.Contains(t, body, `href="https://unpkg.com/swagger-ui-dist@5.17.14/swagger-ui.css" integrity="sha384-css" crossorigin="anonymous"`)
+	assert.Contains(t, body, `src="https://unpkg.com/swagger-ui-dist@5.17.14/swagger-ui-bundle.js" integrity="sha384-js" crossorigin="anonymous"`)
+}
@@ -0,0 +1,179 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_problem
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strings"
+
+	"github.com/caiomarcatti12/nanogo/pkg/env"
+	"github.com/caiomarcatti12/nanogo/pkg/errors"
+)
+
+const (
+	// FormatProblem escreve os erros como application/problem+json (RFC 7807).
+	FormatProblem = "problem"
+	// FormatJSON mantém o formato anterior: {"error": "mensagem"}.
+	FormatJSON = "json"
+
+	ContentType = "application/problem+json"
+)
+
+// Problem é o corpo de erro do RFC 7807, com as extensões correlationId,
+// errors (falhas de validação por campo) e details.
+type Problem struct {
+	Type          string              `json:"type"`
+	Title         string              `json:"title"`
+	Status        int                 `json:"status"`
+	Detail        string              `json:"detail,omitempty"`
+	Instance      string              `json:"instance,omitempty"`
+	CorrelationID string              `json:"correlationId,omitempty"`
+	Errors        []errors.FieldError `json:"errors,omitempty"`
+	Details       interface{}         `json:"details,omitempty"`
+}
+
+// Writer escreve as respostas de erro do webserver no formato configurado
+// por WEB_SERVER_ERROR_FORMAT. WEB_SERVER_PROBLEM_TYPE_URL é prefixado aos
+// Type relativos de errors.CustomError.
+type Writer struct {
+	format  string
+	typeURL string
+}
+
+func NewWriter(env env.IEnv) *Writer {
+	format := strings.ToLower(env.GetEnv("WEB_SERVER_ERROR_FORMAT", FormatProblem))
+	if format != FormatJSON {
+		format = FormatProblem
+	}
+
+	return &Writer{
+		format:  format,
+		typeURL: strings.TrimSuffix(env.GetEnv("WEB_SERVER_PROBLEM_TYPE_URL", ""), "/"),
+	}
+}
+
+func (p *Writer) Format() string {
+	return p.format
+}
+
+// Write converte err em resposta. Erros que não são *errors.CustomError
+// viram 500.
+func (p *Writer) Write(w http.ResponseWriter, r *http.Request, err error) {
+	customError := asCustomError(err)
+
+	if p.format == FormatJSON {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(customError.Code)
+
+		body := map[string]interface{}{"error": customError.Message}
+		if customError.Details != nil {
+			body["details"] = customError.Details
+		}
+		if len(customError.Errors) > 0 {
+			body["errors"] = customError.Errors
+		}
+
+		json.NewEncoder(w).Encode(body)
+		return
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(customError.Code)
+
+	json.NewEncoder(w).Encode(p.Problem(r, w.Header().Get("X-Correlation-ID"), customError))
+}
+
+// Problem monta o corpo RFC 7807 de um CustomError.
+func (p *Writer) Problem(r *http.Request, correlationID string, customError *errors.CustomError) Problem {
+	problem := Problem{
+		Type:          p.problemType(customError.Type),
+		Title:         customError.Title,
+		Status:        customError.Code,
+		Detail:        customError.Message,
+		CorrelationID: correlationID,
+		Errors:        customError.Errors,
+		Details:       customError.Details,
+	}
+
+	if problem.Title == "" {
+		problem.Title = http.StatusText(customError.Code)
+	}
+
+	if r != nil {
+		problem.Instance = r.URL.Path
+	}
+
+	if correlationID == "" && r != nil {
+		problem.CorrelationID = r.Header.Get("X-Correlation-ID")
+	}
+
+	return problem
+}
+
+func (p *Writer) problemType(problemType string) string {
+	if problemType == "" {
+		return "about:blank"
+	}
+
+	if p.typeURL == "" || strings.Contains(problemType, ":") {
+		return problemType
+	}
+
+	return p.typeURL + "/" + strings.TrimPrefix(problemType, "/")
+}
+
+func asCustomError(err error) *errors.CustomError {
+	var customError *errors.CustomError
+	if stderrors.As(err, &customError) {
+		if customError.Code == 0 {
+			copied := *customError
+			copied.Code = http.StatusInternalServerError
+			return &copied
+		}
+		return customError
+	}
+
+	return errors.InternalServerError(err.Error())
+}
+
+type contextKey struct{}
+
+var defaultWriter = &Writer{format: FormatProblem}
+
+// Handler disponibiliza o Writer às rotas e middlewares de next.
+func (p *Writer) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, p)))
+	})
+}
+
+// FromContext retorna o Writer do webserver, ou um Writer no formato padrão
+// quando a requisição não passou pelo webserver.
+func FromContext(ctx context.Context) *Writer {
+	if writer, ok := ctx.Value(contextKey{}).(*Writer); ok {
+		return writer
+	}
+
+	return defaultWriter
+}
+
+// Write escreve err com o Writer da requisição. Use em middlewares.
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	FromContext(r.Context()).Write(w, r, err)
+}
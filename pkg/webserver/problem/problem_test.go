@@ -0,0 +1,96 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_problem
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/caiomarcatti12/nanogo/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeEnv map[string]string
+
+func (e fakeEnv) GetEnv(variable string, defaultValue ...string) string {
+	if value, ok := e[variable]; ok {
+		return value
+	}
+	if len(defaultValue) > 0 {
+		return defaultValue[0]
+	}
+	return ""
+}
+
+func (e fakeEnv) GetEnvBool(variable string, defaultValue ...string) bool {
+	return e.GetEnv(variable, defaultValue...) == "true"
+}
+
+func TestWriteProblemDetails(t *testing.T) {
+	writer := NewWriter(fakeEnv{"WEB_SERVER_PROBLEM_TYPE_URL": "https://errors.example.com/"})
+
+	request := httptest.NewRequest(http.MethodPost, "/users", nil)
+	recorder := httptest.NewRecorder()
+	recorder.Header().Set("X-Correlation-ID", "abc")
+
+	customError := &errors.CustomError{
+		Code:    http.StatusBadRequest,
+		Message: "field Name is required",
+		Type:    "validation",
+		Errors:  []errors.FieldError{{Field: "Name", Rule: "required", Message: "field Name is required"}},
+	}
+	writer.Write(recorder, request, customError)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, ContentType, recorder.Header().Get("Content-Type"))
+
+	var problem Problem
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+	assert.Equal(t, "https://errors.example.com/validation", problem.Type)
+	assert.Equal(t, "Bad Request", problem.Title)
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.Equal(t, "field Name is required", problem.Detail)
+	assert.Equal(t, "/users", problem.Instance)
+	assert.Equal(t, "abc", problem.CorrelationID)
+	assert.Equal(t, customError.Errors, problem.Errors)
+}
+
+func TestWriteTreatsUnknownErrorsAsInternal(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	Write(recorder, httptest.NewRequest(http.MethodGet, "/", nil), fmt.Errorf("boom"))
+
+	var problem Problem
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Equal(t, "about:blank", problem.Type)
+	assert.Equal(t, "boom", problem.Detail)
+}
+
+func TestWriteLegacyJSON(t *testing.T) {
+	writer := NewWriter(fakeEnv{"WEB_SERVER_ERROR_FORMAT": "json"})
+	recorder := httptest.NewRecorder()
+
+	writer.Write(recorder, httptest.NewRequest(http.MethodGet, "/", nil), errors.Forbidden("denied"))
+
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"error": "denied"}`, recorder.Body.String())
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver
+
+import (
+	webserver_middleware "github.com/caiomarcatti12/nanogo/pkg/webserver/middleware"
+	webserver_types "github.com/caiomarcatti12/nanogo/pkg/webserver/types"
+	"github.com/gorilla/mux"
+)
+
+// RouteGroup registra rotas em um subrouter do mux com o prefixo do grupo.
+type RouteGroup struct {
+	ws     *WebServer
+	prefix string
+	router *mux.Router
+}
+
+func newRouteGroup(ws *WebServer, parent *mux.Router, prefix string, middlewares []webserver_middleware.IMiddleware) *RouteGroup {
+	group := &RouteGroup{
+		ws:     ws,
+		prefix: prefix,
+		router: parent.PathPrefix(prefix).Subrouter(),
+	}
+
+	for _, middleware := range middlewares {
+		group.AddMidleware(middleware)
+	}
+
+	return group
+}
+
+func (g *RouteGroup) AddMidleware(middleware webserver_middleware.IMiddleware) {
+	g.ws.logger.Trace(g.ws.i18n.Get("webserver.add_group_middleware", map[string]interface{}{"middleware": middleware.GetName(), "prefix": g.prefix}))
+	g.router.Use(g.ws.middlewareFunc(middleware))
+}
+
+// AddRoute registra a rota com o caminho relativo ao prefixo do grupo.
+func (g *RouteGroup) AddRoute(route webserver_types.Route) {
+	g.ws.addRoute(g.router, g.prefix, route)
+}
+
+// AddSSERoute registra a rota de Server-Sent Events relativa ao prefixo do grupo.
+func (g *RouteGroup) AddSSERoute(route webserver_types.Route) {
+	g.ws.addSSERoute(g.router, g.prefix, route)
+}
+
+// Group cria um grupo aninhado; o prefixo é somado ao do grupo atual e os
+// middlewares deste grupo também são executados nas rotas do grupo aninhado.
+func (g *RouteGroup) Group(prefix string, middlewares ...webserver_middleware.IMiddleware) IRouteGroup {
+	group := newRouteGroup(g.ws, g.router, prefix, middlewares)
+	group.prefix = g.prefix + prefix
+
+	return group
+}
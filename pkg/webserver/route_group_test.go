@@ -0,0 +1,64 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup_ConcatenatesNestedPrefixes(t *testing.T) {
+	ws := newTestWebServer(t, testEnv{})
+
+	Handle(ws.Group("/api").Group("/v1"), http.MethodGet, "/users", ok)
+
+	assert.Equal(t, http.StatusOK, serve(ws, httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)).Code)
+	assert.Equal(t, http.StatusNotFound, serve(ws, httptest.NewRequest(http.MethodGet, "/v1/users", nil)).Code)
+	assert.Contains(t, ws.OpenAPI().Paths, "/api/v1/users")
+}
+
+func TestGroup_MiddlewaresRunOnlyOnGroupRoutes(t *testing.T) {
+	ws := newTestWebServer(t, testEnv{})
+
+	var calls []string
+	api := ws.Group("/api", recordingMiddleware{name: "Api", calls: &calls})
+	admin := api.Group("/admin", recordingMiddleware{name: "Admin", calls: &calls})
+
+	Handle(ws, http.MethodGet, "/public", ok)
+	Handle(api, http.MethodGet, "/users", ok)
+	Handle(admin, http.MethodGet, "/stats", ok)
+
+	tests := []struct {
+		path  string
+		calls []string
+	}{
+		{path: "/public", calls: nil},
+		{path: "/api/users", calls: []string{"Api"}},
+		{path: "/api/admin/stats", calls: []string{"Api", "Admin"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			calls = nil
+
+			assert.Equal(t, http.StatusOK, serve(ws, httptest.NewRequest(http.MethodGet, tt.path, nil)).Code)
+			assert.Equal(t, tt.calls, calls)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_route
+
+import (
+	"net/http"
+
+	"github.com/caiomarcatti12/nanogo/pkg/health"
+	"github.com/caiomarcatti12/nanogo/pkg/types"
+)
+
+type ILivenessController interface {
+	Handler(r *http.Request) (interface{}, error)
+}
+
+// LivenessController responde o /healthz/livez com as verificações marcadas
+// com Liveness. Sem elas, responde 200 enquanto o processo atender requisições.
+type LivenessController struct {
+	registry health.IRegistry
+}
+
+func NewLivenessController(registry health.IRegistry) ILivenessController {
+	return &LivenessController{registry: registry}
+}
+
+func (lc *LivenessController) Handler(r *http.Request) (interface{}, error) {
+	return reportResponse(lc.registry.Liveness(r.Context())), nil
+}
+
+type IStartupController interface {
+	Handler(r *http.Request) (interface{}, error)
+}
+
+// StartupController responde o /healthz/startupz com 503 até que todas as
+// verificações críticas tenham passado pelo menos uma vez.
+type StartupController struct {
+	registry health.IRegistry
+}
+
+func NewStartupController(registry health.IRegistry) IStartupController {
+	return &StartupController{registry: registry}
+}
+
+func (sc *StartupController) Handler(r *http.Request) (interface{}, error) {
+	return reportResponse(sc.registry.Startup(r.Context())), nil
+}
+
+// reportResponse escreve o relatório em JSON, com 503 quando alguma
+// verificação crítica falhou. Relatórios degraded continuam com 200.
+func reportResponse(report health.Report) types.Response {
+	statusCode := http.StatusOK
+
+	if report.Status == health.StatusDown {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	return types.Response{
+		StatusCode: statusCode,
+		Headers: map[string]string{
+			"Content-Type":  "application/json",
+			"Cache-Control": "no-store",
+		},
+		Data: report,
+	}
+}
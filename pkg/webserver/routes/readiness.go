@@ -0,0 +1,83 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_route
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/caiomarcatti12/nanogo/pkg/health"
+	"github.com/caiomarcatti12/nanogo/pkg/i18n"
+)
+
+// IReadiness guarda se a aplicação está apta a receber tráfego. O App marca a
+// aplicação como indisponível ao receber SIGTERM, antes de drenar as requisições.
+type IReadiness interface {
+	IsReady() bool
+	SetReady(ready bool)
+}
+
+type Readiness struct {
+	ready atomic.Bool
+}
+
+func NewReadiness() IReadiness {
+	readiness := &Readiness{}
+	readiness.ready.Store(true)
+
+	return readiness
+}
+
+func (r *Readiness) IsReady() bool {
+	return r.ready.Load()
+}
+
+func (r *Readiness) SetReady(ready bool) {
+	r.ready.Store(ready)
+}
+
+type IReadinessController interface {
+	Handler(r *http.Request) (interface{}, error)
+}
+
+// ReadinessController responde o /healthz/readyz com o resultado de todas as
+// verificações do health.IRegistry. Depois do SIGTERM responde 503 sem
+// executá-las.
+type ReadinessController struct {
+	readiness IReadiness
+	registry  health.IRegistry
+	i18n      i18n.I18N
+}
+
+func NewReadinessController(readiness IReadiness, registry health.IRegistry, i18n i18n.I18N) IReadinessController {
+	return &ReadinessController{readiness: readiness, registry: registry, i18n: i18n}
+}
+
+func (rc *ReadinessController) Handler(r *http.Request) (interface{}, error) {
+	if !rc.readiness.IsReady() {
+		return reportResponse(health.Report{
+			Status: health.StatusDown,
+			Checks: []health.Result{{
+				Name:     "shutdown",
+				Status:   health.StatusDown,
+				Critical: true,
+				Error:    rc.i18n.Get("health.shutting_down"),
+			}},
+		}), nil
+	}
+
+	return reportResponse(rc.registry.Readiness(r.Context())), nil
+}
@@ -13,41 +13,33 @@
  * See the License for the specific language governing permissions and
  * limitations under the License.
  */
-
 package webserver_route
 
-// import (
-// 	"github.com/caiomarcatti12/nanogo/pkg/env"
-// 	"github.com/prometheus/client_golang/prometheus/promhttp"
-// 	"net/http"
-// )
-
-// func MetricsHandler(ctx *HandlerContext[interface{}]) (interface{}, error) {
-// 	handler := promhttp.Handler()
-// 	handler.ServeHTTP(ctx.Response, ctx.Request)
+import (
+	"crypto/subtle"
+	"net/http"
 
-// 	return APIResponse{
-// 		StatusCode: http.StatusOK,
-// 	}, nil
-// }
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
 
-// func MetricsHandlerAuthenticated(ctx *HandlerContext[interface{}]) (interface{}, error) {
-// 	tokenSecret := env.GetEnv("PROMETHEUS_TOKEN", "")
+// MetricsHandler expõe as métricas do registro padrão do Prometheus. Quando
+// token não é vazio, exige o cabeçalho "Authorization: Bearer <token>".
+func MetricsHandler(token string) http.Handler {
+	handler := promhttp.Handler()
 
-// 	if tokenSecret != "" {
-// 		providedToken := ctx.Request.Header.Get("Authorization")
+	if token == "" {
+		return handler
+	}
 
-// 		if providedToken != "Bearer "+tokenSecret {
-// 			return APIResponse{
-// 				StatusCode: http.StatusUnauthorized,
-// 			}, nil
-// 		}
-// 	}
+	expected := []byte("Bearer " + token)
 
-// 	handler := promhttp.Handler()
-// 	handler.ServeHTTP(ctx.Response, ctx.Request)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
 
-// 	return APIResponse{
-// 		StatusCode: http.StatusOK,
-// 	}, nil
-// }
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -15,23 +15,51 @@
  */
 package webserver_route
 
-// import (
-// 	"github.com/caiomarcatti12/nanogo/pkg/env"
-// 	"io/ioutil"
-// 	"net/http"
-// )
+import (
+	"html/template"
+	"net/http"
+)
 
-// func SwaggerHandler(ctx *HandlerContext[any]) (interface{}, error) {
-// 	docsPath := env.GetEnv("SWAGGER_DOCS_PATH", ".docs/swagger/swaggeer.yaml")
+// SwaggerUIVersion é a versão exata do swagger-ui-dist carregada do unpkg. Ao
+// trocá-la, atualize também os hashes de WEB_SERVER_SWAGGER_*_INTEGRITY.
+const SwaggerUIVersion = "5.17.14"
 
-// 	content, err := ioutil.ReadFile(docsPath)
-// 	if err != nil {
-// 		return nil, err
-// 	}
+// SwaggerUIAssets são os hashes SRI (ex.: "sha384-...") dos arquivos da Swagger
+// UI. Com um hash informado, o navegador recusa um arquivo alterado na CDN.
+type SwaggerUIAssets struct {
+	CSSIntegrity string
+	JSIntegrity  string
+}
 
-// 	return &APIResponse{
-// 		Data:       content,
-// 		StatusCode: http.StatusOK,
-// 		Headers:    map[string]string{"Content-Type": "application/x-yaml"},
-// 	}, nil
-// }
+var swaggerUITemplate = template.Must(template.New("swagger").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8" />
+  <title>{{.Title}}</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@{{.Version}}/swagger-ui.css"{{if .CSSIntegrity}} integrity="{{.CSSIntegrity}}"{{end}} crossorigin="anonymous" />
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@{{.Version}}/swagger-ui-bundle.js"{{if .JSIntegrity}} integrity="{{.JSIntegrity}}"{{end}} crossorigin="anonymous"></script>
+  <script>
+    window.onload = () => {
+      window.ui = SwaggerUIBundle({ url: {{.SpecURL}}, dom_id: "#swagger-ui" });
+    };
+  </script>
+</body>
+</html>
+`))
+
+// SwaggerUIHandler serve a Swagger UI apontando para o documento OpenAPI em specURL.
+func SwaggerUIHandler(title string, specURL string, assets SwaggerUIAssets) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		swaggerUITemplate.Execute(w, map[string]string{
+			"Title":        title,
+			"SpecURL":      specURL,
+			"Version":      SwaggerUIVersion,
+			"CSSIntegrity": assets.CSSIntegrity,
+			"JSIntegrity":  assets.JSIntegrity,
+		})
+	}
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+
+	"github.com/caiomarcatti12/nanogo/pkg/di"
+	webserver_sse "github.com/caiomarcatti12/nanogo/pkg/webserver/sse"
+	webserver_types "github.com/caiomarcatti12/nanogo/pkg/webserver/types"
+	"github.com/gorilla/mux"
+)
+
+var (
+	streamType        = reflect.TypeOf((*webserver_sse.IStream)(nil)).Elem()
+	eventsType        = reflect.TypeOf((<-chan webserver_sse.Event)(nil))
+	writableEventType = reflect.TypeOf((chan webserver_sse.Event)(nil))
+	errorType         = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// AddSSERoute registra uma rota de Server-Sent Events (GET quando Method está
+// vazio). O HandlerFunc recebe os parâmetros como nas outras rotas e pode
+// retornar um canal de webserver_sse.Event, enviado até ser fechado, ou
+// receber um webserver_sse.IStream e escrever os eventos com Send. Qualquer
+// outro retorno que não (<-chan Event, error), (chan Event, error) ou error
+// causa panic no registro.
+func (ws *WebServer) AddSSERoute(route webserver_types.Route) {
+	ws.addSSERoute(ws.router, "", route)
+}
+
+func (ws *WebServer) addSSERoute(router *mux.Router, prefix string, route webserver_types.Route) {
+	if route.Method == "" {
+		route.Method = http.MethodGet
+	}
+
+	ws.logger.Trace(ws.i18n.Get("webserver.add_sse_route", map[string]interface{}{"method": route.Method, "path": prefix + route.Path}))
+
+	if _, method, ok := handlerMethod(route); ok && !isSSEResult(method.Type) {
+		ws.invalidRoute(route, prefix, ws.i18n.Get("webserver.sse_invalid_result", map[string]interface{}{"handler": route.HandlerFunc}))
+	}
+
+	ws.di.Register(route.IHandler, di.WithLifetime(route.Lifetime))
+
+	inputs, _ := ws.handlerSignature(route)
+
+	ws.mount(router, prefix, route, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws.streamHandler(w, r, route)
+	}), inputs, eventsType)
+}
+
+// isSSEResult informa se o método retorna (<-chan Event, error),
+// (chan Event, error) ou apenas error.
+func isSSEResult(methodType reflect.Type) bool {
+	switch methodType.NumOut() {
+	case 1:
+		return methodType.Out(0) == errorType
+	case 2:
+		events := methodType.Out(0)
+		return (events == eventsType || events == writableEventType) && methodType.Out(1) == errorType
+	default:
+		return false
+	}
+}
+
+func (ws *WebServer) streamHandler(w http.ResponseWriter, r *http.Request, route webserver_types.Route) {
+	ws.logger.Trace(ws.i18n.Get("webserver.execute_handler", map[string]interface{}{"method": r.Method, "path": r.URL.Path}))
+
+	stream := webserver_sse.NewStream(w, r, ws.sseHeartbeat)
+	defer stream.Close()
+
+	// O Shutdown encerra os streams, que de outra forma nunca terminariam.
+	if ws.streams != nil {
+		defer context.AfterFunc(ws.streams, stream.Close)()
+	}
+
+	r = r.WithContext(webserver_sse.NewContext(r.Context(), stream))
+
+	// O escopo fica aberto até o fim do Pump, já que o produtor dos eventos
+	// ainda usa as dependências scoped depois que o handler retorna.
+	scope := ws.di.CreateScope()
+	defer scope.Close()
+
+	data, err := ws.callHandler(w, r, scope, route, ws.payload(w, r), r.Header)
+
+	// Antes do primeiro evento o erro ainda pode ser respondido normalmente.
+	if err != nil && !stream.Started() {
+		ws.sendJSONError(w, r, err)
+		return
+	}
+
+	if err == nil {
+		switch events := data.(type) {
+		case <-chan webserver_sse.Event:
+			err = stream.Pump(events)
+		case chan webserver_sse.Event:
+			err = stream.Pump(events)
+		default:
+			// Sem eventos, 204 faz o EventSource parar de reconectar.
+			if !stream.Started() {
+				w.WriteHeader(http.StatusNoContent)
+			}
+		}
+	}
+
+	if err != nil {
+		ws.logger.Error(ws.i18n.Get("webserver.sse_failed", map[string]interface{}{"path": r.URL.Path, "error": err}))
+	}
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_sse
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/caiomarcatti12/nanogo/pkg/env"
+)
+
+// subscriberBuffer é quantos eventos um assinante pode acumular antes de ser
+// desconectado por lentidão.
+const subscriberBuffer = 64
+
+// IBroker distribui eventos publicados em um tópico para os streams inscritos,
+// de modo que consumidores de fila ou do IEventDispatcher possam publicar nos
+// streams abertos.
+type IBroker interface {
+	Publish(topic string, event Event)
+	Subscribe(ctx context.Context, topic string, lastEventID string) <-chan Event
+}
+
+type topic struct {
+	subscribers map[chan Event]struct{}
+	history     []Event
+	sequence    uint64
+}
+
+// Broker é o IBroker em memória. Cada tópico guarda os últimos eventos para
+// reenviar a quem reconecta com Last-Event-ID. Um assinante que não acompanha
+// a publicação tem o canal fechado; o EventSource reconecta e recebe o que
+// perdeu pelo histórico.
+type Broker struct {
+	mu      sync.Mutex
+	topics  map[string]*topic
+	history int
+}
+
+// Factory cria o broker com o histórico de WEB_SERVER_SSE_HISTORY eventos por
+// tópico.
+func Factory(env env.IEnv) IBroker {
+	history, err := strconv.Atoi(env.GetEnv("WEB_SERVER_SSE_HISTORY", "100"))
+	if err != nil || history < 0 {
+		history = 100
+	}
+
+	return NewBroker(history)
+}
+
+func NewBroker(history int) *Broker {
+	return &Broker{topics: make(map[string]*topic), history: history}
+}
+
+// Publish envia o evento aos inscritos no tópico. Eventos sem ID recebem um
+// sequencial do tópico, usado na retomada com Last-Event-ID.
+func (b *Broker) Publish(name string, event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	topic := b.topic(name)
+	topic.sequence++
+
+	if event.ID == "" {
+		event.ID = strconv.FormatUint(topic.sequence, 10)
+	}
+
+	if b.history > 0 {
+		topic.history = append(topic.history, event)
+		if len(topic.history) > b.history {
+			topic.history = topic.history[len(topic.history)-b.history:]
+		}
+	}
+
+	for subscriber := range topic.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			delete(topic.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+}
+
+// Subscribe retorna os eventos do tópico até o ctx ser cancelado, quando o
+// canal é fechado. Com lastEventID, os eventos seguintes a ele no histórico
+// são reenviados primeiro; se o ID já saiu do histórico, todo o histórico é
+// reenviado.
+func (b *Broker) Subscribe(ctx context.Context, name string, lastEventID string) <-chan Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	topic := b.topic(name)
+	replay := topic.replay(lastEventID)
+
+	subscriber := make(chan Event, len(replay)+subscriberBuffer)
+	for _, event := range replay {
+		subscriber <- event
+	}
+
+	topic.subscribers[subscriber] = struct{}{}
+
+	context.AfterFunc(ctx, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if _, ok := topic.subscribers[subscriber]; ok {
+			delete(topic.subscribers, subscriber)
+			close(subscriber)
+		}
+	})
+
+	return subscriber
+}
+
+func (b *Broker) topic(name string) *topic {
+	current, ok := b.topics[name]
+	if !ok {
+		current = &topic{subscribers: make(map[chan Event]struct{})}
+		b.topics[name] = current
+	}
+
+	return current
+}
+
+func (t *topic) replay(lastEventID string) []Event {
+	if lastEventID == "" {
+		return nil
+	}
+
+	for i := len(t.history) - 1; i >= 0; i-- {
+		if t.history[i].ID == lastEventID {
+			return append([]Event(nil), t.history[i+1:]...)
+		}
+	}
+
+	return append([]Event(nil), t.history...)
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_sse
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event é uma mensagem do stream. Data é escrito como texto quando for string
+// ou []byte e como JSON nos outros casos; sem Data, o evento só atualiza ID ou
+// Retry no cliente. Retry define o intervalo de reconexão do EventSource.
+type Event struct {
+	ID    string
+	Event string
+	Data  interface{}
+	Retry time.Duration
+}
+
+// WriteTo escreve o evento no formato text/event-stream.
+func (e Event) WriteTo(w io.Writer) (int64, error) {
+	var buffer bytes.Buffer
+
+	if e.ID != "" {
+		buffer.WriteString("id: " + singleLine(e.ID) + "\n")
+	}
+
+	if e.Event != "" {
+		buffer.WriteString("event: " + singleLine(e.Event) + "\n")
+	}
+
+	if e.Retry > 0 {
+		buffer.WriteString("retry: " + strconv.FormatInt(e.Retry.Milliseconds(), 10) + "\n")
+	}
+
+	data, err := e.data()
+	if err != nil {
+		return 0, err
+	}
+
+	if data != nil {
+		// Cada linha do conteúdo vira um campo data; o cliente junta com "\n".
+		text := strings.ReplaceAll(strings.ReplaceAll(string(data), "\r\n", "\n"), "\r", "\n")
+		for _, line := range strings.Split(text, "\n") {
+			buffer.WriteString("data: " + line + "\n")
+		}
+	}
+
+	buffer.WriteString("\n")
+
+	return buffer.WriteTo(w)
+}
+
+func (e Event) data() ([]byte, error) {
+	switch data := e.Data.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []byte(data), nil
+	case []byte:
+		return data, nil
+	default:
+		return json.Marshal(data)
+	}
+}
+
+// singleLine remove quebras de linha, que encerrariam o campo antes da hora.
+func singleLine(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
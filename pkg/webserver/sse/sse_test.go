@@ -0,0 +1,218 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_sse
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventWriteTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    Event
+		expected string
+	}{
+		{"text", Event{Data: "hello"}, "data: hello\n\n"},
+		{"all fields", Event{ID: "7", Event: "update", Retry: 3 * time.Second, Data: "x"}, "id: 7\nevent: update\nretry: 3000\ndata: x\n\n"},
+		{"json", Event{Data: map[string]int{"count": 2}}, "data: {\"count\":2}\n\n"},
+		{"multiline", Event{Data: "a\r\nb\nc"}, "data: a\ndata: b\ndata: c\n\n"},
+		{"newlines in fields", Event{ID: "1\n2", Event: "a\r\nb"}, "id: 12\nevent: ab\n\n"},
+		{"bytes", Event{Data: []byte("raw")}, "data: raw\n\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			_, err := test.event.WriteTo(&buffer)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, buffer.String())
+		})
+	}
+}
+
+func TestStreamSendWritesHeadersOnFirstEvent(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/events", nil)
+	request.Header.Set("Last-Event-ID", "41")
+
+	stream := NewStream(recorder, request, 0)
+	assert.Equal(t, "41", stream.LastEventID())
+	assert.False(t, stream.Started())
+
+	require.NoError(t, stream.Send(Event{ID: "42", Data: "hi"}))
+
+	assert.True(t, stream.Started())
+	assert.True(t, recorder.Flushed)
+	assert.Equal(t, "text/event-stream", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "no-cache", recorder.Header().Get("Cache-Control"))
+	assert.Equal(t, "id: 42\ndata: hi\n\n", recorder.Body.String())
+
+	stream.Close()
+	assert.ErrorIs(t, stream.Send(Event{Data: "late"}), ErrClosed)
+	assert.Error(t, stream.Context().Err())
+}
+
+func TestStreamReadsLastEventIDFromQuery(t *testing.T) {
+	stream := NewStream(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/events?lastEventId=9", nil), 0)
+	assert.Equal(t, "9", stream.LastEventID())
+}
+
+func TestStreamPumpStopsWhenChannelCloses(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	stream := NewStream(recorder, httptest.NewRequest(http.MethodGet, "/events", nil), 0)
+
+	events := make(chan Event, 2)
+	events <- Event{Data: "a"}
+	events <- Event{Data: "b"}
+	close(events)
+
+	require.NoError(t, stream.Pump(events))
+	assert.Equal(t, "data: a\n\ndata: b\n\n", recorder.Body.String())
+}
+
+func TestStreamPumpStopsWhenClientDisconnects(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	request := httptest.NewRequest(http.MethodGet, "/events", nil).WithContext(ctx)
+	stream := NewStream(httptest.NewRecorder(), request, 0)
+
+	done := make(chan error)
+	go func() { done <- stream.Pump(make(chan Event)) }()
+
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Pump did not return after the client disconnected")
+	}
+}
+
+func TestStreamSendsHeartbeats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stream := NewStream(w, r, 10*time.Millisecond)
+		defer stream.Close()
+
+		require.NoError(t, stream.Open())
+		time.Sleep(50 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	response, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	var body bytes.Buffer
+	_, err = body.ReadFrom(response.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+	assert.GreaterOrEqual(t, strings.Count(body.String(), ": heartbeat\n\n"), 2)
+}
+
+func TestBrokerPublishesToSubscribers(t *testing.T) {
+	broker := NewBroker(10)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	events := broker.Subscribe(ctx, "orders", "")
+	other := broker.Subscribe(ctx, "users", "")
+
+	broker.Publish("orders", Event{Data: "created"})
+	broker.Publish("orders", Event{ID: "custom", Data: "paid"})
+
+	assert.Equal(t, Event{ID: "1", Data: "created"}, <-events)
+	assert.Equal(t, Event{ID: "custom", Data: "paid"}, <-events)
+	assert.Empty(t, other)
+
+	cancel()
+
+	assert.Eventually(t, func() bool {
+		_, open := <-events
+		return !open
+	}, time.Second, time.Millisecond)
+}
+
+func TestBrokerReplaysAfterLastEventID(t *testing.T) {
+	broker := NewBroker(3)
+
+	for _, data := range []string{"a", "b", "c", "d"} {
+		broker.Publish("orders", Event{Data: data})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	resumed := broker.Subscribe(ctx, "orders", "3")
+	require.Len(t, resumed, 1)
+	assert.Equal(t, "d", (<-resumed).Data)
+
+	// O ID 1 já saiu do histórico de 3 eventos: todo o histórico é reenviado.
+	expired := broker.Subscribe(ctx, "orders", "1")
+	require.Len(t, expired, 3)
+	assert.Equal(t, "b", (<-expired).Data)
+}
+
+func TestBrokerDisconnectsSlowSubscribers(t *testing.T) {
+	broker := NewBroker(0)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := broker.Subscribe(ctx, "orders", "")
+
+	for i := 0; i <= subscriberBuffer; i++ {
+		broker.Publish("orders", Event{Data: i})
+	}
+
+	received := 0
+	for range events {
+		received++
+	}
+
+	assert.Equal(t, subscriberBuffer, received)
+}
+
+func TestFactoryReadsHistory(t *testing.T) {
+	broker := Factory(fakeEnv{"WEB_SERVER_SSE_HISTORY": "5"}).(*Broker)
+	assert.Equal(t, 5, broker.history)
+
+	broker = Factory(fakeEnv{"WEB_SERVER_SSE_HISTORY": "x"}).(*Broker)
+	assert.Equal(t, 100, broker.history)
+}
+
+type fakeEnv map[string]string
+
+func (e fakeEnv) GetEnv(variable string, defaultValue ...string) string {
+	if value, ok := e[variable]; ok {
+		return value
+	}
+	if len(defaultValue) > 0 {
+		return defaultValue[0]
+	}
+	return ""
+}
+
+func (e fakeEnv) GetEnvBool(variable string, defaultValue ...string) bool {
+	return e.GetEnv(variable, defaultValue...) == "true"
+}
@@ -0,0 +1,245 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_sse
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrClosed indica um stream encerrado pelo cliente, pelo Shutdown do servidor
+// ou pelo fim do handler.
+var ErrClosed = errors.New("event stream closed")
+
+// IStream escreve eventos na resposta. Context é cancelado quando o cliente
+// desconecta ou o stream é encerrado, e LastEventID é o último ID recebido pelo
+// cliente antes de reconectar (cabeçalho Last-Event-ID).
+type IStream interface {
+	Send(event Event) error
+	Context() context.Context
+	LastEventID() string
+}
+
+// Stream é a resposta text/event-stream de uma requisição. Os cabeçalhos são
+// escritos no primeiro Send (ou em Open), de modo que o handler ainda pode
+// responder com erro antes disso. Enquanto aberto, um comentário é enviado a
+// cada heartbeat para manter a conexão viva em proxies.
+type Stream struct {
+	writer      http.ResponseWriter
+	controller  *http.ResponseController
+	heartbeat   time.Duration
+	lastEventID string
+	ctx         context.Context
+	cancel      context.CancelFunc
+	mu          sync.Mutex
+	started     bool
+	closed      bool
+}
+
+// NewStream cria o stream da requisição. heartbeat zero desliga os comentários
+// periódicos.
+func NewStream(w http.ResponseWriter, r *http.Request, heartbeat time.Duration) *Stream {
+	ctx, cancel := context.WithCancel(r.Context())
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		// Polyfills do EventSource que não enviam cabeçalhos usam a query.
+		lastEventID = r.URL.Query().Get("lastEventId")
+	}
+
+	return &Stream{
+		writer:      w,
+		controller:  http.NewResponseController(w),
+		heartbeat:   heartbeat,
+		lastEventID: lastEventID,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+func (s *Stream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *Stream) LastEventID() string {
+	return s.lastEventID
+}
+
+// Started informa se os cabeçalhos já foram escritos.
+func (s *Stream) Started() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.started
+}
+
+// Open escreve os cabeçalhos e inicia o heartbeat sem enviar um evento.
+func (s *Stream) Open() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.open()
+}
+
+func (s *Stream) open() error {
+	if s.closed || s.ctx.Err() != nil {
+		return ErrClosed
+	}
+
+	if s.started {
+		return nil
+	}
+
+	header := s.writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	header.Del("Content-Length")
+
+	// O stream dura mais que o WEB_SERVER_WRITE_TIMEOUT das outras rotas.
+	if err := s.controller.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+
+	s.writer.WriteHeader(http.StatusOK)
+	s.started = true
+
+	if err := s.flush(); err != nil {
+		return err
+	}
+
+	if s.heartbeat > 0 {
+		go s.keepAlive()
+	}
+
+	return nil
+}
+
+// Send escreve o evento e o envia imediatamente ao cliente.
+func (s *Stream) Send(event Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.open(); err != nil {
+		return err
+	}
+
+	if _, err := event.WriteTo(s.writer); err != nil {
+		return s.fail(err)
+	}
+
+	return s.flush()
+}
+
+// Pump envia os eventos do canal até ele ser fechado ou o stream encerrado.
+// O cliente desconectar não é erro.
+func (s *Stream) Pump(events <-chan Event) error {
+	err := s.Open()
+
+	for err == nil {
+		select {
+		case <-s.ctx.Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+
+			err = s.Send(event)
+		}
+	}
+
+	if errors.Is(err, ErrClosed) {
+		return nil
+	}
+
+	return err
+}
+
+// Close encerra o stream: o Context é cancelado e nada mais é escrito na
+// resposta. Deve ser chamado antes do handler HTTP retornar.
+func (s *Stream) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	s.cancel()
+}
+
+func (s *Stream) keepAlive() {
+	ticker := time.NewTicker(s.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.comment("heartbeat"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *Stream) comment(text string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+
+	if _, err := s.writer.Write([]byte(": " + singleLine(text) + "\n\n")); err != nil {
+		return s.fail(err)
+	}
+
+	return s.flush()
+}
+
+func (s *Stream) flush() error {
+	if err := s.controller.Flush(); err != nil {
+		return s.fail(err)
+	}
+
+	return nil
+}
+
+// fail encerra o stream depois de uma falha de escrita, em geral o cliente
+// que desconectou.
+func (s *Stream) fail(err error) error {
+	s.closed = true
+	s.cancel()
+
+	return err
+}
+
+type contextKey struct{}
+
+// NewContext retorna um contexto com o stream da requisição.
+func NewContext(ctx context.Context, stream IStream) context.Context {
+	return context.WithValue(ctx, contextKey{}, stream)
+}
+
+// FromContext retorna o stream das rotas registradas com AddSSERoute.
+func FromContext(ctx context.Context) (IStream, bool) {
+	stream, ok := ctx.Value(contextKey{}).(IStream)
+	return stream, ok
+}
@@ -0,0 +1,240 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/caiomarcatti12/nanogo/pkg/di"
+	"github.com/caiomarcatti12/nanogo/pkg/di/ditest"
+	"github.com/caiomarcatti12/nanogo/pkg/metric"
+	webserver_middleware "github.com/caiomarcatti12/nanogo/pkg/webserver/middleware"
+	webserver_sse "github.com/caiomarcatti12/nanogo/pkg/webserver/sse"
+	webserver_types "github.com/caiomarcatti12/nanogo/pkg/webserver/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type noopMetric struct{}
+
+func (noopMetric) CreateMetric(metric.MetricType, string, string, metric.LabelsKeys) {}
+func (noopMetric) IncrementCounter(string, metric.Labels) error                      { return nil }
+func (noopMetric) SetGauge(string, float64, metric.Labels) error                     { return nil }
+func (noopMetric) ObserveHistogram(string, float64, metric.Labels) error             { return nil }
+func (noopMetric) ObserveSummary(string, float64, metric.Labels) error               { return nil }
+
+type eventsHandler struct {
+	events chan webserver_sse.Event
+}
+
+func (h *eventsHandler) Events() (<-chan webserver_sse.Event, error)  { return h.events, nil }
+func (h *eventsHandler) Writable() (chan webserver_sse.Event, error)  { return h.events, nil }
+func (h *eventsHandler) Send(stream webserver_sse.IStream) error      { return nil }
+func (h *eventsHandler) Values() (<-chan string, error)               { return nil, nil }
+func (h *eventsHandler) Payload() (map[string]interface{}, error)     { return nil, nil }
+func (h *eventsHandler) NoError() <-chan webserver_sse.Event          { return h.events }
+func (h *eventsHandler) Nothing()                                     {}
+func (h *eventsHandler) Swapped() (error, <-chan webserver_sse.Event) { return nil, h.events }
+
+func TestAddSSERoute_ValidatesHandlerResult(t *testing.T) {
+	tests := []struct {
+		handlerFunc string
+		valid       bool
+	}{
+		{handlerFunc: "Events", valid: true},
+		{handlerFunc: "Writable", valid: true},
+		{handlerFunc: "Send", valid: true},
+		{handlerFunc: "Values", valid: false},
+		{handlerFunc: "Payload", valid: false},
+		{handlerFunc: "NoError", valid: false},
+		{handlerFunc: "Nothing", valid: false},
+		{handlerFunc: "Swapped", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.handlerFunc, func(t *testing.T) {
+			ws := newTestWebServer(t, testEnv{})
+
+			message := registrationPanic(func() {
+				ws.AddSSERoute(webserver_types.Route{
+					Path:        "/events",
+					IHandler:    func() *eventsHandler { return &eventsHandler{} },
+					HandlerFunc: tt.handlerFunc,
+				})
+			})
+
+			if tt.valid {
+				assert.Empty(t, message)
+			} else {
+				assert.Contains(t, message, "webserver.sse_invalid_result handler="+tt.handlerFunc)
+			}
+		})
+	}
+}
+
+// readEvent lê as linhas de um evento até a linha em branco que o encerra.
+func readEvent(t *testing.T, reader *bufio.Reader) []string {
+	var lines []string
+
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+
+		line = strings.TrimRight(line, "\n")
+		if line == "" {
+			return lines
+		}
+
+		lines = append(lines, line)
+	}
+}
+
+func TestAddSSERoute_FlushesEachEventThroughMiddlewares(t *testing.T) {
+	env := testEnv{"WEB_SERVER_COMPRESSION_MIN_SIZE": "1"}
+	ws := newTestWebServer(t, env)
+	ws.sseHeartbeat = 0
+
+	ws.AddMidleware(webserver_middleware.NewMetricsMiddleware(env, ditest.Logger{}, ditest.Translator{}, noopMetric{}))
+	ws.AddMidleware(webserver_middleware.NewCompressionMiddleware(env, ws.compressors, ditest.Logger{}, ditest.Translator{}))
+	ws.AddMidleware(webserver_middleware.NewCorrelationIdMiddleware(ditest.Logger{}, ditest.Translator{}))
+	ws.AddMidleware(webserver_middleware.NewRecoveryMiddleware(ws.recoverer, ditest.Logger{}, ditest.Translator{}))
+
+	handler := &eventsHandler{events: make(chan webserver_sse.Event)}
+	ws.Group("/api").AddSSERoute(webserver_types.Route{
+		Path:        "/events",
+		IHandler:    func() *eventsHandler { return handler },
+		HandlerFunc: "Events",
+	})
+
+	server := httptest.NewServer(ws.problem.Handler(ws.router))
+	defer server.Close()
+
+	request, err := http.NewRequest(http.MethodGet, server.URL+"/api/events", nil)
+	require.NoError(t, err)
+	request.Header.Set("Accept", "text/event-stream")
+	request.Header.Set("Accept-Encoding", "gzip")
+
+	// Sem o primeiro evento o handler ainda não escreveu os cabeçalhos.
+	go func() { handler.events <- webserver_sse.Event{ID: "1", Data: "first"} }()
+
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+	assert.Empty(t, response.Header.Get("Content-Encoding"))
+	assert.NotEmpty(t, response.Header.Get("X-Correlation-ID"))
+
+	reader := bufio.NewReader(response.Body)
+
+	// Cada evento precisa chegar antes do próximo ser produzido.
+	assert.Equal(t, []string{"id: 1", "data: first"}, readEvent(t, reader))
+
+	handler.events <- webserver_sse.Event{ID: "2", Event: "update", Data: map[string]int{"count": 2}}
+	assert.Equal(t, []string{"id: 2", "event: update", `data: {"count":2}`}, readEvent(t, reader))
+
+	close(handler.events)
+
+	_, err = reader.ReadString('\n')
+	assert.Error(t, err)
+}
+
+// streamResource é uma dependência scoped descartada pelo Close do escopo.
+type streamResource struct {
+	closed chan struct{}
+}
+
+func (r *streamResource) Close() error {
+	close(r.closed)
+	return nil
+}
+
+type resourceHandler struct {
+	resource *streamResource
+	next     chan struct{}
+}
+
+func (h *resourceHandler) Events() (<-chan webserver_sse.Event, error) {
+	events := make(chan webserver_sse.Event)
+
+	go func() {
+		defer close(events)
+
+		for _, id := range []string{"1", "2"} {
+			<-h.next
+
+			data := "open"
+			select {
+			case <-h.resource.closed:
+				data = "closed"
+			default:
+			}
+
+			events <- webserver_sse.Event{ID: id, Data: data}
+		}
+	}()
+
+	return events, nil
+}
+
+func TestAddSSERoute_KeepsScopeOpenUntilStreamEnds(t *testing.T) {
+	ws := newTestWebServer(t, testEnv{})
+	ws.sseHeartbeat = 0
+
+	resource := &streamResource{closed: make(chan struct{})}
+	next := make(chan struct{})
+
+	require.NoError(t, ws.di.Register(func() *streamResource { return resource }, di.AsScoped()))
+	ws.AddSSERoute(webserver_types.Route{
+		Path: "/events",
+		IHandler: func(resource *streamResource) *resourceHandler {
+			return &resourceHandler{resource: resource, next: next}
+		},
+		HandlerFunc: "Events",
+		Lifetime:    di.Scoped,
+	})
+
+	server := httptest.NewServer(ws.problem.Handler(ws.router))
+	defer server.Close()
+
+	go func() { next <- struct{}{} }()
+
+	response, err := http.Get(server.URL + "/events")
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	reader := bufio.NewReader(response.Body)
+	assert.Equal(t, []string{"id: 1", "data: open"}, readEvent(t, reader))
+
+	// O handler já retornou, mas o produtor ainda usa a dependência.
+	next <- struct{}{}
+	assert.Equal(t, []string{"id: 2", "data: open"}, readEvent(t, reader))
+
+	_, err = reader.ReadString('\n')
+	assert.Error(t, err)
+
+	select {
+	case <-resource.closed:
+	case <-time.After(time.Second):
+		t.Fatal("scope was not closed after the stream ended")
+	}
+}
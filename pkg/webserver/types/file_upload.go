@@ -15,8 +15,35 @@
  */
 package webserver_types
 
+import (
+	"io"
+	"os"
+)
+
+// FileUpload descreve um arquivo enviado em multipart/form-data. O conteúdo
+// não fica em memória: ele é gravado pelo armazenamento de uploads durante a
+// leitura e lido com Open. ContentType é detectado pelos primeiros bytes do
+// conteúdo, e não pelo cabeçalho enviado pelo cliente, e Checksum é o SHA-256
+// em hexadecimal. Location é onde o armazenamento gravou o arquivo.
 type FileUpload struct {
-	Filename string
-	Size     int64
-	Content  []byte
+	Filename    string
+	Size        int64
+	ContentType string
+	Checksum    string
+	Location    string
+	open        func() (io.ReadCloser, error)
+}
+
+// NewFileUpload cria um FileUpload cujo conteúdo é lido com open.
+func NewFileUpload(filename string, open func() (io.ReadCloser, error)) *FileUpload {
+	return &FileUpload{Filename: filename, open: open}
+}
+
+// Open abre o conteúdo do arquivo; quem abre deve fechar.
+func (f *FileUpload) Open() (io.ReadCloser, error) {
+	if f.open == nil {
+		return nil, os.ErrNotExist
+	}
+
+	return f.open()
 }
@@ -15,10 +15,35 @@
  */
 package webserver_types
 
+import (
+	"github.com/caiomarcatti12/nanogo/pkg/di"
+	"github.com/caiomarcatti12/nanogo/pkg/ratelimit"
+)
+
 // Route define a estrutura para rotas no servidor.
+// Lifetime controla como o handler é instanciado pelo DI (singleton por padrão).
+// Name permite montar a URL da rota com IWebServer.URL. Middlewares são
+// executados apenas nesta rota, depois dos middlewares globais e do grupo, e
+// SkipMiddlewares desliga nesta rota os middlewares globais ou do grupo com o
+// GetName informado. Summary e Tags aparecem na operação do documento OpenAPI.
+// Roles e Scopes restringem a rota ao principal autenticado com ao menos um dos
+// papéis e todos os escopos; caso contrário a resposta é 401 ou 403.
+// RateLimit limita as requisições de cada cliente nesta rota; os campos vazios
+// herdam das variáveis RATE_LIMIT_*. Upload limita o tamanho e os tipos dos
+// arquivos enviados nesta rota.
 type Route struct {
-	Path        string
-	Method      string
-	IHandler    interface{}
-	HandlerFunc string
+	Path            string
+	Method          string
+	IHandler        interface{}
+	HandlerFunc     string
+	Lifetime        di.Lifetime
+	Name            string
+	Middlewares     []IMiddleware
+	SkipMiddlewares []string
+	Summary         string
+	Tags            []string
+	Roles           []string
+	Scopes          []string
+	RateLimit       *ratelimit.Policy
+	Upload          *UploadPolicy
 }
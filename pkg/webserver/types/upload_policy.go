@@ -0,0 +1,25 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_types
+
+// UploadPolicy limita os uploads multipart de uma rota. MaxSize é o tamanho
+// máximo do corpo, em bytes, e AllowedTypes os tipos aceitos, detectados pelo
+// conteúdo de cada arquivo (ex.: "image/png" ou "image/*"). Os campos vazios
+// herdam de WEB_SERVER_MAX_UPLOAD_SIZE e WEB_SERVER_UPLOAD_ALLOWED_TYPES.
+type UploadPolicy struct {
+	MaxSize      int64
+	AllowedTypes []string
+}
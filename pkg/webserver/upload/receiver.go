@@ -0,0 +1,301 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_upload
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/caiomarcatti12/nanogo/pkg/env"
+	webserver_types "github.com/caiomarcatti12/nanogo/pkg/webserver/types"
+	"github.com/gorilla/mux"
+)
+
+// sniffLength é a quantidade de bytes usada por http.DetectContentType.
+const sniffLength = 512
+
+// ErrTooLarge indica que o corpo multipart passou do MaxSize da rota.
+var ErrTooLarge = errors.New("upload too large")
+
+// TypeError indica um arquivo com tipo fora de AllowedTypes.
+type TypeError struct {
+	Field       string
+	Filename    string
+	ContentType string
+}
+
+func (e *TypeError) Error() string {
+	return fmt.Sprintf("file %q in field %s has type %s, which is not allowed", e.Filename, e.Field, e.ContentType)
+}
+
+// FormatSize escreve um tamanho em bytes na maior unidade inteira (MB, KB ou
+// bytes), com até uma casa decimal: FormatSize(5 << 20) retorna "5 MB".
+func FormatSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return strconv.FormatFloat(math.Round(float64(size)/(1<<20)*10)/10, 'f', -1, 64) + " MB"
+	case size >= 1<<10:
+		return strconv.FormatFloat(math.Round(float64(size)/(1<<10)*10)/10, 'f', -1, 64) + " KB"
+	default:
+		return strconv.FormatInt(size, 10) + " bytes"
+	}
+}
+
+// Form guarda os campos e os arquivos de um corpo multipart.
+type Form struct {
+	Values  url.Values
+	Files   map[string][]*webserver_types.FileUpload
+	ctx     context.Context
+	storage IStorage
+}
+
+// Release libera os arquivos no armazenamento. Deve ser chamado ao fim da
+// requisição.
+func (f *Form) Release() error {
+	var errs []error
+
+	for _, files := range f.Files {
+		for _, file := range files {
+			errs = append(errs, f.storage.Release(f.ctx, file.Location))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Receiver lê corpos multipart em streaming: cada arquivo vai direto para o
+// IStorage, com o tipo detectado pelo conteúdo e o SHA-256 calculado durante a
+// leitura. Os limites padrão vêm das variáveis de ambiente e podem ser
+// trocados por rota.
+type Receiver struct {
+	mu       sync.RWMutex
+	storage  IStorage
+	defaults webserver_types.UploadPolicy
+	routes   map[*mux.Route]webserver_types.UploadPolicy
+}
+
+// NewReceiver lê WEB_SERVER_MAX_UPLOAD_SIZE (em MB), WEB_SERVER_UPLOAD_ALLOWED_TYPES
+// (separados por vírgula) e WEB_SERVER_UPLOAD_DIR, usado pelo TempStorage.
+func NewReceiver(env env.IEnv) *Receiver {
+	maxSize, err := strconv.ParseInt(env.GetEnv("WEB_SERVER_MAX_UPLOAD_SIZE", "5"), 10, 64)
+	if err != nil || maxSize <= 0 {
+		maxSize = 5
+	}
+
+	var allowedTypes []string
+	for _, contentType := range strings.Split(env.GetEnv("WEB_SERVER_UPLOAD_ALLOWED_TYPES", ""), ",") {
+		if contentType = strings.TrimSpace(contentType); contentType != "" {
+			allowedTypes = append(allowedTypes, contentType)
+		}
+	}
+
+	return &Receiver{
+		storage:  NewTempStorage(env.GetEnv("WEB_SERVER_UPLOAD_DIR", "")),
+		defaults: webserver_types.UploadPolicy{MaxSize: maxSize << 20, AllowedTypes: allowedTypes},
+		routes:   make(map[*mux.Route]webserver_types.UploadPolicy),
+	}
+}
+
+// SetStorage troca o armazenamento dos arquivos, ex.: por um bucket.
+func (r *Receiver) SetStorage(storage IStorage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.storage = storage
+}
+
+// SetPolicy define os limites de uma rota do mux; os campos vazios herdam dos
+// limites padrão.
+func (r *Receiver) SetPolicy(route *mux.Route, policy webserver_types.UploadPolicy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if policy.MaxSize <= 0 {
+		policy.MaxSize = r.defaults.MaxSize
+	}
+
+	if len(policy.AllowedTypes) == 0 {
+		policy.AllowedTypes = r.defaults.AllowedTypes
+	}
+
+	r.routes[route] = policy
+}
+
+// Policy retorna os limites da rota encontrada para a requisição.
+func (r *Receiver) Policy(req *http.Request) webserver_types.UploadPolicy {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if route := mux.CurrentRoute(req); route != nil {
+		if policy, ok := r.routes[route]; ok {
+			return policy
+		}
+	}
+
+	return r.defaults
+}
+
+// Receive lê o corpo multipart da requisição. Em caso de erro, os arquivos já
+// gravados são liberados. Retorna ErrTooLarge quando o corpo passa do MaxSize
+// e *TypeError quando um arquivo tem tipo não permitido.
+func (r *Receiver) Receive(w http.ResponseWriter, req *http.Request) (*Form, error) {
+	policy := r.Policy(req)
+
+	r.mu.RLock()
+	storage := r.storage
+	r.mu.RUnlock()
+
+	req.Body = http.MaxBytesReader(w, req.Body, policy.MaxSize)
+
+	reader, err := req.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+
+	form := &Form{
+		Values:  make(url.Values),
+		Files:   make(map[string][]*webserver_types.FileUpload),
+		ctx:     req.Context(),
+		storage: storage,
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return form, nil
+		}
+
+		if err == nil {
+			err = r.receivePart(form, part, policy)
+			part.Close()
+		}
+
+		if err != nil {
+			form.Release()
+
+			var maxBytesError *http.MaxBytesError
+			if errors.As(err, &maxBytesError) {
+				return nil, ErrTooLarge
+			}
+
+			return nil, err
+		}
+	}
+}
+
+func (r *Receiver) receivePart(form *Form, part *multipart.Part, policy webserver_types.UploadPolicy) error {
+	if part.FileName() == "" {
+		value, err := io.ReadAll(part)
+		if err != nil {
+			return err
+		}
+
+		form.Values.Add(part.FormName(), string(value))
+		return nil
+	}
+
+	file, err := r.store(form, part, policy)
+	if err != nil {
+		return err
+	}
+
+	form.Files[part.FormName()] = append(form.Files[part.FormName()], file)
+	return nil
+}
+
+func (r *Receiver) store(form *Form, part *multipart.Part, policy webserver_types.UploadPolicy) (*webserver_types.FileUpload, error) {
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(part, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+	if !Allowed(policy.AllowedTypes, contentType) {
+		return nil, &TypeError{Field: part.FormName(), Filename: part.FileName(), ContentType: contentType}
+	}
+
+	var location string
+	file := webserver_types.NewFileUpload(part.FileName(), func() (io.ReadCloser, error) {
+		return form.storage.Open(form.ctx, location)
+	})
+	file.ContentType = contentType
+
+	hash := sha256.New()
+	counter := &countingWriter{}
+	content := io.TeeReader(io.MultiReader(bytes.NewReader(head), part), io.MultiWriter(hash, counter))
+
+	location, err = form.storage.Save(form.ctx, file, content)
+	if err != nil {
+		return nil, err
+	}
+
+	file.Location = location
+	file.Size = counter.n
+	file.Checksum = hex.EncodeToString(hash.Sum(nil))
+
+	return file, nil
+}
+
+// Allowed informa se contentType está em allowedTypes, que aceita curingas como
+// "image/*" e "*/*". Uma lista vazia aceita qualquer tipo.
+func Allowed(allowedTypes []string, contentType string) bool {
+	if len(allowedTypes) == 0 {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, allowed := range allowedTypes {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+
+		if allowed == "*/*" || allowed == mediaType {
+			return true
+		}
+
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,264 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_upload
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	webserver_types "github.com/caiomarcatti12/nanogo/pkg/webserver/types"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeEnv map[string]string
+
+func (e fakeEnv) GetEnv(variable string, defaultValue ...string) string {
+	if value, ok := e[variable]; ok {
+		return value
+	}
+	if len(defaultValue) > 0 {
+		return defaultValue[0]
+	}
+	return ""
+}
+
+func (e fakeEnv) GetEnvBool(variable string, defaultValue ...string) bool {
+	return e.GetEnv(variable, defaultValue...) == "true"
+}
+
+var pngHeader = []byte("\x89PNG\x0D\x0A\x1A\x0A" + "rest of the image")
+
+type part struct {
+	field    string
+	filename string
+	content  []byte
+}
+
+func multipartRequest(t *testing.T, parts ...part) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	for _, p := range parts {
+		if p.filename == "" {
+			require.NoError(t, writer.WriteField(p.field, string(p.content)))
+			continue
+		}
+
+		fileWriter, err := writer.CreateFormFile(p.field, p.filename)
+		require.NoError(t, err)
+		_, err = fileWriter.Write(p.content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	request := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+
+	return request
+}
+
+func newReceiver(t *testing.T, env fakeEnv) *Receiver {
+	env["WEB_SERVER_UPLOAD_DIR"] = t.TempDir()
+	return NewReceiver(env)
+}
+
+func TestReceiveStreamsFilesToStorage(t *testing.T) {
+	receiver := newReceiver(t, fakeEnv{})
+	text := bytes.Repeat([]byte("hello "), 200)
+
+	request := multipartRequest(t,
+		part{field: "name", content: []byte("john")},
+		part{field: "docs", filename: "a.png", content: pngHeader},
+		part{field: "docs", filename: "b.txt", content: text},
+	)
+
+	form, err := receiver.Receive(httptest.NewRecorder(), request)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"john"}, form.Values["name"])
+	require.Len(t, form.Files["docs"], 2)
+
+	png := form.Files["docs"][0]
+	assert.Equal(t, "a.png", png.Filename)
+	assert.Equal(t, "image/png", png.ContentType)
+	assert.Equal(t, int64(len(pngHeader)), png.Size)
+
+	sum := sha256.Sum256(pngHeader)
+	assert.Equal(t, hex.EncodeToString(sum[:]), png.Checksum)
+
+	txt := form.Files["docs"][1]
+	assert.Equal(t, "text/plain; charset=utf-8", txt.ContentType)
+	assert.Equal(t, int64(len(text)), txt.Size)
+
+	content, err := txt.Open()
+	require.NoError(t, err)
+	data, err := io.ReadAll(content)
+	require.NoError(t, err)
+	require.NoError(t, content.Close())
+	assert.Equal(t, text, data)
+
+	require.NoError(t, form.Release())
+	_, err = os.Stat(txt.Location)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestReceiveRejectsTypesNotAllowed(t *testing.T) {
+	dir := t.TempDir()
+	receiver := NewReceiver(fakeEnv{"WEB_SERVER_UPLOAD_DIR": dir, "WEB_SERVER_UPLOAD_ALLOWED_TYPES": "image/*"})
+
+	request := multipartRequest(t,
+		part{field: "image", filename: "a.png", content: pngHeader},
+		part{field: "script", filename: "a.png", content: []byte("#!/bin/sh\nrm -rf /")},
+	)
+
+	_, err := receiver.Receive(httptest.NewRecorder(), request)
+
+	var typeError *TypeError
+	require.ErrorAs(t, err, &typeError)
+	assert.Equal(t, "script", typeError.Field)
+	assert.Equal(t, "text/plain; charset=utf-8", typeError.ContentType)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "files stored before the failure must be released")
+}
+
+func TestReceiveLimitsSize(t *testing.T) {
+	receiver := newReceiver(t, fakeEnv{"WEB_SERVER_MAX_UPLOAD_SIZE": "1"})
+	large := bytes.Repeat([]byte("a"), 2<<20)
+
+	_, err := receiver.Receive(httptest.NewRecorder(), multipartRequest(t, part{field: "file", filename: "a.txt", content: large}))
+	assert.ErrorIs(t, err, ErrTooLarge)
+}
+
+func TestReceiveUsesRoutePolicy(t *testing.T) {
+	receiver := newReceiver(t, fakeEnv{})
+	router := mux.NewRouter()
+
+	var (
+		form *Form
+		err  error
+	)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		form, err = receiver.Receive(w, r)
+	})
+
+	route := router.Handle("/upload", handler)
+	receiver.SetPolicy(route, webserver_types.UploadPolicy{MaxSize: 1024})
+
+	assert.Equal(t, int64(5<<20), receiver.Policy(httptest.NewRequest(http.MethodPost, "/other", nil)).MaxSize)
+
+	router.ServeHTTP(httptest.NewRecorder(), multipartRequest(t, part{field: "file", filename: "a.txt", content: bytes.Repeat([]byte("a"), 2048)}))
+	assert.ErrorIs(t, err, ErrTooLarge)
+	assert.Nil(t, form)
+
+	router.ServeHTTP(httptest.NewRecorder(), multipartRequest(t, part{field: "file", filename: "a.txt", content: []byte("small")}))
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), form.Files["file"][0].Size)
+	require.NoError(t, form.Release())
+}
+
+func TestAllowed(t *testing.T) {
+	tests := []struct {
+		allowed     []string
+		contentType string
+		expected    bool
+	}{
+		{nil, "application/x-msdownload", true},
+		{[]string{"image/png"}, "image/png", true},
+		{[]string{"image/*"}, "image/jpeg", true},
+		{[]string{"image/*"}, "text/plain; charset=utf-8", false},
+		{[]string{"text/plain"}, "text/plain; charset=utf-8", true},
+		{[]string{"*/*"}, "application/pdf", true},
+		{[]string{"application/pdf"}, "application/zip", false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, Allowed(test.allowed, test.contentType), "%v %s", test.allowed, test.contentType)
+	}
+}
+
+type memoryStorage struct {
+	files    map[string][]byte
+	released []string
+}
+
+func (s *memoryStorage) Save(ctx context.Context, file *webserver_types.FileUpload, content io.Reader) (string, error) {
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return "", err
+	}
+
+	location := "bucket/" + file.Filename
+	s.files[location] = data
+	return location, nil
+}
+
+func (s *memoryStorage) Open(ctx context.Context, location string) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(s.files[location])), nil
+}
+
+func (s *memoryStorage) Release(ctx context.Context, location string) error {
+	s.released = append(s.released, location)
+	return nil
+}
+
+func TestReceiveUsesCustomStorage(t *testing.T) {
+	storage := &memoryStorage{files: make(map[string][]byte)}
+	receiver := newReceiver(t, fakeEnv{})
+	receiver.SetStorage(storage)
+
+	form, err := receiver.Receive(httptest.NewRecorder(), multipartRequest(t, part{field: "file", filename: "a.png", content: pngHeader}))
+	require.NoError(t, err)
+
+	file := form.Files["file"][0]
+	assert.Equal(t, "bucket/a.png", file.Location)
+	assert.Equal(t, pngHeader, storage.files["bucket/a.png"])
+
+	require.NoError(t, form.Release())
+	assert.Equal(t, []string{"bucket/a.png"}, storage.released)
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		size     int64
+		expected string
+	}{
+		{size: 0, expected: "0 bytes"},
+		{size: 512, expected: "512 bytes"},
+		{size: 1024, expected: "1 KB"},
+		{size: 1536, expected: "1.5 KB"},
+		{size: 5 << 20, expected: "5 MB"},
+		{size: 5<<20 + 1<<19, expected: "5.5 MB"},
+		{size: 1<<20 + 1, expected: "1 MB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FormatSize(tt.size))
+		})
+	}
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver_upload
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+
+	webserver_types "github.com/caiomarcatti12/nanogo/pkg/webserver/types"
+)
+
+// IStorage grava o conteúdo dos arquivos enviados. Save recebe o arquivo já
+// com Filename e ContentType e retorna a localização usada em Open e Release.
+// Release é chamado ao fim da requisição: armazenamentos temporários removem o
+// arquivo, e os definitivos, como buckets de object storage, podem mantê-lo.
+type IStorage interface {
+	Save(ctx context.Context, file *webserver_types.FileUpload, content io.Reader) (string, error)
+	Open(ctx context.Context, location string) (io.ReadCloser, error)
+	Release(ctx context.Context, location string) error
+}
+
+// TempStorage grava cada arquivo em um arquivo temporário, removido ao fim da
+// requisição.
+type TempStorage struct {
+	dir string
+}
+
+// NewTempStorage cria o armazenamento no diretório dir (os.TempDir quando vazio).
+func NewTempStorage(dir string) *TempStorage {
+	return &TempStorage{dir: dir}
+}
+
+func (s *TempStorage) Save(ctx context.Context, file *webserver_types.FileUpload, content io.Reader) (string, error) {
+	temp, err := os.CreateTemp(s.dir, "upload-*")
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := io.Copy(temp, content); err != nil {
+		temp.Close()
+		os.Remove(temp.Name())
+		return "", err
+	}
+
+	if err := temp.Close(); err != nil {
+		os.Remove(temp.Name())
+		return "", err
+	}
+
+	return temp.Name(), nil
+}
+
+func (s *TempStorage) Open(ctx context.Context, location string) (io.ReadCloser, error) {
+	return os.Open(location)
+}
+
+func (s *TempStorage) Release(ctx context.Context, location string) error {
+	if err := os.Remove(location); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
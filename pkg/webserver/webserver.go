@@ -16,20 +16,33 @@
 package webserver
 
 import (
+	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"reflect"
 	"sync"
+	"time"
 
+	"github.com/caiomarcatti12/nanogo/pkg/authz"
 	"github.com/caiomarcatti12/nanogo/pkg/context_manager"
 	"github.com/caiomarcatti12/nanogo/pkg/di"
 	"github.com/caiomarcatti12/nanogo/pkg/env"
 	"github.com/caiomarcatti12/nanogo/pkg/i18n"
 	"github.com/caiomarcatti12/nanogo/pkg/log"
+	"github.com/caiomarcatti12/nanogo/pkg/metric"
+	"github.com/caiomarcatti12/nanogo/pkg/ratelimit"
+	"github.com/caiomarcatti12/nanogo/pkg/recovery"
 	"github.com/caiomarcatti12/nanogo/pkg/telemetry"
+	webserver_encoding "github.com/caiomarcatti12/nanogo/pkg/webserver/encoding"
 	webserver_middleware "github.com/caiomarcatti12/nanogo/pkg/webserver/middleware"
+	webserver_openapi "github.com/caiomarcatti12/nanogo/pkg/webserver/openapi"
+	webserver_problem "github.com/caiomarcatti12/nanogo/pkg/webserver/problem"
 	webserver_route "github.com/caiomarcatti12/nanogo/pkg/webserver/routes"
 	webserver_types "github.com/caiomarcatti12/nanogo/pkg/webserver/types"
+	webserver_upload "github.com/caiomarcatti12/nanogo/pkg/webserver/upload"
 	"github.com/gorilla/mux"
 )
 
@@ -44,8 +57,35 @@ type WebServer struct {
 	di             di.IContainer
 	telemetry      telemetry.ITelemetry
 	contextManager context_manager.ISafeContextManager
+	authorizer     authz.IAuthorizer
+	recoverer      *recovery.Recoverer
+	limiter        ratelimit.IRateLimiter
+	rateLimit      ratelimit.Policy
 	tLSConfig      *tls.Config
+	timeouts       timeouts
+	openapi        *webserver_openapi.Generator
+	problem        *webserver_problem.Writer
+	codecs         *webserver_encoding.Registry
+	compressors    *webserver_encoding.Compressors
+	uploads        *webserver_upload.Receiver
+	sseHeartbeat   time.Duration
+	streams        context.Context
+	closeStreams   context.CancelFunc
 	router         *mux.Router
+	server         *http.Server
+	metricsServer  *http.Server
+	mu             sync.Mutex
+	// skips guarda, por rota do mux, os middlewares desligados em SkipMiddlewares.
+	skips   map[*mux.Route]map[string]bool
+	skipsMu sync.RWMutex
+}
+
+// timeouts do http.Server; zero desativa o respectivo limite.
+type timeouts struct {
+	readHeader time.Duration
+	read       time.Duration
+	write      time.Duration
+	idle       time.Duration
 }
 
 var (
@@ -60,7 +100,16 @@ func newWebServer(
 	diContainer di.IContainer,
 	telemetry telemetry.ITelemetry,
 	contextManager context_manager.ISafeContextManager,
-) IWebServer {
+	metrics metric.IMetric,
+	authorizer authz.IAuthorizer,
+	limiter ratelimit.IRateLimiter,
+) (IWebServer, error) {
+	// Uma política inválida impede a subida, em vez de deixar o servidor sem limite.
+	rateLimit, err := ratelimit.DefaultPolicy(env)
+	if err != nil {
+		return nil, errors.New(i18n.Get("webserver.invalid_rate_limit", map[string]interface{}{"error": err}))
+	}
+
 	once.Do(func() {
 		instance = &WebServer{
 			host:           env.GetEnv("WEB_SERVER_HOST", ""),
@@ -73,91 +122,349 @@ func newWebServer(
 			di:             diContainer,
 			telemetry:      telemetry,
 			contextManager: contextManager,
+			authorizer:     authorizer,
+			recoverer:      recovery.NewRecoverer("http", logger, i18n, metrics),
+			limiter:        limiter,
 			router:         mux.NewRouter(),
+			skips:          make(map[*mux.Route]map[string]bool),
+			openapi:        webserver_openapi.NewGenerator(env.GetEnv("APP_NAME", "nanogo"), env.GetEnv("VERSION", "1.0.0")),
+			problem:        webserver_problem.NewWriter(env),
+			codecs:         webserver_encoding.NewRegistry(),
+			compressors:    webserver_encoding.NewCompressors(),
+			uploads:        webserver_upload.NewReceiver(env),
 			tLSConfig: &tls.Config{
 				ClientAuth: tls.RequestClientCert,
 			},
 		}
 
+		instance.sseHeartbeat = instance.duration(env, "WEB_SERVER_SSE_HEARTBEAT", "15s")
+		instance.streams, instance.closeStreams = context.WithCancel(context.Background())
+
+		instance.openapi.ProblemDetails(instance.problem.Format() == webserver_problem.FormatProblem)
+
+		instance.timeouts = instance.readTimeouts(env)
+
+		instance.AddMidleware(webserver_middleware.NewMetricsMiddleware(env, logger, i18n, metrics))
+
+		if env.GetEnvBool("WEB_SERVER_COMPRESSION_ENABLED", "true") {
+			instance.AddMidleware(webserver_middleware.NewCompressionMiddleware(env, instance.compressors, logger, i18n))
+		}
+
 		instance.AddMidleware(webserver_middleware.NewCorsMiddleware(env, logger, i18n))
-		instance.AddMidleware(webserver_middleware.NewPayloadExtractorMiddleware(env, logger, i18n))
+		instance.AddMidleware(webserver_middleware.NewPayloadExtractorMiddleware(instance.codecs, instance.uploads, logger, i18n))
 		instance.AddMidleware(webserver_middleware.NewCorrelationIdMiddleware(logger, i18n))
+		instance.AddMidleware(webserver_middleware.NewRecoveryMiddleware(instance.recoverer, logger, i18n))
+
+		instance.rateLimit = rateLimit
+
+		if !rateLimit.IsEmpty() {
+			instance.AddMidleware(webserver_middleware.NewRateLimitMiddleware(limiter, rateLimit, logger, i18n))
+		}
+
 		instance.AddMidleware(webserver_middleware.NewTelemetryMiddleware(env, logger, i18n, telemetry, contextManager))
 
+		// As sondas do orquestrador não passam pelo limite de requisições.
 		instance.AddRoute(webserver_types.Route{
-			Path:        "/healthz/livez",
-			Method:      http.MethodGet,
-			IHandler:    webserver_route.NewHealthCheckController,
-			HandlerFunc: "Handler",
+			Path:            "/healthz/livez",
+			Method:          http.MethodGet,
+			IHandler:        webserver_route.NewLivenessController,
+			HandlerFunc:     "Handler",
+			SkipMiddlewares: []string{"RateLimitMiddleware"},
 		})
+		instance.di.Register(webserver_route.NewReadiness)
+
 		instance.AddRoute(webserver_types.Route{
-			Path:        "/healthz/readyz",
-			Method:      http.MethodGet,
-			IHandler:    webserver_route.NewHealthCheckController,
-			HandlerFunc: "Handler",
+			Path:            "/healthz/readyz",
+			Method:          http.MethodGet,
+			IHandler:        webserver_route.NewReadinessController,
+			HandlerFunc:     "Handler",
+			SkipMiddlewares: []string{"RateLimitMiddleware"},
 		})
 		instance.AddRoute(webserver_types.Route{
-			Path:        "/healthz/startupz",
-			Method:      http.MethodGet,
-			IHandler:    webserver_route.NewHealthCheckController,
-			HandlerFunc: "Handler",
+			Path:            "/healthz/startupz",
+			Method:          http.MethodGet,
+			IHandler:        webserver_route.NewStartupController,
+			HandlerFunc:     "Handler",
+			SkipMiddlewares: []string{"RateLimitMiddleware"},
 		})
+
+		instance.serveOpenAPI(env)
+		instance.serveMetrics(env)
 	})
 
-	return instance
+	return instance, nil
 }
 
 func (ws *WebServer) AddMidleware(middleware webserver_middleware.IMiddleware) {
 	ws.logger.Trace(ws.i18n.Get("webserver.add_middleware", map[string]interface{}{"middleware": middleware.GetName()}))
-	ws.router.Use(func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			middleware.Process(w, r, next)
-		})
-	})
+	ws.router.Use(ws.middlewareFunc(middleware))
+}
+
+// RegisterCodec adiciona um formato à negociação de conteúdo, ou substitui o
+// de mesmo Content-Type.
+func (ws *WebServer) RegisterCodec(codec webserver_encoding.ICodec) {
+	ws.codecs.Register(codec)
+}
+
+// RegisterCompressor adiciona uma Content-Encoding às respostas, ou substitui
+// a de mesmo nome.
+func (ws *WebServer) RegisterCompressor(compressor webserver_encoding.ICompressor) {
+	ws.compressors.Register(compressor)
+}
+
+// SetUploadStorage troca o armazenamento dos arquivos enviados em
+// multipart/form-data, que por padrão são arquivos temporários.
+func (ws *WebServer) SetUploadStorage(storage webserver_upload.IStorage) {
+	ws.uploads.SetStorage(storage)
 }
 
 func (ws *WebServer) AddRoute(route webserver_types.Route) {
-	ws.logger.Trace(ws.i18n.Get("webserver.add_route", map[string]interface{}{"method": route.Method, "path": route.Path}))
+	ws.addRoute(ws.router, "", route)
+}
+
+// Group cria um grupo de rotas com o prefixo informado. Os middlewares do grupo
+// são executados apenas nas rotas do grupo, depois dos middlewares globais.
+func (ws *WebServer) Group(prefix string, middlewares ...webserver_middleware.IMiddleware) IRouteGroup {
+	return newRouteGroup(ws, ws.router, prefix, middlewares)
+}
 
-	ws.di.Register(route.IHandler)
+// URL monta a URL da rota registrada com Name, substituindo as variáveis do
+// caminho pelos pares chave/valor informados: URL("user", "id", "42").
+func (ws *WebServer) URL(name string, pairs ...string) (string, error) {
+	route := ws.router.Get(name)
 
-	ws.router.HandleFunc(route.Path, func(w http.ResponseWriter, r *http.Request) {
+	if route == nil {
+		return "", errors.New(ws.i18n.Get("webserver.route_name_not_found", map[string]interface{}{"name": name}))
+	}
+
+	url, err := route.URL(pairs...)
+
+	if err != nil {
+		return "", err
+	}
+
+	return url.String(), nil
+}
+
+func (ws *WebServer) addRoute(router *mux.Router, prefix string, route webserver_types.Route) {
+	ws.logger.Trace(ws.i18n.Get("webserver.add_route", map[string]interface{}{"method": route.Method, "path": prefix + route.Path}))
+
+	ws.di.Register(route.IHandler, di.WithLifetime(route.Lifetime))
+
+	inputs, output := ws.handlerSignature(route)
+
+	ws.mount(router, prefix, route, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ws.Handler(w, r, route)
-	}).Methods(route.Method)
+	}), inputs, output)
+}
+
+// mount registra o handler no router com os middlewares da rota, a rota
+// OPTIONS e a documentação OpenAPI. inputs e output são os tipos vinculados
+// da requisição e o tipo da resposta.
+func (ws *WebServer) mount(router *mux.Router, prefix string, route webserver_types.Route, handler http.Handler, inputs []reflect.Type, output reflect.Type) {
+	// A autorização roda por último, depois de um JWTMiddleware da própria rota.
+	if policy := (authz.Policy{Roles: route.Roles, Scopes: route.Scopes}); !policy.IsEmpty() {
+		handler = ws.middlewareFunc(webserver_middleware.NewAuthorizationMiddleware(ws.authorizer, policy, ws.logger, ws.i18n))(handler)
+	}
+
+	if route.RateLimit != nil && !route.RateLimit.IsEmpty() {
+		policy := route.RateLimit.WithDefaults(ws.rateLimit)
+		if route.RateLimit.Name == "" {
+			policy.Name = route.Method + " " + prefix + route.Path
+		}
+
+		handler = ws.middlewareFunc(webserver_middleware.NewRateLimitMiddleware(ws.limiter, policy, ws.logger, ws.i18n))(handler)
+	}
+
+	for i := len(route.Middlewares) - 1; i >= 0; i-- {
+		handler = ws.middlewareFunc(route.Middlewares[i])(handler)
+	}
+
+	muxRoute := router.Handle(route.Path, handler).Methods(route.Method)
+
+	if route.Name != "" {
+		muxRoute.Name(route.Name)
+	}
+
+	if route.Upload != nil {
+		ws.uploads.SetPolicy(muxRoute, *route.Upload)
+	}
+
+	ws.documentRoute(prefix+route.Path, route, inputs, output)
 
 	// Adiciona automaticamente suporte para método OPTIONS para cada rota.
-	ws.router.HandleFunc(route.Path, func(w http.ResponseWriter, r *http.Request) {
+	optionsRoute := router.HandleFunc(route.Path, func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}).Methods("OPTIONS")
+
+	if len(route.SkipMiddlewares) > 0 {
+		skip := make(map[string]bool, len(route.SkipMiddlewares))
+		for _, name := range route.SkipMiddlewares {
+			skip[name] = true
+		}
+
+		ws.skipsMu.Lock()
+		ws.skips[muxRoute] = skip
+		ws.skips[optionsRoute] = skip
+		ws.skipsMu.Unlock()
+	}
 }
 
-func (ws *WebServer) Start() {
+// middlewareFunc adapta o IMiddleware para o mux, respeitando SkipMiddlewares
+// da rota encontrada.
+func (ws *WebServer) middlewareFunc(middleware webserver_middleware.IMiddleware) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ws.skipped(r, middleware.GetName()) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			middleware.Process(w, r, next)
+		})
+	}
+}
+
+func (ws *WebServer) skipped(r *http.Request, middleware string) bool {
+	route := mux.CurrentRoute(r)
+
+	if route == nil {
+		return false
+	}
+
+	ws.skipsMu.RLock()
+	defer ws.skipsMu.RUnlock()
+
+	return ws.skips[route][middleware]
+}
+
+// Start abre a porta e atende as requisições até o Shutdown. Retorna erro se a
+// porta não puder ser aberta (ex.: já em uso) ou se o servidor parar por falha;
+// após o Shutdown retorna nil.
+func (ws *WebServer) Start() error {
+	if err := ws.startMetricsServer(); err != nil {
+		return err
+	}
+
 	if ws.crt != "" && ws.key != "" {
-		ws.startWebserverHttps()
-	} else {
-		ws.startWebserverHttp()
+		return ws.startWebserverHttps()
 	}
+
+	return ws.startWebserverHttp()
 }
 
-func (ws *WebServer) startWebserverHttps() {
-	ws.logger.Info(ws.i18n.Get("webserver.server_https_started", map[string]interface{}{"host": ws.host, "port": ws.port}))
+func (ws *WebServer) startWebserverHttps() error {
+	server := ws.newServer(&tls.Config{
+		ClientAuth: tls.RequestClientCert,
+	})
 
-	server := &http.Server{
-		Addr:    fmt.Sprintf("%s:%s", ws.host, ws.port),
-		Handler: ws.router,
-		TLSConfig: &tls.Config{
-			ClientAuth: tls.RequestClientCert,
-		},
+	listener, err := ws.listen(server)
+	if err != nil {
+		return err
 	}
-	server.ListenAndServeTLS(ws.crt, ws.key)
+
+	ws.logger.Info(ws.i18n.Get("webserver.server_https_started", map[string]interface{}{"host": ws.host, "port": ws.port}))
+
+	return ws.served(server.ServeTLS(listener, ws.crt, ws.key))
 }
 
-func (ws *WebServer) startWebserverHttp() {
+func (ws *WebServer) startWebserverHttp() error {
+	server := ws.newServer(nil)
+
+	listener, err := ws.listen(server)
+	if err != nil {
+		return err
+	}
+
 	ws.logger.Info(ws.i18n.Get("webserver.server_http_started", map[string]interface{}{"host": ws.host, "port": ws.port}))
 
-	server := &http.Server{
-		Addr:    fmt.Sprintf("%s:%s", ws.host, ws.port),
-		Handler: ws.router,
+	return ws.served(server.Serve(listener))
+}
+
+func (ws *WebServer) newServer(tlsConfig *tls.Config) *http.Server {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	ws.server = &http.Server{
+		Addr:              fmt.Sprintf("%s:%s", ws.host, ws.port),
+		Handler:           ws.problem.Handler(ws.router),
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: ws.timeouts.readHeader,
+		ReadTimeout:       ws.timeouts.read,
+		WriteTimeout:      ws.timeouts.write,
+		IdleTimeout:       ws.timeouts.idle,
+	}
+
+	if ws.closeStreams != nil {
+		ws.server.RegisterOnShutdown(ws.closeStreams)
 	}
-	server.ListenAndServe()
+
+	return ws.server
+}
+
+func (ws *WebServer) listen(server *http.Server) (net.Listener, error) {
+	listener, err := net.Listen("tcp", server.Addr)
+
+	if err != nil {
+		return nil, errors.New(ws.i18n.Get("webserver.listen_failed", map[string]interface{}{"address": server.Addr, "error": err.Error()}))
+	}
+
+	return listener, nil
+}
+
+// served converte o retorno do Serve: encerrar via Shutdown não é erro.
+func (ws *WebServer) served(err error) error {
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	return err
+}
+
+// readTimeouts lê os WEB_SERVER_*_TIMEOUT; valores inválidos usam o padrão.
+func (ws *WebServer) readTimeouts(env env.IEnv) timeouts {
+	return timeouts{
+		readHeader: ws.duration(env, "WEB_SERVER_READ_HEADER_TIMEOUT", "10s"),
+		read:       ws.duration(env, "WEB_SERVER_READ_TIMEOUT", "30s"),
+		write:      ws.duration(env, "WEB_SERVER_WRITE_TIMEOUT", "30s"),
+		idle:       ws.duration(env, "WEB_SERVER_IDLE_TIMEOUT", "120s"),
+	}
+}
+
+func (ws *WebServer) duration(env env.IEnv, variable string, defaultValue string) time.Duration {
+	value, err := time.ParseDuration(env.GetEnv(variable, defaultValue))
+
+	if err != nil {
+		ws.logger.Warning(ws.i18n.Get("webserver.invalid_timeout", map[string]interface{}{"variable": variable, "default": defaultValue}))
+		value, _ = time.ParseDuration(defaultValue)
+	}
+
+	return value
+}
+
+// Shutdown para de aceitar novas conexões e aguarda as requisições em andamento
+// terminarem ou o ctx expirar.
+func (ws *WebServer) Shutdown(ctx context.Context) error {
+	ws.mu.Lock()
+	server := ws.server
+	ws.mu.Unlock()
+
+	if server == nil {
+		return nil
+	}
+
+	ws.logger.Info(ws.i18n.Get("webserver.server_stopping", map[string]interface{}{"host": ws.host, "port": ws.port}))
+
+	var errs []error
+
+	if err := server.Shutdown(ctx); err != nil {
+		// Prazo esgotado: derruba as conexões que ainda estão abertas.
+		errs = append(errs, err, server.Close())
+	}
+
+	if ws.metricsServer != nil {
+		errs = append(errs, ws.metricsServer.Shutdown(ctx))
+	}
+
+	return errors.Join(errs...)
 }
@@ -16,12 +16,33 @@
 package webserver
 
 import (
+	"context"
+
+	webserver_encoding "github.com/caiomarcatti12/nanogo/pkg/webserver/encoding"
 	webserver_middleware "github.com/caiomarcatti12/nanogo/pkg/webserver/middleware"
+	webserver_openapi "github.com/caiomarcatti12/nanogo/pkg/webserver/openapi"
 	webserver_types "github.com/caiomarcatti12/nanogo/pkg/webserver/types"
+	webserver_upload "github.com/caiomarcatti12/nanogo/pkg/webserver/upload"
 )
 
 type IWebServer interface {
 	AddMidleware(middleware webserver_middleware.IMiddleware)
 	AddRoute(route webserver_types.Route)
-	Start()
+	AddSSERoute(route webserver_types.Route)
+	Group(prefix string, middlewares ...webserver_middleware.IMiddleware) IRouteGroup
+	URL(name string, pairs ...string) (string, error)
+	RegisterCodec(codec webserver_encoding.ICodec)
+	RegisterCompressor(compressor webserver_encoding.ICompressor)
+	SetUploadStorage(storage webserver_upload.IStorage)
+	OpenAPI() *webserver_openapi.Document
+	Start() error
+	Shutdown(ctx context.Context) error
+}
+
+// IRouteGroup agrupa rotas sob um prefixo comum, com middlewares próprios.
+type IRouteGroup interface {
+	AddMidleware(middleware webserver_middleware.IMiddleware)
+	AddRoute(route webserver_types.Route)
+	AddSSERoute(route webserver_types.Route)
+	Group(prefix string, middlewares ...webserver_middleware.IMiddleware) IRouteGroup
 }
@@ -16,21 +16,43 @@
 package webserver
 
 import (
+	"bytes"
 	"encoding/json"
 	"net/http"
 	"reflect"
 
+	"github.com/caiomarcatti12/nanogo/pkg/di"
 	"github.com/caiomarcatti12/nanogo/pkg/errors"
+	"github.com/caiomarcatti12/nanogo/pkg/jwt"
 	"github.com/caiomarcatti12/nanogo/pkg/mapper"
+	"github.com/caiomarcatti12/nanogo/pkg/recovery"
 	"github.com/caiomarcatti12/nanogo/pkg/types"
 	"github.com/caiomarcatti12/nanogo/pkg/validator"
+	webserver_binding "github.com/caiomarcatti12/nanogo/pkg/webserver/binding"
+	webserver_encoding "github.com/caiomarcatti12/nanogo/pkg/webserver/encoding"
+	webserver_sse "github.com/caiomarcatti12/nanogo/pkg/webserver/sse"
 	webserver_types "github.com/caiomarcatti12/nanogo/pkg/webserver/types"
+	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
-	"github.com/mozillazg/go-httpheader"
+	"google.golang.org/protobuf/proto"
 )
 
+var protoMessageType = reflect.TypeOf((*proto.Message)(nil)).Elem()
+
 func (ws *WebServer) Handler(w http.ResponseWriter, r *http.Request, route webserver_types.Route) {
 	ws.logger.Trace(ws.i18n.Get("webserver.execute_handler", map[string]interface{}{"method": r.Method, "path": r.URL.Path}))
+	payload := ws.payload(w, r)
+
+	scope := ws.di.CreateScope()
+	defer scope.Close()
+
+	data, err := ws.callHandler(w, r, scope, route, payload, r.Header)
+
+	ws.respond(w, r, data, err)
+}
+
+// payload retorna o map montado pelo PayloadExtractorMiddleware.
+func (ws *WebServer) payload(w http.ResponseWriter, r *http.Request) map[string]interface{} {
 	payload := make(map[string]interface{})
 
 	if _, ok := r.Context().Value("payload").(map[string]interface{}); ok {
@@ -45,16 +67,14 @@ func (ws *WebServer) Handler(w http.ResponseWriter, r *http.Request, route webse
 		ws.debugInput(w, r, payload)
 	}
 
-	data, err := ws.callHandler(w, r, route, payload, r.Header)
+	return payload
+}
 
+// respond escreve o retorno do handler ou o erro.
+func (ws *WebServer) respond(w http.ResponseWriter, r *http.Request, data interface{}, err error) {
 	if err != nil {
-		if customErr, ok := err.(*errors.CustomError); ok {
-			ws.sendJSONError(w, customErr.Message, customErr.Code)
-			return
-		} else {
-			ws.sendJSONError(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
+		ws.sendJSONError(w, r, err)
+		return
 	}
 
 	if apiResponse, ok := data.(types.Response); ok {
@@ -62,33 +82,77 @@ func (ws *WebServer) Handler(w http.ResponseWriter, r *http.Request, route webse
 			w.Header().Set(key, value)
 		}
 
-		w.WriteHeader(apiResponse.StatusCode)
-
-		contentType := w.Header().Get("Content-Type")
-		if contentType != "" && contentType != "application/json" && apiResponse.Data != nil {
-			switch v := apiResponse.Data.(type) {
-			case []byte:
-				w.Write(v)
-			case string:
-				w.Write([]byte(v))
-			default:
-				ws.sendJSONError(w, "Unsupported data type", http.StatusInternalServerError)
-				return
-			}
-		} else if apiResponse.Data != nil {
-			json.NewEncoder(w).Encode(apiResponse.Data)
-		}
+		ws.writeResponse(w, r, apiResponse.StatusCode, apiResponse.Data)
 	} else if !ws.isWebSocket(r) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
+		ws.writeResponse(w, r, http.StatusOK, data)
+	}
+}
+
+// writeResponse codifica data no formato negociado pelo Accept, ou no
+// Content-Type definido pelo handler. []byte e string com um Content-Type
+// diferente de JSON são escritos como vieram.
+func (ws *WebServer) writeResponse(w http.ResponseWriter, r *http.Request, status int, data interface{}) {
+	contentType := w.Header().Get("Content-Type")
+
+	if data == nil && contentType == "" {
+		w.WriteHeader(status)
+		return
+	}
 
-		if data != nil {
-			json.NewEncoder(w).Encode(data)
+	if contentType != "" && contentType != "application/json" && data != nil {
+		switch v := data.(type) {
+		case []byte:
+			w.WriteHeader(status)
+			w.Write(v)
+			return
+		case string:
+			w.WriteHeader(status)
+			w.Write([]byte(v))
+			return
+		}
+	}
+
+	var (
+		codec webserver_encoding.ICodec
+		ok    bool
+	)
+
+	if contentType != "" {
+		codec, ok = ws.codecs.Lookup(contentType)
+		if !ok {
+			ws.sendJSONError(w, r, errors.InternalServerError("Unsupported data type"))
+			return
+		}
+	} else {
+		w.Header().Add("Vary", "Accept")
+
+		codec, ok = ws.codecs.Negotiate(r.Header.Get("Accept"), data)
+		if !ok {
+			ws.sendJSONError(w, r, errors.NotAcceptable(ws.i18n.Get("webserver.not_acceptable", map[string]interface{}{"accept": r.Header.Get("Accept")})))
+			return
 		}
+
+		w.Header().Set("Content-Type", codec.ContentType())
 	}
+
+	// Codificado antes do status, para que uma falha ainda vire 500.
+	var body bytes.Buffer
+	if data != nil {
+		if err := codec.Encode(&body, data); err != nil {
+			w.Header().Del("Content-Type")
+			ws.sendJSONError(w, r, errors.InternalServerError(ws.i18n.Get("webserver.error_encoding_response", map[string]interface{}{"contentType": codec.ContentType(), "error": err})))
+			return
+		}
+	}
+
+	w.WriteHeader(status)
+	w.Write(body.Bytes())
 }
-func (ws *WebServer) callHandler(w http.ResponseWriter, r *http.Request, route webserver_types.Route, contextPayload map[string]interface{}, contextHeaders http.Header) (response interface{}, err error) {
-	handler, err := ws.di.GetByFactory(route.IHandler)
+
+// callHandler resolve o handler no escopo recebido, que pertence a quem chama e
+// deve continuar aberto enquanto o resultado for usado.
+func (ws *WebServer) callHandler(w http.ResponseWriter, r *http.Request, scope di.IScope, route webserver_types.Route, contextPayload map[string]interface{}, contextHeaders http.Header) (response interface{}, err error) {
+	handler, err := scope.GetByFactory(route.IHandler)
 
 	if err != nil {
 		return nil, err
@@ -107,6 +171,9 @@ func (ws *WebServer) callHandler(w http.ResponseWriter, r *http.Request, route w
 	span := ws.telemetry.StartChildSpan(structName + "::" + route.HandlerFunc)
 	defer (func() { ws.telemetry.EndSpan(span, err) })()
 
+	// Executado antes do EndSpan, para que o span registre o panic como falha.
+	defer ws.recoverPanic(&response, &err)
+
 	method := handlerValue.MethodByName(route.HandlerFunc)
 
 	if !method.IsValid() {
@@ -124,26 +191,18 @@ func (ws *WebServer) callHandler(w http.ResponseWriter, r *http.Request, route w
 			args[i] = reflect.ValueOf(w)
 		} else if paramType == reflect.TypeOf((*http.Request)(nil)) {
 			args[i] = reflect.ValueOf(r)
-		} else {
-			ptrToStruct := reflect.New(paramType)
-			err := mapper.Deserialize(contextPayload, ptrToStruct.Interface())
-
-			if err != nil {
-				return nil, errors.InternalServerError(ws.i18n.Get("webserver.error_injecting_data", map[string]interface{}{"error": err}))
+		} else if paramType == streamType {
+			args[i] = reflect.Zero(streamType)
+			if stream, ok := webserver_sse.FromContext(r.Context()); ok {
+				args[i] = reflect.ValueOf(stream)
 			}
-
-			err = httpheader.Decode(contextHeaders, ptrToStruct.Interface())
+		} else {
+			value, err := ws.bindPayload(r, paramType, contextPayload, contextHeaders)
 			if err != nil {
-				return nil, errors.InternalServerError(ws.i18n.Get("webserver.error_decoding_headers", map[string]interface{}{"error": err}))
-			}
-
-			errorValidateStruct := validator.ValidateStruct(ptrToStruct.Interface())
-
-			if errorValidateStruct != nil {
-				return nil, errorValidateStruct
+				return nil, err
 			}
 
-			args[i] = ptrToStruct.Elem()
+			args[i] = value
 		}
 	}
 
@@ -191,20 +250,80 @@ func (ws *WebServer) callHandler(w http.ResponseWriter, r *http.Request, route w
 	return result, err
 }
 
-func (ws *WebServer) sendJSONError(w http.ResponseWriter, errorMessage string, statusCode int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
+// bindPayload cria o valor de paramType a partir das origens da requisição
+// (webserver_binding.Bind) e das claims do token, e o valida.
+func (ws *WebServer) bindPayload(r *http.Request, paramType reflect.Type, contextPayload map[string]interface{}, contextHeaders http.Header) (reflect.Value, error) {
+	// Mensagens protobuf são lidas do corpo bruto, no formato do Content-Type.
+	if paramType.Implements(protoMessageType) && paramType.Kind() == reflect.Ptr {
+		message := reflect.New(paramType.Elem())
+
+		if body, ok := webserver_encoding.FromContext(r.Context()); ok {
+			if err := body.Decode(message.Interface()); err != nil {
+				return reflect.Value{}, errors.InvalidPayload(ws.i18n.Get("webserver.error_decoding_body", map[string]interface{}{"error": err}))
+			}
+		}
+
+		return message, nil
+	}
+
+	ptrToStruct := reflect.New(paramType)
+
+	if paramType.Kind() == reflect.Struct {
+		request, ok := webserver_binding.FromContext(r.Context())
+		if !ok {
+			request = webserver_binding.Request{Path: mux.Vars(r), Query: r.URL.Query(), Header: contextHeaders, Body: contextPayload}
+		}
+
+		if err := webserver_binding.Bind(request, ptrToStruct.Interface()); err != nil {
+			return reflect.Value{}, err
+		}
+
+		// Executado mesmo sem token, para zerar os campos de claims.
+		claims, _ := jwt.FromContext(r.Context())
+		if err := jwt.Bind(claims, ptrToStruct.Interface()); err != nil {
+			return reflect.Value{}, errors.InternalServerError(ws.i18n.Get("webserver.error_injecting_claims", map[string]interface{}{"error": err}))
+		}
+	} else if err := mapper.Deserialize(contextPayload, ptrToStruct.Interface()); err != nil {
+		return reflect.Value{}, errors.InternalServerError(ws.i18n.Get("webserver.error_injecting_data", map[string]interface{}{"error": err}))
+	}
+
+	errorValidateStruct := validator.ValidateStruct(ptrToStruct.Interface())
+
+	if errorValidateStruct != nil {
+		return reflect.Value{}, errorValidateStruct
+	}
+
+	return ptrToStruct.Elem(), nil
+}
+
+// recoverPanic converte o panic do handler em erro. Deve ser chamado com
+// defer, depois do defer que encerra o span.
+func (ws *WebServer) recoverPanic(response *interface{}, err *error) {
+	if value := recover(); value != nil {
+		if value == http.ErrAbortHandler {
+			panic(value)
+		}
+
+		*response, *err = nil, ws.recoverer.Recover(value)
+	}
+}
+
+// sendJSONError escreve o erro no formato configurado (RFC 7807 por padrão).
+func (ws *WebServer) sendJSONError(w http.ResponseWriter, r *http.Request, err error) {
+	// O panic já foi registrado pelo recoverer; o cliente recebe só um 500.
+	if _, ok := err.(*recovery.PanicError); ok {
+		ws.problem.Write(w, r, errors.InternalServerError(ws.i18n.Get("webserver.panic_recovered")))
+		return
+	}
 
-	if statusCode == 500 {
-		ws.logger.Error(errorMessage)
+	customErr, ok := err.(*errors.CustomError)
+	if !ok || customErr.Code == 0 || customErr.Code >= http.StatusInternalServerError {
+		ws.logger.Error(err.Error())
 	} else {
-		ws.logger.Warning(errorMessage)
+		ws.logger.Warning(err.Error())
 	}
 
-	// Encode and send the error message
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"error": errorMessage,
-	})
+	ws.problem.Write(w, r, err)
 }
 
 func (ws *WebServer) debugInput(w http.ResponseWriter, r *http.Request, payload map[string]interface{}) {
@@ -0,0 +1,229 @@
+/*
+ * Copyright 2023 Caio Matheus Marcatti Calimério
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package webserver
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/caiomarcatti12/nanogo/pkg/di/ditest"
+	"github.com/caiomarcatti12/nanogo/pkg/recovery"
+	"github.com/caiomarcatti12/nanogo/pkg/telemetry"
+	webserver_encoding "github.com/caiomarcatti12/nanogo/pkg/webserver/encoding"
+	webserver_middleware "github.com/caiomarcatti12/nanogo/pkg/webserver/middleware"
+	webserver_openapi "github.com/caiomarcatti12/nanogo/pkg/webserver/openapi"
+	webserver_problem "github.com/caiomarcatti12/nanogo/pkg/webserver/problem"
+	webserver_types "github.com/caiomarcatti12/nanogo/pkg/webserver/types"
+	webserver_upload "github.com/caiomarcatti12/nanogo/pkg/webserver/upload"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEnv map[string]string
+
+func (e testEnv) GetEnv(variable string, defaultValue ...string) string {
+	if value, ok := e[variable]; ok {
+		return value
+	}
+
+	if len(defaultValue) > 0 {
+		return defaultValue[0]
+	}
+
+	return ""
+}
+
+func (e testEnv) GetEnvBool(variable string, defaultValue ...string) bool {
+	value, _ := strconv.ParseBool(e.GetEnv(variable, defaultValue...))
+
+	return value
+}
+
+// newTestWebServer monta o servidor sem o singleton de newWebServer, com o
+// PayloadExtractorMiddleware como único middleware global.
+func newTestWebServer(t *testing.T, env testEnv) *WebServer {
+	ws := &WebServer{
+		port:        env.GetEnv("WEB_SERVER_PORT", "0"),
+		logger:      ditest.Logger{},
+		i18n:        ditest.Translator{},
+		di:          ditest.New(t),
+		telemetry:   telemetry.NewOpenMemory(),
+		recoverer:   recovery.NewRecoverer("http", ditest.Logger{}, ditest.Translator{}, nil),
+		router:      mux.NewRouter(),
+		skips:       make(map[*mux.Route]map[string]bool),
+		openapi:     webserver_openapi.NewGenerator("nanogo", "1.0.0"),
+		problem:     webserver_problem.NewWriter(env),
+		codecs:      webserver_encoding.NewRegistry(),
+		compressors: webserver_encoding.NewCompressors(),
+		uploads:     webserver_upload.NewReceiver(env),
+	}
+
+	ws.AddMidleware(webserver_middleware.NewPayloadExtractorMiddleware(ws.codecs, ws.uploads, ws.logger, ws.i18n))
+
+	return ws
+}
+
+func serve(ws *WebServer, r *http.Request) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	ws.problem.Handler(ws.router).ServeHTTP(recorder, r)
+
+	return recorder
+}
+
+// recordingMiddleware anota em calls o próprio nome a cada requisição.
+type recordingMiddleware struct {
+	name  string
+	calls *[]string
+}
+
+func (m recordingMiddleware) GetName() string {
+	return m.name
+}
+
+func (m recordingMiddleware) Process(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	*m.calls = append(*m.calls, m.name)
+	next.ServeHTTP(w, r)
+}
+
+type empty struct{}
+
+func ok(webserver_types.HandlerContext[empty]) (string, error) {
+	return "ok", nil
+}
+
+type userRequest struct {
+	ID string `path:"id"`
+}
+
+func TestSkipMiddlewares_SkipsGlobalMiddleware(t *testing.T) {
+	ws := newTestWebServer(t, testEnv{})
+
+	var calls []string
+	ws.AddMidleware(recordingMiddleware{name: "Audit", calls: &calls})
+
+	Handle(ws, http.MethodGet, "/audited", ok)
+	Handle(ws, http.MethodGet, "/skipped", ok, WithSkipMiddlewares("Audit"))
+
+	assert.Equal(t, http.StatusOK, serve(ws, httptest.NewRequest(http.MethodGet, "/skipped", nil)).Code)
+	assert.Empty(t, calls)
+
+	assert.Equal(t, http.StatusOK, serve(ws, httptest.NewRequest(http.MethodGet, "/audited", nil)).Code)
+	assert.Equal(t, []string{"Audit"}, calls)
+}
+
+func TestURL_BuildsNamedRoute(t *testing.T) {
+	ws := newTestWebServer(t, testEnv{})
+
+	Handle(ws.Group("/api"), http.MethodGet, "/users/{id}", func(ctx webserver_types.HandlerContext[userRequest]) (string, error) {
+		return ctx.Payload.ID, nil
+	}, WithName("user"))
+
+	url, err := ws.URL("user", "id", "42")
+
+	require.NoError(t, err)
+	assert.Equal(t, "/api/users/42", url)
+}
+
+func TestURL_FailsForUnknownName(t *testing.T) {
+	ws := newTestWebServer(t, testEnv{})
+
+	_, err := ws.URL("missing")
+
+	assert.EqualError(t, err, "webserver.route_name_not_found name=missing")
+}
+
+func TestStart_FailsWhenPortIsInUse(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+
+	ws := newTestWebServer(t, testEnv{"WEB_SERVER_PORT": port})
+	ws.host = "127.0.0.1"
+
+	err = ws.Start()
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "webserver.listen_failed")
+}
+
+func TestReadTimeouts(t *testing.T) {
+	defaults := timeouts{readHeader: 10 * time.Second, read: 30 * time.Second, write: 30 * time.Second, idle: 120 * time.Second}
+
+	tests := []struct {
+		name     string
+		env      testEnv
+		expected timeouts
+	}{
+		{name: "defaults", env: testEnv{}, expected: defaults},
+		{
+			name: "configured",
+			env: testEnv{
+				"WEB_SERVER_READ_HEADER_TIMEOUT": "2s",
+				"WEB_SERVER_READ_TIMEOUT":        "1m",
+				"WEB_SERVER_WRITE_TIMEOUT":       "0s",
+				"WEB_SERVER_IDLE_TIMEOUT":        "500ms",
+			},
+			expected: timeouts{readHeader: 2 * time.Second, read: time.Minute, write: 0, idle: 500 * time.Millisecond},
+		},
+		{
+			name: "invalid values fall back to defaults",
+			env: testEnv{
+				"WEB_SERVER_READ_HEADER_TIMEOUT": "ten",
+				"WEB_SERVER_READ_TIMEOUT":        "30",
+				"WEB_SERVER_WRITE_TIMEOUT":       "",
+				"WEB_SERVER_IDLE_TIMEOUT":        "-",
+			},
+			expected: defaults,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := newTestWebServer(t, tt.env)
+
+			assert.Equal(t, tt.expected, ws.readTimeouts(tt.env))
+		})
+	}
+}
+
+func TestNewWebServer_FailsOnInvalidRateLimit(t *testing.T) {
+	tests := []struct {
+		name string
+		env  testEnv
+	}{
+		{name: "unknown key", env: testEnv{"RATE_LIMIT_REQUESTS": "10", "RATE_LIMIT_KEY": "cookie"}},
+		{name: "subject before authentication", env: testEnv{"RATE_LIMIT_REQUESTS": "10", "RATE_LIMIT_KEY": "subject"}},
+		{name: "invalid period", env: testEnv{"RATE_LIMIT_REQUESTS": "10", "RATE_LIMIT_PERIOD": "soon"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, err := newWebServer(tt.env, ditest.Logger{}, ditest.Translator{}, nil, nil, nil, nil, nil, nil)
+
+			assert.Nil(t, server)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "webserver.invalid_rate_limit")
+		})
+	}
+}
@@ -16,13 +16,28 @@
 package websocketserver
 
 import (
+	"github.com/caiomarcatti12/nanogo/pkg/authz"
 	"github.com/caiomarcatti12/nanogo/pkg/di"
 	"github.com/caiomarcatti12/nanogo/pkg/env"
 	"github.com/caiomarcatti12/nanogo/pkg/i18n"
 	"github.com/caiomarcatti12/nanogo/pkg/log"
+	"github.com/caiomarcatti12/nanogo/pkg/metric"
 	"github.com/caiomarcatti12/nanogo/pkg/webserver"
 )
 
-func Factory(env env.IEnv, logger log.ILog, i18n i18n.I18N, ws webserver.IWebServer, di di.IContainer) IWebSocketServer {
-	return newWebSocketServer(env, logger, i18n, ws, di)
+// FactoryParams são as dependências de Factory. Metrics é opcional: sem o
+// MetricModule os panics recuperados aparecem apenas no log.
+type FactoryParams struct {
+	di.In
+	Env        env.IEnv
+	Log        log.ILog
+	I18N       i18n.I18N
+	WebServer  webserver.IWebServer
+	Container  di.IContainer
+	Authorizer authz.IAuthorizer
+	Metrics    metric.IMetric `optional:"true"`
+}
+
+func Factory(params FactoryParams) IWebSocketServer {
+	return newWebSocketServer(params.Env, params.Log, params.I18N, params.WebServer, params.Container, params.Authorizer, params.Metrics)
 }
@@ -15,8 +15,15 @@
  */
 package websocketserver
 
+import "github.com/caiomarcatti12/nanogo/pkg/di"
+
+// Route define uma rota de mensagens do WebSocket. Roles e Scopes restringem a
+// rota ao principal autenticado na abertura da conexão (veja AddMidleware).
 type Route struct {
 	Path        string
 	IHandler    interface{}
 	HandlerFunc string
+	Lifetime    di.Lifetime
+	Roles       []string
+	Scopes      []string
 }
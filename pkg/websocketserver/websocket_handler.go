@@ -21,7 +21,11 @@ import (
 	"net/http"
 	"reflect"
 
+	"github.com/caiomarcatti12/nanogo/pkg/authz"
+	nanogo_errors "github.com/caiomarcatti12/nanogo/pkg/errors"
+	"github.com/caiomarcatti12/nanogo/pkg/jwt"
 	"github.com/caiomarcatti12/nanogo/pkg/mapper"
+	"github.com/caiomarcatti12/nanogo/pkg/recovery"
 	"github.com/caiomarcatti12/nanogo/pkg/validator"
 	"github.com/gorilla/websocket"
 )
@@ -51,6 +55,14 @@ func (wss *WebSocketServer) HandleConnections(w http.ResponseWriter, r *http.Req
 
 	defer clientConnection.Close()
 
+	wss.trackConnection(clientConnection, r.RemoteAddr)
+	defer wss.untrackConnection(clientConnection)
+
+	// As claims vêm dos middlewares da abertura da conexão e valem para todas
+	// as mensagens dela.
+	claims, _ := jwt.FromContext(r.Context())
+	principal := wss.authorizer.Principal(claims)
+
 	for {
 		_, msg, err := clientConnection.ReadMessage()
 
@@ -76,7 +88,14 @@ func (wss *WebSocketServer) HandleConnections(w http.ResponseWriter, r *http.Req
 			continue
 		}
 
-		response, err := wss.callHandler(clientConnection, route, payload)
+		err = wss.authorizer.Authorize(principal, authz.Policy{Roles: route.Roles, Scopes: route.Scopes})
+
+		if customErr, ok := err.(*nanogo_errors.CustomError); ok {
+			wss.sendJSONError(clientConnection, err, customErr.Code)
+			continue
+		}
+
+		response, err := wss.callHandler(clientConnection, route, payload, claims)
 
 		if err != nil {
 			wss.sendJSONError(clientConnection, err, 500)
@@ -113,8 +132,18 @@ func (wss *WebSocketServer) getRoute(msg Message) (Route, error) {
 	return Route{}, errors.New(wss.i18n.Get("websocketserver.route_not_found", map[string]interface{}{"path": msg.Path}))
 }
 
-func (wss *WebSocketServer) callHandler(clientConnection *websocket.Conn, route Route, msg Message) (interface{}, error) {
-	handler, err := wss.di.GetByFactory(route.IHandler)
+func (wss *WebSocketServer) callHandler(clientConnection *websocket.Conn, route Route, msg Message, claims jwt.Claims) (response interface{}, err error) {
+	scope := wss.di.CreateScope()
+	defer scope.Close()
+
+	// Um panic no handler vira erro da mensagem e a conexão segue aberta.
+	defer func() {
+		if value := recover(); value != nil {
+			response, err = nil, wss.recoverer.Recover(value)
+		}
+	}()
+
+	handler, err := scope.GetByFactory(route.IHandler)
 
 	if err != nil {
 		return nil, err
@@ -144,6 +173,12 @@ func (wss *WebSocketServer) callHandler(clientConnection *websocket.Conn, route
 				return nil, errors.New(wss.i18n.Get("websocketserver.error_injecting_data", map[string]interface{}{"error": err}))
 			}
 
+			if paramType.Kind() == reflect.Struct {
+				if err := jwt.Bind(claims, ptrToStruct.Interface()); err != nil {
+					return nil, errors.New(wss.i18n.Get("websocketserver.error_injecting_data", map[string]interface{}{"error": err}))
+				}
+			}
+
 			errorValidateStruct := validator.ValidateStruct(ptrToStruct.Interface())
 
 			if errorValidateStruct != nil {
@@ -173,13 +208,25 @@ func (wss *WebSocketServer) callHandler(clientConnection *websocket.Conn, route
 }
 
 func (wss *WebSocketServer) sendJSONError(clientConnection *websocket.Conn, err error, statusCode int) {
+	// O panic já foi registrado pelo recoverer; o cliente recebe só um 500.
+	if _, ok := err.(*recovery.PanicError); ok {
+		wss.sendJSONResponse(clientConnection, map[string]interface{}{"error": wss.i18n.Get("websocketserver.panic_recovered"), "status": 500})
+		return
+	}
+
 	if statusCode == 500 {
 		wss.logger.Error(err.Error())
 	} else {
 		wss.logger.Warning(err.Error())
 	}
 
-	wss.sendJSONResponse(clientConnection, map[string]interface{}{"error": err.Error(), "status": statusCode})
+	response := map[string]interface{}{"error": err.Error(), "status": statusCode}
+
+	if customErr, ok := err.(*nanogo_errors.CustomError); ok && len(customErr.Errors) > 0 {
+		response["errors"] = customErr.Errors
+	}
+
+	wss.sendJSONResponse(clientConnection, response)
 }
 
 func (wss *WebSocketServer) sendJSONResponse(clientConnection *websocket.Conn, response interface{}) error {
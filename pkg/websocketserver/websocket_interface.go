@@ -15,7 +15,15 @@
  */
 package websocketserver
 
+import (
+	"context"
+
+	webserver_middleware "github.com/caiomarcatti12/nanogo/pkg/webserver/middleware"
+)
+
 type IWebSocketServer interface {
-	Start()
+	Start() error
 	AddRoute(route Route)
+	AddMidleware(middleware webserver_middleware.IMiddleware)
+	Shutdown(ctx context.Context) error
 }
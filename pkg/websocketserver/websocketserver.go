@@ -16,14 +16,20 @@
 package websocketserver
 
 import (
+	"context"
 	"net/http"
 	"sync"
+	"time"
 
+	"github.com/caiomarcatti12/nanogo/pkg/authz"
 	"github.com/caiomarcatti12/nanogo/pkg/di"
 	"github.com/caiomarcatti12/nanogo/pkg/env"
 	"github.com/caiomarcatti12/nanogo/pkg/i18n"
 	"github.com/caiomarcatti12/nanogo/pkg/log"
+	"github.com/caiomarcatti12/nanogo/pkg/metric"
+	"github.com/caiomarcatti12/nanogo/pkg/recovery"
 	"github.com/caiomarcatti12/nanogo/pkg/webserver"
+	webserver_middleware "github.com/caiomarcatti12/nanogo/pkg/webserver/middleware"
 	webserver_types "github.com/caiomarcatti12/nanogo/pkg/webserver/types"
 	"github.com/gorilla/websocket"
 )
@@ -37,11 +43,15 @@ type WebSocketServer struct {
 	upgrader    *websocket.Upgrader
 	routes      map[string]Route
 	connections map[*websocket.Conn]string
+	connMu      sync.Mutex
 
-	webserver webserver.IWebServer
-	logger    log.ILog
-	i18n      i18n.I18N
-	di        di.IContainer
+	webserver   webserver.IWebServer
+	middlewares []webserver_middleware.IMiddleware
+	logger      log.ILog
+	i18n        i18n.I18N
+	di          di.IContainer
+	authorizer  authz.IAuthorizer
+	recoverer   *recovery.Recoverer
 
 	logInput bool
 }
@@ -52,6 +62,8 @@ func newWebSocketServer(
 	i18n i18n.I18N,
 	ws webserver.IWebServer,
 	di di.IContainer,
+	authorizer authz.IAuthorizer,
+	metrics metric.IMetric,
 ) IWebSocketServer {
 	once.Do(func() {
 		instance = &WebSocketServer{
@@ -66,6 +78,8 @@ func newWebSocketServer(
 			logger:      logger,
 			i18n:        i18n,
 			di:          di,
+			authorizer:  authorizer,
+			recoverer:   recovery.NewRecoverer("websocket", logger, i18n, metrics),
 			logInput:    env.GetEnvBool("WEBSOCKET_SERVER_LOG_INPUT", "false"),
 		}
 
@@ -80,20 +94,63 @@ func newWebSocketServer(
 	return instance
 }
 
-func (ws *WebSocketServer) Start() {
+func (ws *WebSocketServer) Start() error {
 	ws.webserver.AddRoute(webserver_types.Route{
 		Method:      "GET",
 		Path:        "/ws",
 		IHandler:    newWebSocketServer,
 		HandlerFunc: "HandleConnections",
+		Middlewares: ws.middlewares,
 	})
-	ws.webserver.Start()
+	return ws.webserver.Start()
+}
+
+// Shutdown envia uma mensagem de fechamento (going away) para todas as conexões
+// abertas e as encerra. O servidor HTTP é encerrado pelo próprio webserver.
+func (wss *WebSocketServer) Shutdown(ctx context.Context) error {
+	wss.connMu.Lock()
+	defer wss.connMu.Unlock()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(time.Second)
+	}
+
+	message := websocket.FormatCloseMessage(websocket.CloseGoingAway, "")
+
+	for conn := range wss.connections {
+		conn.WriteControl(websocket.CloseMessage, message, deadline)
+		conn.Close()
+		delete(wss.connections, conn)
+	}
+
+	return nil
+}
+
+func (wss *WebSocketServer) trackConnection(conn *websocket.Conn, remoteAddr string) {
+	wss.connMu.Lock()
+	defer wss.connMu.Unlock()
+
+	wss.connections[conn] = remoteAddr
+}
+
+func (wss *WebSocketServer) untrackConnection(conn *websocket.Conn) {
+	wss.connMu.Lock()
+	defer wss.connMu.Unlock()
+
+	delete(wss.connections, conn)
+}
+
+// AddMidleware adiciona um middleware à rota de abertura da conexão (/ws), por
+// exemplo o JWTMiddleware para autenticar o cliente. Deve ser chamado antes do Start.
+func (wss *WebSocketServer) AddMidleware(middleware webserver_middleware.IMiddleware) {
+	wss.middlewares = append(wss.middlewares, middleware)
 }
 
 func (wss *WebSocketServer) AddRoute(route Route) {
 	wss.logger.Trace(wss.i18n.Get("websocketserver.add_route", map[string]interface{}{"path": route.Path}))
 
-	wss.di.Register(route.IHandler)
+	wss.di.Register(route.IHandler, di.WithLifetime(route.Lifetime))
 
 	wss.routes[route.Path] = route
 }